@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/terminal"
+)
+
+var (
+	approvalsAll    bool
+	approvalsReason string
+	approvalsBy     string
+)
+
+// approvals コマンド
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "人間の承認が必要な変更を確認・判断",
+	Long: `agents/config.yaml の intervention.require_approval に一致するタスクとマージを確認し、
+承認・却下を記録します（agents/queue/approvals.yaml）。
+
+一致したタスクは割り当て前に awaiting_approval になり、承認されると割り当て対象に戻り、
+却下されると failed（failure: rejected）になります。
+一致したブランチはマージされず、承認されるとマージを再開し、却下されるとタスクを failed にします。`,
+}
+
+// approvals list コマンド
+var approvalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "判断待ちの承認を表示",
+	Args:  cobra.NoArgs,
+	RunE:  runApprovalsList,
+}
+
+// approvals approve コマンド
+var approvalsApproveCmd = &cobra.Command{
+	Use:   "approve <approval-id>",
+	Short: "承認して作業を再開",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runApprovalsApprove,
+}
+
+// approvals reject コマンド
+var approvalsRejectCmd = &cobra.Command{
+	Use:   "reject <approval-id>",
+	Short: "却下して作業を中止",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runApprovalsReject,
+}
+
+func init() {
+	rootCmd.AddCommand(approvalsCmd)
+	approvalsCmd.AddCommand(approvalsListCmd)
+	approvalsCmd.AddCommand(approvalsApproveCmd)
+	approvalsCmd.AddCommand(approvalsRejectCmd)
+	approvalsListCmd.Flags().BoolVar(&approvalsAll, "all", false, "判断済みの承認も表示")
+	for _, c := range []*cobra.Command{approvalsApproveCmd, approvalsRejectCmd} {
+		c.Flags().StringVar(&approvalsReason, "reason", "", "判断の理由")
+		c.Flags().StringVar(&approvalsBy, "by", "", "判断した人（省略時は $USER）")
+	}
+}
+
+func runApprovalsList(cmd *cobra.Command, args []string) error {
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	approvals, err := orch.Approvals(approvalsAll)
+	if err != nil {
+		terminal.PrintError("承認一覧の取得に失敗しました: %v", err)
+		return err
+	}
+
+	if len(approvals) == 0 {
+		terminal.PrintInfo("判断待ちの承認はありません")
+		return nil
+	}
+
+	terminal.PrintInfo("承認一覧:")
+	for _, req := range approvals {
+		line := fmt.Sprintf("  • %-8s %-6s %-16s %-10s %s", req.ID, req.Kind, req.TaskID, req.Status, strings.Join(req.Policies, ", "))
+		if req.Status == communication.ApprovalStatusPending {
+			terminal.PrintfYellow("%s\n", line)
+		} else {
+			fmt.Println(line)
+		}
+		for _, match := range req.Matches {
+			fmt.Printf("      %s\n", match)
+		}
+		if req.DecidedBy != "" {
+			fmt.Printf("      判断: %s（%s）%s\n", req.DecidedBy, req.DecidedAt.Format("2006-01-02 15:04"), req.DecisionReason)
+		}
+	}
+	return nil
+}
+
+func runApprovalsApprove(cmd *cobra.Command, args []string) error {
+	return decideApproval(args[0], true)
+}
+
+func runApprovalsReject(cmd *cobra.Command, args []string) error {
+	if approvalsReason == "" {
+		return fmt.Errorf("--reason is required to reject")
+	}
+	return decideApproval(args[0], false)
+}
+
+// 承認・却下を記録
+func decideApproval(id string, approve bool) error {
+	by := approvalsBy
+	if by == "" {
+		by = os.Getenv("USER")
+	}
+	if by == "" {
+		return fmt.Errorf("--by is required when $USER is not set")
+	}
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	result, err := orch.DecideApproval(id, approve, by, approvalsReason)
+	if err != nil {
+		terminal.PrintError("承認の記録に失敗しました: %v", err)
+		return err
+	}
+
+	req := result.Request
+	if approve {
+		terminal.PrintSuccess("✓ %s（%s）を承認しました", req.ID, req.TaskID)
+	} else {
+		terminal.PrintSuccess("✓ %s（%s）を却下し、タスクを failed にしました", req.ID, req.TaskID)
+	}
+	if result.Merge != nil {
+		printMergeReport(result.Merge)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApprovalsList_Empty(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runApprovalsList(&cobra.Command{}, nil); err != nil {
+		t.Errorf("approvals list should succeed without approvals: %v", err)
+	}
+}
+
+func TestApprovalsReject_RequiresReason(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runApprovalsReject(&cobra.Command{}, []string{"apr_001"}); err == nil {
+		t.Error("approvals reject should require --reason")
+	}
+}
+
+func TestApprovalsApprove_UnknownApproval(t *testing.T) {
+	chdirTemp(t)
+	approvalsBy = "alice"
+	defer func() { approvalsBy = "" }()
+
+	if err := runApprovalsApprove(&cobra.Command{}, []string{"apr_999"}); err == nil {
+		t.Error("approvals approve should fail for unknown approval")
+	}
+}
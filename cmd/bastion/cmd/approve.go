@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/orchestrator"
+	"github.com/t-ishitsuka/bastion-core/internal/terminal"
+)
+
+var (
+	approveAlways bool
+	approveDeny   bool
+)
+
+// approve コマンド
+var approveCmd = &cobra.Command{
+	Use:   "approve <agent>",
+	Short: "エージェントの権限確認に応答",
+	Long: `権限確認プロンプトで停止しているエージェントに tmux の外から応答します。
+
+例:
+  bastion approve marshall            # 今回のみ許可
+  bastion approve marshall --always   # 許可し、以後同種の確認を省略
+  bastion approve specialist_1 --deny # 拒否`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApprove,
+}
+
+func init() {
+	rootCmd.AddCommand(approveCmd)
+	approveCmd.Flags().BoolVar(&approveAlways, "always", false, "許可し、セッション中は同種の確認を省略")
+	approveCmd.Flags().BoolVar(&approveDeny, "deny", false, "拒否する")
+}
+
+func runApprove(cmd *cobra.Command, args []string) error {
+	agent := args[0]
+
+	if approveAlways && approveDeny {
+		return fmt.Errorf("--always and --deny cannot be used together")
+	}
+
+	answer := orchestrator.PermissionAnswerYes
+	if approveAlways {
+		answer = orchestrator.PermissionAnswerAlways
+	} else if approveDeny {
+		answer = orchestrator.PermissionAnswerDeny
+	}
+
+	// プロジェクトルートを取得
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		terminal.PrintError("プロジェクトルートの取得に失敗: %v", err)
+		return err
+	}
+
+	orch, err := orchestrator.NewOrchestrator(projectRoot, 0)
+	if err != nil {
+		terminal.PrintError("設定の読み込みに失敗しました: %v", err)
+		return err
+	}
+	if err := orch.AnswerPermission(agent, answer); err != nil {
+		terminal.PrintError("権限確認への応答に失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintSuccess("✓ %s の権限確認に応答しました (%s)", agent, answer)
+	return nil
+}
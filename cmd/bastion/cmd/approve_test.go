@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApproveCommand_ConflictingFlags(t *testing.T) {
+	approveAlways, approveDeny = true, true
+	defer func() { approveAlways, approveDeny = false, false }()
+
+	if err := runApprove(&cobra.Command{}, []string{"marshall"}); err == nil {
+		t.Error("expected error when --always and --deny are both set")
+	}
+}
+
+func TestApproveCommand_UnknownAgent(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runApprove(&cobra.Command{}, []string{"unknown"}); err == nil {
+		t.Error("expected error for unknown agent")
+	}
+}
+
+// 一時ディレクトリに移動し、テスト終了時に元のディレクトリへ戻す
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("現在のディレクトリの取得に失敗: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("一時ディレクトリへの移動に失敗: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(originalDir)
+	})
+	return tmpDir
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/terminal"
+)
+
+var (
+	commandWaiveReason string
+	commandWaiveBy     string
+)
+
+// command コマンド
+var commandCmd = &cobra.Command{
+	Use:   "command",
+	Short: "Envoy が発行した指令を操作",
+}
+
+// command verify コマンド
+var commandVerifyCmd = &cobra.Command{
+	Use:   "verify <command-id>",
+	Short: "指令の完了条件を検証",
+	Long: `指令の acceptance_criteria のうち verify（シェルコマンド）または test（テスト名）を持つものを
+統合用 worktree（なければプロジェクトルート）で実行し、結果を完了条件ごとに記録します。
+test は agents/config.yaml の acceptance.test_command で実行します（テスト名は $BASTION_TEST）。
+
+すべての完了条件が passed または waived になると指令を completed にします。
+実行できない完了条件は bastion command waive で免除するまで unverified のままです。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCommandVerify,
+}
+
+// command waive コマンド
+var commandWaiveCmd = &cobra.Command{
+	Use:   "waive <command-id> <criterion>",
+	Short: "完了条件の検証を免除",
+	Long: `指令の完了条件（1 始まりの番号）を waived にし、免除した人と理由を記録します。
+免除した完了条件は bastion command verify で実行しません。`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCommandWaive,
+}
+
+// command rollback コマンド
+var commandRollbackCmd = &cobra.Command{
+	Use:   "rollback <command-id>",
+	Short: "指令のマージを打ち消すブランチを作成",
+	Long: `bastion merge が記録した指令のマージコミットを新しい順に revert し、
+ロールバック用ブランチ（bastion/rollback/<command-id>）にコミットします。
+ブランチはマージを取り込んだブランチ（プロジェクトルートの HEAD、なければ統合ブランチ）から作成します。
+
+履歴は書き換えません（reset や push --force は使いません）。ブランチをレビューしてからマージしてください。
+revert がコンフリクトした場合は中止します。ロールバック用 worktree で解消してコミットし、再実行すると続きから打ち消します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCommandRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(commandCmd)
+	commandCmd.AddCommand(commandVerifyCmd)
+	commandCmd.AddCommand(commandWaiveCmd)
+	commandCmd.AddCommand(commandRollbackCmd)
+	commandWaiveCmd.Flags().StringVar(&commandWaiveReason, "reason", "", "免除の理由")
+	commandWaiveCmd.Flags().StringVar(&commandWaiveBy, "by", "", "免除した人（省略時は $USER）")
+}
+
+// 完了条件の状態を表示
+func printCriteria(cmd *communication.Command) {
+	for i, c := range cmd.AcceptanceCriteria {
+		line := fmt.Sprintf("  %d. [%s] %s", i+1, c.CurrentStatus(), c.Description)
+		switch c.CurrentStatus() {
+		case communication.CriterionStatusPassed, communication.CriterionStatusWaived:
+			terminal.PrintfGreen("%s\n", line)
+		case communication.CriterionStatusFailed:
+			terminal.PrintfRed("%s\n", line)
+		default:
+			terminal.PrintfYellow("%s\n", line)
+		}
+		if c.WaivedBy != "" {
+			fmt.Printf("      免除: %s\n", c.WaivedBy)
+		}
+		if c.Evidence != "" {
+			fmt.Printf("      %s\n", strings.ReplaceAll(c.Evidence, "\n", "\n      "))
+		}
+	}
+}
+
+func runCommandVerify(cmd *cobra.Command, args []string) error {
+	commandID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	report, err := orch.VerifyCommand(commandID)
+	if err != nil {
+		terminal.PrintError("完了条件の検証に失敗しました: %v", err)
+		return err
+	}
+
+	if len(report.Command.AcceptanceCriteria) == 0 {
+		terminal.PrintInfo("%s には完了条件がありません", commandID)
+	} else {
+		terminal.PrintInfo("%s の完了条件（%s）:", commandID, report.Dir)
+		printCriteria(report.Command)
+	}
+
+	if len(report.Unresolved) > 0 {
+		return fmt.Errorf("%s has unresolved criteria %v", commandID, report.Unresolved)
+	}
+	if report.Completed {
+		terminal.PrintSuccess("✓ %s の完了条件をすべて満たしたため completed にしました", commandID)
+	}
+	return nil
+}
+
+func runCommandWaive(cmd *cobra.Command, args []string) error {
+	commandID := args[0]
+	index, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid criterion number: %s", args[1])
+	}
+	if commandWaiveReason == "" {
+		return fmt.Errorf("--reason is required to waive a criterion")
+	}
+	by := commandWaiveBy
+	if by == "" {
+		by = os.Getenv("USER")
+	}
+	if by == "" {
+		return fmt.Errorf("--by is required when $USER is not set")
+	}
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	if err := orch.WaiveCriterion(commandID, index, by, commandWaiveReason); err != nil {
+		terminal.PrintError("完了条件の免除に失敗しました: %v", err)
+		return err
+	}
+	terminal.PrintSuccess("✓ %s の完了条件 %d を免除しました", commandID, index)
+	return nil
+}
+
+func runCommandRollback(cmd *cobra.Command, args []string) error {
+	commandID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	report, err := orch.RollbackCommand(commandID)
+	if err != nil {
+		terminal.PrintError("ロールバックに失敗しました: %v", err)
+		return err
+	}
+
+	for _, r := range report.Reverted {
+		terminal.PrintSuccess("✓ %s のマージ %s を打ち消しました: %s", r.Task, shortCommit(r.Commit), shortCommit(r.Revert))
+	}
+	for _, c := range report.AlreadyReverted {
+		terminal.PrintInfo("%s は打ち消し済みです", shortCommit(c))
+	}
+
+	if c := report.Conflict; c != nil {
+		terminal.PrintError("%s のマージ %s の打ち消しでコンフリクトが発生しました（revert は進行中のままです）", c.TaskID, shortCommit(c.Commit))
+		terminal.PrintInfo("コンフリクトしたファイル: %s", strings.Join(c.Files, ", "))
+		terminal.PrintInfo("%s で解消して git add し、git revert --continue でコミットしてから再実行してください", report.Worktree)
+		terminal.PrintInfo("やり直す場合は git revert --abort で revert 前に戻せます")
+		return fmt.Errorf("revert conflict in %s", c.TaskID)
+	}
+
+	terminal.PrintSuccess("ロールバック用ブランチ: %s (%s、%s から作成)", report.Branch, report.Worktree, report.Base)
+	terminal.PrintInfo("レビューしてからマージしてください")
+	return nil
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCommandVerify_UnknownCommand(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runCommandVerify(&cobra.Command{}, []string{"cmd_999"}); err == nil {
+		t.Error("command verify should fail for unknown command")
+	}
+}
+
+func TestCommandWaive_RequiresReason(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runCommandWaive(&cobra.Command{}, []string{"cmd_001", "1"}); err == nil {
+		t.Error("command waive should require --reason")
+	}
+}
+
+func TestCommandRollback_UnknownCommand(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runCommandRollback(&cobra.Command{}, []string{"cmd_999"}); err == nil {
+		t.Error("command rollback should fail for unknown command")
+	}
+}
@@ -18,7 +18,7 @@ var initCmd = &cobra.Command{
 
 このコマンドは以下を実行します:
   - テンプレートから agents/ ディレクトリを作成（queue/ も含む）
-  - .gitignore に agents/queue/ を追加
+  - .gitignore に agents/queue/ と .worktrees/ を追加
 
 既存のファイルは上書きせず、新しいファイルのみを追加します（--no-clobber モード）。`,
 	RunE: runInit,
@@ -52,11 +52,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  (%d 個の既存ファイルはスキップしました)\n", skipped)
 	}
 
-	// .gitignore に agents/queue/ を追加
+	// .gitignore に実行時ディレクトリを追加
 	if err := updateGitignore(currentDir); err != nil {
 		fmt.Printf("警告: .gitignore の更新に失敗しました: %v\n", err)
 	} else {
-		fmt.Printf("✓ .gitignore に agents/queue/ を追加しました\n")
+		fmt.Printf("✓ .gitignore に agents/queue/ と .worktrees/ を追加しました\n")
 	}
 
 	fmt.Println("\n初期化が完了しました！")
@@ -140,7 +140,7 @@ func copyEmbeddedDirNoClobber(fsys fs.FS, srcPath, dstPath string) (int, int, er
 	return copied, skipped, err
 }
 
-// .gitignore に queue/ を追加
+// .gitignore に実行時ディレクトリ（agents/queue/, .worktrees/）を追加
 func updateGitignore(projectDir string) error {
 	gitignorePath := filepath.Join(projectDir, ".gitignore")
 
@@ -154,20 +154,27 @@ func updateGitignore(projectDir string) error {
 		}
 	}
 
-	// agents/queue/ が既に記載されているかチェック
+	// 未記載のエントリのみ追加
 	contentStr := string(content)
-	if strings.Contains(contentStr, "agents/queue/") {
+	var missing []string
+	for _, entry := range []string{"agents/queue/", ".worktrees/"} {
+		if !strings.Contains(contentStr, entry) {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
 		// 既に記載されている
 		return nil
 	}
 
-	// agents/queue/ を追加
 	newContent := contentStr
 	if len(newContent) > 0 && newContent[len(newContent)-1] != '\n' {
 		newContent += "\n"
 	}
-	newContent += "# Bastion runtime directories\n"
-	newContent += "agents/queue/\n"
+	if !strings.Contains(contentStr, "# Bastion runtime directories") {
+		newContent += "# Bastion runtime directories\n"
+	}
+	newContent += strings.Join(missing, "\n") + "\n"
 
 	return os.WriteFile(gitignorePath, []byte(newContent), 0644)
 }
@@ -130,6 +130,11 @@ func TestUpdateGitignore(t *testing.T) {
 			existingContent: "agents/queue/\nnode_modules/\n",
 			wantContains:    "agents/queue/",
 		},
+		{
+			name:            "worktree ディレクトリも追加される",
+			existingContent: "agents/queue/\n",
+			wantContains:    ".worktrees/",
+		},
 	}
 
 	for _, tt := range tests {
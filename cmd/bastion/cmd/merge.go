@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/orchestrator"
+	"github.com/t-ishitsuka/bastion-core/internal/terminal"
+)
+
+// merge コマンド
+var mergeCmd = &cobra.Command{
+	Use:   "merge <command-id>",
+	Short: "指令の完了タスクのブランチを統合ブランチにマージ",
+	Long: `指令に属する completed タスクのブランチ（bastion/task/<task_id>）を、
+依存関係の順に統合ブランチ bastion/integration/<command-id> へマージします。
+
+コンフリクトが発生した場合はそのマージを中止して統合ブランチをマージ前の状態に戻し、
+コンフリクト解消タスクを作成して元のタスクの担当 Specialist に割り当てます。
+解消後に再度実行すると、残りのタスクをマージします。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMerge,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	commandID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	terminal.PrintInfo("%s のタスクをマージしています...", commandID)
+
+	report, err := orch.MergeCommand(commandID)
+	if report != nil {
+		printMergeReport(report)
+	}
+	if err != nil {
+		terminal.PrintError("マージに失敗しました: %v", err)
+		return err
+	}
+
+	if report.Conflict != nil {
+		return fmt.Errorf("merge conflict in %s", report.Conflict.TaskID)
+	}
+	return nil
+}
+
+// マージ結果を表示
+func printMergeReport(report *orchestrator.MergeReport) {
+	for _, m := range report.Merged {
+		terminal.PrintSuccess("✓ %s (%s) をマージしました: %s", m.TaskID, m.Branch, shortCommit(m.Commit))
+	}
+	for _, id := range report.AlreadyMerged {
+		terminal.PrintInfo("%s はマージ済みです", id)
+	}
+	for _, s := range report.Skipped {
+		terminal.PrintWarning("%s をスキップしました: %s", s.TaskID, s.Reason)
+	}
+
+	if c := report.Conflict; c != nil {
+		terminal.PrintError("%s (%s) でコンフリクトが発生したためマージを中止しました", c.TaskID, c.Branch)
+		terminal.PrintInfo("コンフリクトしたファイル: %s", strings.Join(c.Files, ", "))
+		if c.AssignedTo != "" {
+			terminal.PrintInfo("コンフリクト解消タスク %s を %s に割り当てました", c.ResolutionTask, c.AssignedTo)
+		} else {
+			terminal.PrintInfo("コンフリクト解消タスク %s を作成しました（未割当）", c.ResolutionTask)
+		}
+		return
+	}
+
+	terminal.PrintSuccess("統合ブランチ: %s (%s)", report.Branch, report.Worktree)
+}
+
+// コミットハッシュの短縮形
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
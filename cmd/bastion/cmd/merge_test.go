@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestMerge_OutsideRepository(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runMerge(&cobra.Command{}, []string{"cmd_001"}); err == nil {
+		t.Error("merge should fail outside a git repository")
+	}
+}
+
+func TestMerge_InvalidConfig(t *testing.T) {
+	dir := chdirTemp(t)
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "agents"), 0755); err != nil {
+		t.Fatalf("failed to create agents dir: %v", err)
+	}
+	// スケジューラなしの承認ポリシーは不正（承認なしでマージしない）
+	content := "intervention:\n  require_approval: [new_dependencies]\n"
+	if err := os.WriteFile(filepath.Join(dir, "agents", "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	err := runMerge(&cobra.Command{}, []string{"cmd_001"})
+	if err == nil || !strings.Contains(err.Error(), "require_approval") {
+		t.Errorf("merge should refuse to run on an invalid config, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/orchestrator"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+	"github.com/t-ishitsuka/bastion-core/internal/terminal"
+)
+
+// specialist コマンド
+var specialistCmd = &cobra.Command{
+	Use:   "specialist",
+	Short: "実行中のセッションの Specialist を管理",
+	Long: `実行中の Bastion セッションに Specialist を追加・削除・一覧表示します。
+
+bastion start --specialists で指定した数に関わらず、実行時に増減できます。`,
+}
+
+// specialist add コマンド
+var specialistAddCmd = &cobra.Command{
+	Use:   "add [path]",
+	Short: "Specialist を追加",
+	Long: `specialists ウィンドウに新しいペインを作成して Specialist を起動します。
+
+ウィンドウに空きがない場合は新しいウィンドウを作成します。
+外部 Specialist の定義ファイル（.claude/agents/*.yaml と同じ形式）を指定すると、
+そのペルソナで Specialist を起動します。
+
+例:
+  bastion specialist add
+  bastion specialist add ./my-specialist.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSpecialistAdd,
+}
+
+// specialist remove コマンド
+var specialistRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Specialist を削除",
+	Long: `Specialist のペインを閉じて登録を解除します。
+
+担当中のタスクは未割当に戻し、Marshall の inbox に再割当を依頼します。
+worktree に未コミットの変更が残っていてタスクのブランチを外せない場合は、削除せずに終了します
+（変更をコミットするか破棄してから再度実行してください）。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSpecialistRemove,
+}
+
+// specialist list コマンド
+var specialistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Specialist 一覧を表示",
+	Args:  cobra.NoArgs,
+	RunE:  runSpecialistList,
+}
+
+func init() {
+	rootCmd.AddCommand(specialistCmd)
+	specialistCmd.AddCommand(specialistAddCmd)
+	specialistCmd.AddCommand(specialistRemoveCmd)
+	specialistCmd.AddCommand(specialistListCmd)
+}
+
+// セッションが起動していることを確認して Orchestrator を作成
+func newSessionOrchestrator() (*orchestrator.Orchestrator, error) {
+	sm := parallel.NewSessionManager()
+
+	exists, err := sm.SessionExists()
+	if err != nil {
+		terminal.PrintError("セッションの確認に失敗しました: %v", err)
+		return nil, err
+	}
+	if !exists {
+		terminal.PrintError("Bastion セッションは起動していません")
+		terminal.PrintInfo("起動するには: bastion start")
+		return nil, fmt.Errorf("session not found: %s", parallel.SessionName)
+	}
+
+	// プロジェクトルートを取得
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		terminal.PrintError("プロジェクトルートの取得に失敗: %v", err)
+		return nil, err
+	}
+
+	orch, err := orchestrator.NewOrchestrator(projectRoot, 0)
+	if err != nil {
+		terminal.PrintError("設定の読み込みに失敗しました: %v", err)
+		return nil, err
+	}
+	return orch, nil
+}
+
+func runSpecialistAdd(cmd *cobra.Command, args []string) error {
+	// 定義ファイルはセッション確認より先に検証する
+	var spec *orchestrator.SpecialistConfig
+	if len(args) == 1 {
+		loaded, err := orchestrator.LoadSpecialistFile(args[0])
+		if err != nil {
+			terminal.PrintError("Specialist 定義の読み込みに失敗しました: %v", err)
+			return err
+		}
+		spec = loaded
+	}
+
+	orch, err := newSessionOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	terminal.PrintInfo("Specialist を追加しています...")
+
+	info, err := orch.AddSpecialist(spec)
+	if err != nil {
+		terminal.PrintError("Specialist の追加に失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintSuccess("✓ %s を起動しました (ペイン: %s)", info.Name, info.Target)
+	return nil
+}
+
+func runSpecialistRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	orch, err := newSessionOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	terminal.PrintInfo("%s を削除しています...", name)
+
+	drained, err := orch.RemoveSpecialist(name)
+	if len(drained) > 0 {
+		terminal.PrintWarning("担当中のタスクを Marshall に戻しました: %s", strings.Join(drained, ", "))
+	}
+	if err != nil {
+		terminal.PrintError("Specialist の削除に失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintSuccess("✓ %s を削除しました", name)
+	return nil
+}
+
+func runSpecialistList(cmd *cobra.Command, args []string) error {
+	orch, err := newSessionOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	specialists, err := orch.Specialists()
+	if err != nil {
+		terminal.PrintError("Specialist 一覧の取得に失敗しました: %v", err)
+		return err
+	}
+
+	if len(specialists) == 0 {
+		terminal.PrintWarning("登録済みの Specialist はありません")
+		return nil
+	}
+
+	terminal.PrintInfo("Specialist 一覧:")
+	for _, info := range specialists {
+		state, err := orch.DetectState(info.Name)
+		if err != nil {
+			state = orchestrator.AgentStateUnknown
+		}
+
+		tasks, err := orch.AssignedTasks(info.Name)
+		taskLabel := "-"
+		if err == nil && len(tasks) > 0 {
+			taskLabel = strings.Join(tasks, ", ")
+		}
+
+		status := orchestrator.AgentStatus{Name: info.Name, Target: info.Target, State: state}
+		if info.Spec != nil {
+			status.Persona = info.Spec.Persona
+		}
+		printAgentState(status)
+		fmt.Printf("      ペイン: %s  タスク: %s\n", info.Target, taskLabel)
+		if r := info.Resources; r != nil {
+			fmt.Printf("      ポート: %d-%d  作業用ディレクトリ: %s\n", r.PortBase, r.PortBase+r.PortCount-1, r.ScratchDir)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestSpecialistCommands_SessionNotRunning(t *testing.T) {
+	if !isTmuxAvailableForCmd() {
+		t.Skip("tmux is not available")
+	}
+
+	sm := parallel.NewSessionManager()
+
+	// セッションが存在しない状態にする
+	if exists, _ := sm.SessionExists(); exists {
+		_ = sm.KillSession()
+	}
+
+	if err := runSpecialistAdd(&cobra.Command{}, []string{}); err == nil {
+		t.Error("specialist add should fail when session doesn't exist")
+	}
+	if err := runSpecialistRemove(&cobra.Command{}, []string{"specialist_1"}); err == nil {
+		t.Error("specialist remove should fail when session doesn't exist")
+	}
+	if err := runSpecialistList(&cobra.Command{}, []string{}); err == nil {
+		t.Error("specialist list should fail when session doesn't exist")
+	}
+}
+
+func TestSpecialistCommand_Registered(t *testing.T) {
+	names := map[string]bool{}
+	for _, c := range specialistCmd.Commands() {
+		names[c.Name()] = true
+	}
+
+	for _, name := range []string{"add", "remove", "list"} {
+		if !names[name] {
+			t.Errorf("expected subcommand %q to be registered", name)
+		}
+	}
+}
+
+func TestSpecialistAdd_InvalidDefinition(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.yaml")
+	if err := os.WriteFile(path, []byte("name: Not Kebab\n"), 0644); err != nil {
+		t.Fatalf("failed to write definition: %v", err)
+	}
+
+	// セッションの有無に関わらず定義の検証で失敗する
+	if err := runSpecialistAdd(&cobra.Command{}, []string{path}); err == nil {
+		t.Error("specialist add should fail for invalid definition")
+	}
+}
@@ -35,6 +35,20 @@ func init() {
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
+	// プロジェクトルートを取得
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		terminal.PrintError("プロジェクトルートの取得に失敗: %v", err)
+		return err
+	}
+
+	// 設定が不正ならセッションを作らない
+	orch, err := orchestrator.NewOrchestrator(projectRoot, specialists)
+	if err != nil {
+		terminal.PrintError("設定の読み込みに失敗しました: %v", err)
+		return err
+	}
+
 	terminal.PrintInfo("Bastion セッションを起動しています...")
 
 	// tmux セッションをセットアップ
@@ -45,16 +59,6 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	terminal.PrintSuccess("✓ tmux セッションを作成しました")
 
-	// プロジェクトルートを取得
-	projectRoot, err := os.Getwd()
-	if err != nil {
-		terminal.PrintError("プロジェクトルートの取得に失敗: %v", err)
-		return err
-	}
-
-	// Orchestrator を作成
-	orch := orchestrator.NewOrchestrator(projectRoot, specialists)
-
 	terminal.PrintInfo("エージェントを起動しています...")
 
 	// すべてのエージェントを起動
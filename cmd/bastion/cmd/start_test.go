@@ -49,6 +49,8 @@ func TestStartCommand(t *testing.T) {
 
 	// テストモードを有効化
 	setTestEnv(t, "BASTION_TEST_MODE", "1")
+	// エージェント登録ファイルをリポジトリ内に作らない
+	chdirTemp(t)
 
 	sm := parallel.NewSessionManager()
 	defer cleanupSession(t, sm)
@@ -81,6 +83,8 @@ func TestStartCommand_AlreadyRunning(t *testing.T) {
 
 	// テストモードを有効化
 	setTestEnv(t, "BASTION_TEST_MODE", "1")
+	// エージェント登録ファイルをリポジトリ内に作らない
+	chdirTemp(t)
 
 	sm := parallel.NewSessionManager()
 	defer cleanupSession(t, sm)
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/orchestrator"
 	"github.com/t-ishitsuka/bastion-core/internal/parallel"
 	"github.com/t-ishitsuka/bastion-core/internal/terminal"
 )
@@ -14,7 +16,8 @@ var statusCmd = &cobra.Command{
 	Short: "Bastion セッションの状態を確認",
 	Long: `Bastion セッションの実行状態を表示します。
 
-tmux セッション、ウィンドウ、ペインの状態を確認できます。`,
+tmux セッション、ウィンドウ、ペインの状態と、各エージェントの活動状態
+（idle / busy / awaiting_permission / crashed）を確認できます。`,
 	RunE: runStatus,
 }
 
@@ -59,9 +62,49 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		terminal.PrintfGreen("  • %s (%d ペイン)\n", w, len(panes))
 	}
 
+	// エージェントの活動状態を表示
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		terminal.PrintError("プロジェクトルートの取得に失敗: %v", err)
+		return err
+	}
+
+	fmt.Println()
+	terminal.PrintInfo("エージェント状態:")
+	orch, err := orchestrator.NewOrchestrator(projectRoot, 0)
+	if err != nil {
+		terminal.PrintError("設定の読み込みに失敗しました: %v", err)
+		return err
+	}
+	for _, st := range orch.AgentStates() {
+		printAgentState(st)
+	}
+
 	fmt.Println()
 	terminal.PrintInfo("セッションにアタッチ: tmux attach -t %s", parallel.SessionName)
 	terminal.PrintInfo("セッションを停止: bastion stop")
 
 	return nil
 }
+
+// エージェントの状態を色分けして表示
+func printAgentState(st orchestrator.AgentStatus) {
+	// 外部 Specialist はペルソナを併記
+	label := string(st.State)
+	if st.Persona != "" {
+		label = fmt.Sprintf("%-20s %s", st.State, st.Persona)
+	}
+
+	switch st.State {
+	case orchestrator.AgentStateIdle:
+		terminal.PrintfGreen("  • %-18s %s\n", st.Name, label)
+	case orchestrator.AgentStateBusy:
+		terminal.PrintfCyan("  • %-18s %s\n", st.Name, label)
+	case orchestrator.AgentStateAwaitingPermission:
+		terminal.PrintfYellow("  • %-18s %s\n", st.Name, label)
+	case orchestrator.AgentStateCrashed:
+		terminal.PrintfRed("  • %-18s %s\n", st.Name, label)
+	default:
+		fmt.Printf("  • %-18s %s\n", st.Name, label)
+	}
+}
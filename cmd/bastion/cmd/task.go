@@ -0,0 +1,555 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/orchestrator"
+	"github.com/t-ishitsuka/bastion-core/internal/terminal"
+)
+
+var (
+	taskReviewApprove bool
+	taskReviewBy      string
+	taskAttemptsPick  bool
+)
+
+// task コマンド
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Specialist 向けタスクを操作",
+}
+
+// task route コマンド
+var taskRouteCmd = &cobra.Command{
+	Use:   "route <task-id>",
+	Short: "タスクの担当 Specialist を提案",
+	Long: `タスクの objective / deliverables を各 Specialist の trigger_patterns と
+capabilities に照合し、空いている Specialist から担当候補を提案します。
+
+判定結果（候補ごとのスコアと一致した語）はタスクファイルの routing に記録されます。
+割り当ては行いません。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskRoute,
+}
+
+// task dispatch コマンド
+var taskDispatchCmd = &cobra.Command{
+	Use:   "dispatch",
+	Short: "着手できるタスクを空いている Specialist に割り当て",
+	Long: `依存タスクがすべて completed になった未割当の pending タスクを、
+空いている Specialist に割り当ててタスクファイルと inbox に書き込みます。
+割り当て後に着手されず放置されたタスクは、空いている Specialist に付け替えます。
+
+agents/config.yaml の scheduler.enabled を true にすると、bastion watch が定期的に同じ処理を行います。`,
+	Args: cobra.NoArgs,
+	RunE: runTaskDispatch,
+}
+
+// task leases コマンド
+var taskLeasesCmd = &cobra.Command{
+	Use:   "leases",
+	Short: "実行中のタスクの変更予定パス（リース）を表示",
+	Long: `担当が決まっていて終了していないタスクの変更予定パス（paths、なければパスの形式の成果物）を
+リースとして agents/queue/leases.yaml に記録し、一覧と重なりを表示します。`,
+	Args: cobra.NoArgs,
+	RunE: runTaskLeases,
+}
+
+// task scope コマンド
+var taskScopeCmd = &cobra.Command{
+	Use:   "scope <task-id>",
+	Short: "タスクの実際の変更を変更予定パスと照合",
+	Long: `タスクのブランチの差分（ブランチがなければレポートの成果物）を変更予定パスと照合し、
+paths に含まれない変更を表示します。結果はタスクファイルの scope に記録されます。
+
+bastion watch は完了報告（agents/queue/reports）の書き込みを検知して同じ照合を行い、
+paths 外の変更があれば Marshall に通知します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskScope,
+}
+
+// task secrets コマンド
+var taskSecretsCmd = &cobra.Command{
+	Use:   "secrets <task-id>",
+	Short: "タスクのブランチに追加された秘密情報を検出",
+	Long: `タスクのブランチの各コミットで追加された行から API キー・トークン・秘密鍵・.env の値などを検出します
+（agents/config.yaml の secrets。外部サービスには問い合わせません）。
+後のコミットで削除した値も履歴に残るため検出します。
+
+bastion merge は秘密情報を検出したブランチをマージせず、Marshall に通知します。
+bastion watch は inbox・レポート・ログに書かれた秘密情報を伏せます。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskSecrets,
+}
+
+// task gates コマンド
+var taskGatesCmd = &cobra.Command{
+	Use:   "gates <task-id>",
+	Short: "タスクの worktree で品質ゲートを実行",
+	Long: `agents/config.yaml の gates.commands（テストや lint など）をタスクの worktree で順に実行し、
+終了コード・実行時間・出力の末尾を表示します。結果はレポートに記録しません。
+
+gates.enabled を true にすると、bastion watch は完了報告が届くたびに同じゲートを実行して結果をレポートの gates に記録し、
+失敗したゲートがあればタスクを担当の Specialist に差し戻します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskGates,
+}
+
+// task tests コマンド
+var taskTestsCmd = &cobra.Command{
+	Use:   "tests <task-id>",
+	Short: "タスクの worktree のテスト結果を集計",
+	Long: `タスクの worktree から agents/config.yaml の tests.results に一致するテスト結果
+（go test -json / JUnit XML / TAP）を読み込み、成功・失敗・スキップの数と失敗したテストを表示します。
+タスクの開始前に書かれたファイルは読みません。結果はレポートに記録しません。
+
+bastion watch は完了報告が届くと（品質ゲートがあればゲートの実行後に）同じ結果をレポートの tests に記録し、
+失敗したテストの割合に応じて評価（knowledge/evaluations）の correctness を抑えます。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskTests,
+}
+
+// task coverage コマンド
+var taskCoverageCmd = &cobra.Command{
+	Use:   "coverage <task-id>",
+	Short: "タスクの前後のカバレッジを比較",
+	Long: `タスクのブランチの分岐点と先頭をそれぞれ一時的な worktree に checkout し、
+agents/config.yaml の coverage.command でカバレッジを計測してパッケージごとの変化を表示します。
+結果はレポートに記録しません。
+
+coverage.enabled を true にすると、bastion watch は完了報告が届くと（品質ゲートがあればすべて成功した後に）
+同じ比較を行って結果をレポートの coverage と評価に記録し、基準を下回ったタスクを Marshall に通知します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskCoverage,
+}
+
+// task review コマンド
+var taskReviewCmd = &cobra.Command{
+	Use:   "review <task-id>",
+	Short: "タスクのレビューの状態を表示・承認",
+	Long: `タスクのレビュー（レビュータスク・担当・判定・コメント）を表示します。
+--approve を付けると、レビュータスクの判定を待たずに承認します（修正依頼の上限に達した場合や、
+ほかにレビューできる Specialist がいない場合など）。
+
+agents/config.yaml の review.enabled を true にすると、bastion watch は完了報告が届くと
+（品質ゲートがあればすべて成功した後に）差分と指令の完了条件を添えたレビュータスクを作成し、
+元の担当以外の Specialist に割り当てます。修正依頼なら元の担当に差し戻し、承認されるまでマージしません。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskReview,
+}
+
+// task attempts コマンド
+var taskAttemptsCmd = &cobra.Command{
+	Use:   "attempts <task-id>",
+	Short: "タスクの並列試行の結果を表示・選択",
+	Long: `attempts を指定したタスクの並列試行（試行ごとの担当・状態・基準ごとの結果・勝者と理由）を表示します。
+--select を付けると、評価やカバレッジを待たずに現在の結果で勝者を選びます（すべての試行が終わっている必要があります）。
+
+タスクに attempts: N（2 以上）を指定すると、bastion watch は N 個の試行タスクを別々の Specialist に割り当て、
+すべて終わると agents/config.yaml の attempts.criteria の順に結果を比較して勝者を選びます。
+元のタスクは勝者のブランチで completed になり、選ばれなかった試行のブランチは bastion/archive/ に退避します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskAttempts,
+}
+
+// task deps コマンド
+var taskDepsCmd = &cobra.Command{
+	Use:   "deps <task-id>",
+	Short: "タスクのブランチで変更された依存関係を表示",
+	Long: `タスクのブランチの依存定義ファイル（go.mod / package.json / composer.json / requirements.txt /
+pyproject.toml / Cargo.toml / Gemfile）を分岐点と比較し、追加・削除・更新された依存を表示します。
+
+bastion watch は完了報告の書き込みを検知して同じ比較を行い、結果をレポートの dependencies に記録します。
+intervention.require_approval に new_dependencies を挙げると、依存を追加したブランチはマージ前に承認を待ちます。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskDeps,
+}
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskRouteCmd)
+	taskCmd.AddCommand(taskDispatchCmd)
+	taskCmd.AddCommand(taskLeasesCmd)
+	taskCmd.AddCommand(taskScopeCmd)
+	taskCmd.AddCommand(taskGatesCmd)
+	taskCmd.AddCommand(taskTestsCmd)
+	taskCmd.AddCommand(taskCoverageCmd)
+	taskCmd.AddCommand(taskReviewCmd)
+	taskReviewCmd.Flags().BoolVar(&taskReviewApprove, "approve", false, "レビューを承認する")
+	taskReviewCmd.Flags().StringVar(&taskReviewBy, "by", "", "承認した人（省略時は $USER）")
+	taskCmd.AddCommand(taskAttemptsCmd)
+	taskAttemptsCmd.Flags().BoolVar(&taskAttemptsPick, "select", false, "評価・カバレッジを待たずに勝者を選ぶ")
+	taskCmd.AddCommand(taskDepsCmd)
+	taskCmd.AddCommand(taskSecretsCmd)
+}
+
+func runTaskRoute(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	decision, err := orch.RouteTask(taskID)
+	if decision != nil {
+		printRoutingCandidates(decision)
+	}
+	if errors.Is(err, orchestrator.ErrNoIdleSpecialist) {
+		terminal.PrintWarning("空いている Specialist がいません")
+		return err
+	}
+	if err != nil {
+		terminal.PrintError("タスクのルーティングに失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintSuccess("✓ %s → %s (スコア: %d)", taskID, decision.Specialist, decision.Score)
+	if len(decision.Matched) > 0 {
+		terminal.PrintInfo("一致: %s", strings.Join(decision.Matched, ", "))
+	}
+	terminal.PrintInfo("理由: %s", decision.Reason)
+	return nil
+}
+
+func runTaskDispatch(cmd *cobra.Command, args []string) error {
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	assignments, err := orch.Dispatch(time.Now())
+	for _, a := range assignments {
+		if a.StolenFrom != "" {
+			terminal.PrintSuccess("✓ %s → %s (%s から付け替え)", a.TaskID, a.Specialist, a.StolenFrom)
+		} else {
+			terminal.PrintSuccess("✓ %s → %s", a.TaskID, a.Specialist)
+		}
+	}
+	if err != nil {
+		terminal.PrintError("タスクの割り当てに失敗しました: %v", err)
+		return err
+	}
+
+	if len(assignments) == 0 {
+		terminal.PrintInfo("割り当てられるタスクはありません")
+	}
+	return nil
+}
+
+func runTaskLeases(cmd *cobra.Command, args []string) error {
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	leases, err := orch.Leases()
+	if err != nil {
+		terminal.PrintError("リースの取得に失敗しました: %v", err)
+		return err
+	}
+
+	if len(leases) == 0 {
+		terminal.PrintInfo("実行中のタスクのリースはありません")
+		return nil
+	}
+
+	terminal.PrintInfo("リース一覧:")
+	for _, lease := range leases {
+		fmt.Printf("  • %-18s %-18s %s\n", lease.TaskID, lease.Specialist, strings.Join(lease.Paths, ", "))
+	}
+
+	conflicts := orchestrator.OverlappingLeases(leases)
+	for _, c := range conflicts {
+		terminal.PrintWarning("%s と %s の変更予定パスが重なっています: %s", c.TaskID, c.With, strings.Join(c.Paths, ", "))
+	}
+	return nil
+}
+
+func runTaskScope(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	check, err := orch.CheckScope(taskID)
+	if err != nil {
+		terminal.PrintError("変更の照合に失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintInfo("変更されたファイル（%s）: %d 件", check.Source, len(check.Changed))
+	if len(check.Undeclared) == 0 {
+		terminal.PrintSuccess("✓ すべての変更が変更予定パスに含まれています")
+		return nil
+	}
+
+	terminal.PrintWarning("変更予定パスに含まれない変更があります:")
+	for _, file := range check.Undeclared {
+		fmt.Printf("  • %s\n", file)
+	}
+	return nil
+}
+
+func runTaskGates(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	results, err := orch.RunGates(taskID)
+	if err != nil {
+		terminal.PrintError("品質ゲートの実行に失敗しました: %v", err)
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Passed {
+			terminal.PrintSuccess("✓ %s", orchestrator.FormatGateResult(r))
+			continue
+		}
+		failed++
+		terminal.PrintError("%s", orchestrator.FormatGateResult(r))
+		if r.Output != "" {
+			fmt.Println(r.Output)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d gate(s) failed", failed)
+	}
+	return nil
+}
+
+func runTaskTests(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	results, err := orch.CollectTestResults(taskID)
+	if err != nil {
+		terminal.PrintError("テスト結果の読み込みに失敗しました: %v", err)
+		return err
+	}
+	if results == nil {
+		terminal.PrintInfo("%s のテスト結果は見つかりませんでした", taskID)
+		return nil
+	}
+
+	terminal.PrintInfo("%s のテスト結果（%s）: %s", taskID, results.Format, results)
+	for _, source := range results.Sources {
+		fmt.Printf("  • %s\n", source)
+	}
+	for _, name := range results.Failures {
+		terminal.PrintfRed("  ✗ %s\n", name)
+	}
+	return nil
+}
+
+func runTaskCoverage(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	report, err := orch.MeasureCoverage(taskID)
+	if err != nil {
+		terminal.PrintError("カバレッジの計測に失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintInfo("%s のカバレッジ: %s", taskID, orchestrator.FormatCoverage(*report))
+	for _, p := range report.Packages {
+		line := fmt.Sprintf("  • %-40s %5.1f%% -> %5.1f%% (%+.1f)", p.Package, p.Before, p.After, p.Delta)
+		switch {
+		case p.Added:
+			line += " [added]"
+		case p.Removed:
+			line += " [removed]"
+		}
+		if p.Delta < 0 {
+			terminal.PrintfYellow("%s\n", line)
+		} else {
+			fmt.Println(line)
+		}
+	}
+	for _, flag := range report.Flags {
+		terminal.PrintWarning("%s", flag)
+	}
+	return nil
+}
+
+func runTaskReview(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	if taskReviewApprove {
+		by := taskReviewBy
+		if by == "" {
+			by = os.Getenv("USER")
+		}
+		if by == "" {
+			return fmt.Errorf("--by is required when $USER is not set")
+		}
+		if _, err := orch.ApproveReview(taskID, by); err != nil {
+			terminal.PrintError("レビューの承認に失敗しました: %v", err)
+			return err
+		}
+		terminal.PrintSuccess("✓ %s のレビューを承認しました（%s）", taskID, by)
+		return nil
+	}
+
+	review, err := orch.TaskReview(taskID)
+	if err != nil {
+		terminal.PrintError("レビューの取得に失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintInfo("%s のレビュー（%d 回目）: %s", taskID, review.Round, orchestrator.FormatReview(*review))
+	fmt.Printf("  レビュータスク: %s\n", review.Task)
+	if review.Reviewer != "" {
+		fmt.Printf("  担当: %s\n", review.Reviewer)
+	}
+	for _, c := range review.Comments {
+		location := c.File
+		if c.Line > 0 {
+			location = fmt.Sprintf("%s:%d", c.File, c.Line)
+		}
+		if location != "" {
+			fmt.Printf("  • %s: %s\n", location, c.Comment)
+		} else {
+			fmt.Printf("  • %s\n", c.Comment)
+		}
+	}
+	return nil
+}
+
+func runTaskAttempts(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	var sel *communication.AttemptSelection
+	if taskAttemptsPick {
+		sel, err = orch.SelectAttempt(taskID)
+		if err == nil && sel == nil {
+			err = fmt.Errorf("selection is already in progress: %s", taskID)
+		}
+		if err != nil {
+			terminal.PrintError("勝者の選択に失敗しました: %v", err)
+			return err
+		}
+	} else if sel, err = orch.Attempts(taskID); err != nil {
+		terminal.PrintError("並列試行の取得に失敗しました: %v", err)
+		return err
+	}
+
+	switch {
+	case sel.Winner != "":
+		terminal.PrintSuccess("✓ %s の並列試行: %s を選びました（%s）", taskID, sel.Winner, sel.Reason)
+	case !sel.SelectedAt.IsZero():
+		terminal.PrintWarning("%s の並列試行はすべて失敗しました", taskID)
+	default:
+		terminal.PrintInfo("%s の並列試行: %d 件（基準: %s）", taskID, len(sel.Tasks), strings.Join(sel.Criteria, ", "))
+	}
+	for _, id := range sel.Tasks {
+		r := sel.Result(id)
+		if r == nil {
+			fmt.Printf("  • %s: 結果待ち\n", id)
+			continue
+		}
+		line := fmt.Sprintf("  • %s (%s) %s: %s", r.Task, r.Specialist, r.Status, orchestrator.FormatAttemptResult(*r, sel.Criteria))
+		if r.ArchivedBranch != "" {
+			line += " -> " + r.ArchivedBranch
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runTaskDeps(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	changes, err := orch.DependencyChanges(taskID)
+	if err != nil {
+		terminal.PrintError("依存関係の解析に失敗しました: %v", err)
+		return err
+	}
+
+	if len(changes) == 0 {
+		terminal.PrintSuccess("✓ 依存関係の変更はありません")
+		return nil
+	}
+
+	terminal.PrintInfo("依存関係の変更: %d 件", len(changes))
+	for _, c := range changes {
+		fmt.Printf("  • %-16s %s\n", c.Manifest, orchestrator.FormatDependencyChange(c))
+	}
+	return nil
+}
+
+func runTaskSecrets(cmd *cobra.Command, args []string) error {
+	taskID := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	findings, err := orch.ScanSecrets(taskID)
+	if err != nil {
+		terminal.PrintError("秘密情報の検出に失敗しました: %v", err)
+		return err
+	}
+
+	if len(findings) == 0 {
+		terminal.PrintSuccess("✓ 秘密情報は見つかりませんでした")
+		return nil
+	}
+
+	terminal.PrintWarning("秘密情報が見つかりました（マージされません）:")
+	for _, f := range findings {
+		fmt.Printf("  • %s:%d  %s (%s)\n", f.File, f.Line, f.Rule, f.Secret)
+	}
+	return nil
+}
+
+// 候補ごとのスコアを表示
+func printRoutingCandidates(decision *communication.RoutingDecision) {
+	terminal.PrintInfo("候補:")
+	for _, c := range decision.Candidates {
+		if c.Skipped != "" {
+			fmt.Printf("  • %-18s %3d  (対象外: %s)\n", c.Specialist, c.Score, c.Skipped)
+			continue
+		}
+		fmt.Printf("  • %-18s %3d\n", c.Specialist, c.Score)
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestTaskRoute_UnknownTask(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskRoute(&cobra.Command{}, []string{"task_missing"}); err == nil {
+		t.Error("task route should fail for unknown task")
+	}
+}
+
+func TestTaskDispatch_NoTasks(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskDispatch(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("task dispatch should succeed with no tasks: %v", err)
+	}
+}
+
+func TestTaskScope_UnknownTask(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskScope(&cobra.Command{}, []string{"task_missing"}); err == nil {
+		t.Error("task scope should fail for unknown task")
+	}
+}
+
+func TestTaskDeps_UnknownTask(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskDeps(&cobra.Command{}, []string{"task_missing"}); err == nil {
+		t.Error("task deps should fail for unknown task")
+	}
+}
+
+func TestTaskSecrets_UnknownTask(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskSecrets(&cobra.Command{}, []string{"task_missing"}); err == nil {
+		t.Error("task secrets should fail for unknown task")
+	}
+}
+
+func TestTaskGates_NoCommands(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskGates(&cobra.Command{}, []string{"task_001"}); err == nil {
+		t.Error("task gates should fail without gate commands")
+	}
+}
+
+func TestTaskTests_UnknownTask(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskTests(&cobra.Command{}, []string{"task_999"}); err == nil {
+		t.Error("task tests should fail for unknown task")
+	}
+}
+
+func TestTaskCoverage_UnknownTask(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskCoverage(&cobra.Command{}, []string{"task_999"}); err == nil {
+		t.Error("task coverage should fail for unknown task")
+	}
+}
+
+func TestTaskReview_NotRequested(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskReview(&cobra.Command{}, []string{"task_999"}); err == nil {
+		t.Error("task review should fail for unknown task")
+	}
+}
+
+func TestTaskAttempts_NoAttempts(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runTaskAttempts(&cobra.Command{}, []string{"task_999"}); err == nil {
+		t.Error("task attempts should fail for unknown task")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
 	"github.com/t-ishitsuka/bastion-core/internal/orchestrator"
 	"github.com/t-ishitsuka/bastion-core/internal/terminal"
 )
@@ -37,7 +38,19 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Orchestrator を作成
-	orch := orchestrator.NewOrchestrator(projectRoot, 0)
+	orch, err := orchestrator.NewOrchestrator(projectRoot, 0)
+	if err != nil {
+		terminal.PrintError("設定の読み込みに失敗しました: %v", err)
+		return err
+	}
+
+	// ログに書かれた秘密情報を伏せる
+	scanner, err := orch.SecretScanner()
+	if err != nil {
+		log.Printf("warning: failed to create secret scanner: %v", err)
+	} else if scanner != nil {
+		log.SetOutput(analysis.NewRedactingWriter(log.Writer(), scanner))
+	}
 
 	// watcher を起動
 	if err := orch.StartWatcher(); err != nil {
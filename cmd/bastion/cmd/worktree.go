@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/t-ishitsuka/bastion-core/internal/orchestrator"
+	"github.com/t-ishitsuka/bastion-core/internal/terminal"
+)
+
+// worktree コマンド
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Specialist の git worktree を管理",
+	Long: `Specialist ごとの git worktree（.worktrees/sp<N>）を一覧・削除します。
+
+agents/config.yaml の worktree.enabled を true にすると、Specialist は専用の worktree で起動し、
+タスクが割り当てられると bastion/task/<task_id> ブランチに切り替えて作業します。
+未コミットの変更がある worktree は削除しません。`,
+}
+
+// worktree list コマンド
+var worktreeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "worktree 一覧を表示",
+	Args:  cobra.NoArgs,
+	RunE:  runWorktreeList,
+}
+
+// worktree prune コマンド
+var worktreePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "使用されていない worktree を削除",
+	Long: `ディレクトリが削除された worktree の管理情報と、登録済みの Specialist が
+使用していない worktree を削除します。未コミットの変更がある worktree は残します。
+処理中の指令の統合用・コンフリクト解消待ちのロールバック用・カバレッジ計測用の
+worktree も削除しません。`,
+	Args: cobra.NoArgs,
+	RunE: runWorktreePrune,
+}
+
+// worktree remove コマンド
+var worktreeRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "worktree を削除",
+	Long: `worktree を削除します（ブランチは残ります）。
+Specialist が使用中の worktree と、未コミットの変更がある worktree は削除しません。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWorktreeRemove,
+}
+
+// worktree checkout コマンド
+var worktreeCheckoutCmd = &cobra.Command{
+	Use:   "checkout <specialist> <task-id>",
+	Short: "Specialist の worktree をタスク用ブランチに切り替え",
+	Long: `Marshall が手動でタスクを割り当てた場合に、Specialist の worktree を
+bastion/task/<task_id> ブランチに切り替えます（ブランチがなければ作成）。`,
+	Args: cobra.ExactArgs(2),
+	RunE: runWorktreeCheckout,
+}
+
+func init() {
+	rootCmd.AddCommand(worktreeCmd)
+	worktreeCmd.AddCommand(worktreeListCmd)
+	worktreeCmd.AddCommand(worktreePruneCmd)
+	worktreeCmd.AddCommand(worktreeRemoveCmd)
+	worktreeCmd.AddCommand(worktreeCheckoutCmd)
+}
+
+// カレントディレクトリをプロジェクトルートとして Orchestrator を作成
+func newProjectOrchestrator() (*orchestrator.Orchestrator, error) {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		terminal.PrintError("プロジェクトルートの取得に失敗: %v", err)
+		return nil, err
+	}
+	orch, err := orchestrator.NewOrchestrator(projectRoot, 0)
+	if err != nil {
+		terminal.PrintError("設定の読み込みに失敗しました: %v", err)
+		return nil, err
+	}
+	return orch, nil
+}
+
+func runWorktreeList(cmd *cobra.Command, args []string) error {
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	worktrees, err := orch.Worktrees()
+	if err != nil {
+		terminal.PrintError("worktree 一覧の取得に失敗しました: %v", err)
+		return err
+	}
+
+	if len(worktrees) == 0 {
+		terminal.PrintWarning("worktree はありません")
+		return nil
+	}
+
+	terminal.PrintInfo("worktree 一覧:")
+	for _, wt := range worktrees {
+		owner := wt.Specialist
+		if owner == "" {
+			owner = "-"
+		}
+
+		var notes []string
+		if wt.Dirty {
+			notes = append(notes, "未コミットの変更あり")
+		}
+		if wt.Prunable {
+			notes = append(notes, "ディレクトリなし")
+		}
+
+		line := fmt.Sprintf("  • %-8s %-32s %s", wt.Name, wt.Branch, owner)
+		if len(notes) > 0 {
+			terminal.PrintfYellow("%s  (%s)\n", line, strings.Join(notes, ", "))
+		} else {
+			fmt.Println(line)
+		}
+	}
+	return nil
+}
+
+func runWorktreePrune(cmd *cobra.Command, args []string) error {
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	removed, kept, err := orch.PruneWorktrees()
+	for _, name := range removed {
+		terminal.PrintSuccess("✓ %s を削除しました", name)
+	}
+	for _, name := range kept {
+		terminal.PrintWarning("%s は未コミットの変更があるため残しました", name)
+	}
+	if err != nil {
+		terminal.PrintError("worktree の削除に失敗しました: %v", err)
+		return err
+	}
+
+	if len(removed) == 0 && len(kept) == 0 {
+		terminal.PrintInfo("削除する worktree はありません")
+	}
+	return nil
+}
+
+func runWorktreeRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	if err := orch.RemoveWorktree(name); err != nil {
+		terminal.PrintError("worktree の削除に失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintSuccess("✓ %s を削除しました", name)
+	return nil
+}
+
+func runWorktreeCheckout(cmd *cobra.Command, args []string) error {
+	specialist, taskID := args[0], args[1]
+
+	orch, err := newProjectOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	branch, err := orch.CheckoutTask(specialist, taskID)
+	if err != nil {
+		terminal.PrintError("ブランチの切り替えに失敗しました: %v", err)
+		return err
+	}
+
+	terminal.PrintSuccess("✓ %s の worktree を %s に切り替えました", specialist, branch)
+	return nil
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestWorktreeRemove_NotFound(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runWorktreeRemove(&cobra.Command{}, []string{"sp9"}); err == nil {
+		t.Error("worktree remove should fail for unknown worktree")
+	}
+}
+
+func TestWorktreeCheckout_UnknownTask(t *testing.T) {
+	chdirTemp(t)
+
+	if err := runWorktreeCheckout(&cobra.Command{}, []string{"specialist_1", "task_missing"}); err == nil {
+		t.Error("worktree checkout should fail for unknown task")
+	}
+}
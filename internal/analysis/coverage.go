@@ -0,0 +1,280 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// カバレッジの形式
+const (
+	// go test -coverprofile
+	CoverageFormatGo = "go"
+	// LCOV（JavaScript / Python / Rust などのツールが出力する）
+	CoverageFormatLCOV = "lcov"
+)
+
+// パッケージ（ディレクトリ）ごとの文・行の数
+type CoverageCounts struct {
+	Statements int
+	Covered    int
+}
+
+// カバレッジ（%、小数第 1 位まで。文・行がなければ 0）
+func (c CoverageCounts) Percent() float64 {
+	if c.Statements == 0 {
+		return 0
+	}
+	return roundPercent(float64(c.Covered) / float64(c.Statements) * 100)
+}
+
+// パッケージ（ディレクトリ）ごとのカバレッジ
+type CoverageProfile map[string]CoverageCounts
+
+// 全体の文・行の数
+func (p CoverageProfile) Total() CoverageCounts {
+	var total CoverageCounts
+	for _, c := range p {
+		total.Statements += c.Statements
+		total.Covered += c.Covered
+	}
+	return total
+}
+
+// カバレッジの解析（root はカバレッジを計測したディレクトリ。絶対パスのファイル名を相対パスにする）
+type coverageParser func(content []byte, root string) (CoverageProfile, error)
+
+// 形式ごとの解析
+var coverageParsers = map[string]coverageParser{
+	CoverageFormatGo:   parseGoCoverProfile,
+	CoverageFormatLCOV: parseLCOV,
+}
+
+// 対応しているカバレッジの形式か
+func IsCoverageFormat(format string) bool {
+	_, ok := coverageParsers[format]
+	return ok
+}
+
+// カバレッジのファイルを解析
+func ParseCoverage(format string, content []byte, root string) (CoverageProfile, error) {
+	parse, ok := coverageParsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown coverage format: %s", format)
+	}
+	return parse(content, root)
+}
+
+// go test -coverprofile の出力を解析（同じブロックが複数回現れた場合はどれかで実行されていれば実行済み）
+func parseGoCoverProfile(content []byte, root string) (CoverageProfile, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]*block)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		// <file>:<start>,<end> <statements> <count>
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.Contains(fields[0], ":") {
+			return nil, fmt.Errorf("invalid coverprofile line %d: %q", lineNo, line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverprofile line %d: %q", lineNo, line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverprofile line %d: %q", lineNo, line)
+		}
+
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	profile := CoverageProfile{}
+	for key, b := range blocks {
+		file := key[:strings.LastIndex(key, ":")]
+		pkg := path.Dir(relativeCoveragePath(file, root))
+		counts := profile[pkg]
+		counts.Statements += b.statements
+		if b.covered {
+			counts.Covered += b.statements
+		}
+		profile[pkg] = counts
+	}
+	return profile, nil
+}
+
+// LCOV を解析（DA 行を数える。同じファイルの記録が複数あればどれかで実行されていれば実行済み）
+func parseLCOV(content []byte, root string) (CoverageProfile, error) {
+	lines := make(map[string]map[int]bool)
+	var file string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = relativeCoveragePath(strings.TrimPrefix(line, "SF:"), root)
+			if lines[file] == nil {
+				lines[file] = make(map[int]bool)
+			}
+		case strings.HasPrefix(line, "DA:") && file != "":
+			// DA:<line>,<count>[,<checksum>]
+			parts := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(parts) < 2 {
+				return nil, fmt.Errorf("invalid lcov line: %q", line)
+			}
+			number, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid lcov line: %q", line)
+			}
+			count, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid lcov line: %q", line)
+			}
+			lines[file][number] = lines[file][number] || count > 0
+		case line == "end_of_record":
+			file = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	profile := CoverageProfile{}
+	for file, hits := range lines {
+		pkg := path.Dir(file)
+		counts := profile[pkg]
+		for _, covered := range hits {
+			counts.Statements++
+			if covered {
+				counts.Covered++
+			}
+		}
+		profile[pkg] = counts
+	}
+	return profile, nil
+}
+
+// 計測したディレクトリからの相対パス（スラッシュ区切り）
+func relativeCoveragePath(file, root string) string {
+	if root != "" && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// パッケージのカバレッジの変化
+type PackageCoverageDelta struct {
+	Package string  `yaml:"package"`
+	Before  float64 `yaml:"before"`
+	After   float64 `yaml:"after"`
+	Delta   float64 `yaml:"delta"`
+	// タスクで追加・削除されたパッケージ
+	Added   bool `yaml:"added,omitempty"`
+	Removed bool `yaml:"removed,omitempty"`
+}
+
+// タスクの前後のカバレッジの比較
+type CoverageReport struct {
+	Format string `yaml:"format"`
+	// 計測したコミット（分岐点とブランチの先頭）
+	BaseCommit string  `yaml:"base_commit,omitempty"`
+	HeadCommit string  `yaml:"head_commit,omitempty"`
+	Before     float64 `yaml:"before"`
+	After      float64 `yaml:"after"`
+	Delta      float64 `yaml:"delta"`
+	// カバレッジが変化したパッケージ
+	Packages []PackageCoverageDelta `yaml:"packages,omitempty"`
+	// 基準を下回った理由（空なら問題なし）
+	Flags []string `yaml:"flags,omitempty"`
+}
+
+// タスクの前後のカバレッジを比較（変化のないパッケージは含めない）
+func CompareCoverage(before, after CoverageProfile) CoverageReport {
+	report := CoverageReport{
+		Before: before.Total().Percent(),
+		After:  after.Total().Percent(),
+	}
+	report.Delta = roundPercent(report.After - report.Before)
+
+	packages := make(map[string]bool)
+	for pkg := range before {
+		packages[pkg] = true
+	}
+	for pkg := range after {
+		packages[pkg] = true
+	}
+	for pkg := range packages {
+		b, inBefore := before[pkg]
+		a, inAfter := after[pkg]
+		delta := PackageCoverageDelta{
+			Package: pkg,
+			Before:  b.Percent(),
+			After:   a.Percent(),
+			Added:   !inBefore,
+			Removed: !inAfter,
+		}
+		delta.Delta = roundPercent(delta.After - delta.Before)
+		if delta.Delta == 0 && !delta.Added && !delta.Removed {
+			continue
+		}
+		report.Packages = append(report.Packages, delta)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].Package < report.Packages[j].Package
+	})
+	return report
+}
+
+// 基準を下回った理由を記録する
+// 全体またはパッケージのカバレッジが maxDrop ポイントを超えて下がった場合と、
+// 下がった結果が minCoverage（0 なら判定しない）を下回った場合
+func (r *CoverageReport) Flag(maxDrop, minCoverage float64) {
+	r.Flags = nil
+	if r.Delta < 0 && -r.Delta > maxDrop {
+		r.Flags = append(r.Flags, fmt.Sprintf("total: %.1f%% -> %.1f%% (%+.1f)", r.Before, r.After, r.Delta))
+	}
+	if r.Delta < 0 && minCoverage > 0 && r.After < minCoverage {
+		r.Flags = append(r.Flags, fmt.Sprintf("total: %.1f%% is below %.1f%%", r.After, minCoverage))
+	}
+	for _, p := range r.Packages {
+		// 追加・削除されたパッケージは比較しない
+		if p.Added || p.Removed {
+			continue
+		}
+		if p.Delta < 0 && -p.Delta > maxDrop {
+			r.Flags = append(r.Flags, fmt.Sprintf("%s: %.1f%% -> %.1f%% (%+.1f)", p.Package, p.Before, p.After, p.Delta))
+		}
+	}
+}
+
+// 小数第 1 位に丸める
+func roundPercent(v float64) float64 {
+	return math.Round(v*10) / 10
+}
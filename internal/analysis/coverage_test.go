@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCoverage_Go(t *testing.T) {
+	content := `mode: set
+example.com/app/auth/login.go:10.2,12.16 2 1
+example.com/app/auth/login.go:12.16,14.3 1 0
+example.com/app/auth/token.go:5.1,7.2 3 0
+example.com/app/auth/token.go:5.1,7.2 3 1
+example.com/app/db/db.go:3.1,4.2 4 0
+`
+	profile, err := ParseCoverage(CoverageFormatGo, []byte(content), "")
+	if err != nil {
+		t.Fatalf("ParseCoverage failed: %v", err)
+	}
+	want := CoverageProfile{
+		"example.com/app/auth": {Statements: 6, Covered: 5},
+		"example.com/app/db":   {Statements: 4, Covered: 0},
+	}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+	if got := profile.Total().Percent(); got != 50 {
+		t.Errorf("unexpected total: %v", got)
+	}
+
+	if _, err := ParseCoverage(CoverageFormatGo, []byte("mode: set\nbroken line\n"), ""); err == nil {
+		t.Error("expected error for invalid coverprofile")
+	}
+	if _, err := ParseCoverage("cobertura", nil, ""); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestParseCoverage_LCOV(t *testing.T) {
+	content := `TN:
+SF:/work/wt/src/auth/login.js
+DA:1,1
+DA:2,0
+DA:3,5
+LF:3
+LH:2
+end_of_record
+SF:src/util.js
+DA:1,0
+end_of_record
+`
+	profile, err := ParseCoverage(CoverageFormatLCOV, []byte(content), "/work/wt")
+	if err != nil {
+		t.Fatalf("ParseCoverage failed: %v", err)
+	}
+	want := CoverageProfile{
+		"src/auth": {Statements: 3, Covered: 2},
+		"src":      {Statements: 1, Covered: 0},
+	}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+}
+
+func TestCompareCoverage(t *testing.T) {
+	before := CoverageProfile{
+		"app/auth": {Statements: 10, Covered: 8},
+		"app/db":   {Statements: 10, Covered: 5},
+		"app/old":  {Statements: 5, Covered: 5},
+	}
+	after := CoverageProfile{
+		"app/auth": {Statements: 20, Covered: 10},
+		"app/db":   {Statements: 10, Covered: 5},
+		"app/new":  {Statements: 5, Covered: 0},
+	}
+
+	report := CompareCoverage(before, after)
+	if report.Before != 72 || report.After != 42.9 || report.Delta != -29.1 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	want := []PackageCoverageDelta{
+		{Package: "app/auth", Before: 80, After: 50, Delta: -30},
+		{Package: "app/new", Before: 0, After: 0, Delta: 0, Added: true},
+		{Package: "app/old", Before: 100, After: 0, Delta: -100, Removed: true},
+	}
+	if !reflect.DeepEqual(report.Packages, want) {
+		t.Errorf("unexpected packages: %+v", report.Packages)
+	}
+
+	report.Flag(1, 60)
+	wantFlags := []string{
+		"total: 72.0% -> 42.9% (-29.1)",
+		"total: 42.9% is below 60.0%",
+		"app/auth: 80.0% -> 50.0% (-30.0)",
+	}
+	if !reflect.DeepEqual(report.Flags, wantFlags) {
+		t.Errorf("unexpected flags: %q", report.Flags)
+	}
+
+	// 上がった場合は基準を下回っていても問題にしない
+	report = CompareCoverage(after, before)
+	report.Flag(1, 90)
+	if len(report.Flags) != 0 {
+		t.Errorf("raised coverage should not be flagged: %q", report.Flags)
+	}
+}
@@ -0,0 +1,390 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 依存関係の変更の種類
+const (
+	// 追加された
+	DependencyAdded = "added"
+	// 削除された
+	DependencyRemoved = "removed"
+	// 新しいバージョンに更新された
+	DependencyUpgraded = "upgraded"
+	// 古いバージョンに戻された
+	DependencyDowngraded = "downgraded"
+	// バージョン指定が変わった（新旧を比較できない）
+	DependencyChanged = "changed"
+)
+
+// 依存関係の変更
+type DependencyChange struct {
+	// 変更された依存定義ファイル（リポジトリルートからの相対パス）
+	Manifest string `yaml:"manifest"`
+	Name     string `yaml:"name"`
+	Change   string `yaml:"change"`
+	From     string `yaml:"from,omitempty"`
+	To       string `yaml:"to,omitempty"`
+}
+
+// 依存定義ファイルの解析（依存名 → バージョン指定）
+type manifestParser func(content []byte) (map[string]string, error)
+
+// ファイル名ごとの解析
+var manifestParsers = map[string]manifestParser{
+	"go.mod":           parseGoMod,
+	"package.json":     parsePackageJSON,
+	"composer.json":    parseComposerJSON,
+	"requirements.txt": parseRequirements,
+	"Cargo.toml":       parseCargoToml,
+	"pyproject.toml":   parsePyproject,
+	"Gemfile":          parseGemfile,
+}
+
+// 依存定義ファイルか（go.mod / package.json / requirements.txt / Cargo.toml など）
+func IsManifest(file string) bool {
+	_, ok := manifestParsers[path.Base(file)]
+	return ok
+}
+
+// 依存定義ファイルを解析
+func ParseManifest(file string, content []byte) (map[string]string, error) {
+	parse, ok := manifestParsers[path.Base(file)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported manifest: %s", file)
+	}
+	deps, err := parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return deps, nil
+}
+
+// 変更前後の依存定義ファイルを比較（追加・削除されたファイルは空の内容として渡す）
+// 返り値は依存名の順
+func DiffDependencies(file string, before, after []byte) ([]DependencyChange, error) {
+	old, err := ParseManifest(file, before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := ParseManifest(file, after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []DependencyChange
+	for name, to := range current {
+		from, ok := old[name]
+		switch {
+		case !ok:
+			changes = append(changes, DependencyChange{Manifest: file, Name: name, Change: DependencyAdded, To: to})
+		case from != to:
+			changes = append(changes, DependencyChange{Manifest: file, Name: name, Change: versionChange(from, to), From: from, To: to})
+		}
+	}
+	for name, from := range old {
+		if _, ok := current[name]; !ok {
+			changes = append(changes, DependencyChange{Manifest: file, Name: name, Change: DependencyRemoved, From: from})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes, nil
+}
+
+// バージョン指定の変化の種類（数字の部分を比較する）
+func versionChange(from, to string) string {
+	a, b := versionNumbers(from), versionNumbers(to)
+	if len(a) == 0 || len(b) == 0 {
+		return DependencyChanged
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return DependencyUpgraded
+			}
+			return DependencyDowngraded
+		}
+	}
+	if len(a) < len(b) {
+		return DependencyUpgraded
+	}
+	if len(a) > len(b) {
+		return DependencyDowngraded
+	}
+	return DependencyChanged
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// バージョン指定の最初の数字の並び（例: "^1.2.3" -> [1 2 3]）
+func versionNumbers(version string) []int {
+	match := versionPattern.FindString(version)
+	if match == "" {
+		return nil
+	}
+	var numbers []int
+	for _, part := range strings.Split(match, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// go.mod の require（ブロック形式と 1 行形式）
+func parseGoMod(content []byte) (map[string]string, error) {
+	deps := make(map[string]string)
+	inBlock := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case line == "":
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case line == "require (":
+			inBlock = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			deps[fields[0]] = fields[1]
+		}
+	}
+	return deps, scanner.Err()
+}
+
+// package.json の dependencies / devDependencies / peerDependencies / optionalDependencies
+func parsePackageJSON(content []byte) (map[string]string, error) {
+	return parseJSONSections(content, "dependencies", "devDependencies", "peerDependencies", "optionalDependencies")
+}
+
+// composer.json の require / require-dev
+func parseComposerJSON(content []byte) (map[string]string, error) {
+	return parseJSONSections(content, "require", "require-dev")
+}
+
+// JSON のオブジェクトから依存名とバージョンを集める
+func parseJSONSections(content []byte, sections ...string) (map[string]string, error) {
+	deps := make(map[string]string)
+	if len(bytes.TrimSpace(content)) == 0 {
+		return deps, nil
+	}
+
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		raw, ok := manifest[section]
+		if !ok {
+			continue
+		}
+		var entries map[string]string
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return nil, fmt.Errorf("%s: %w", section, err)
+		}
+		for name, version := range entries {
+			deps[name] = version
+		}
+	}
+	return deps, nil
+}
+
+// PEP 508 形式の依存指定から名前とバージョン指定を取り出す（例: "requests[socks]>=2.31; python_version>'3.8'"）
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+func parseRequirement(spec string) (string, string, bool) {
+	spec = strings.TrimSpace(spec)
+	if i := strings.Index(spec, ";"); i >= 0 {
+		spec = strings.TrimSpace(spec[:i])
+	}
+	match := requirementPattern.FindStringSubmatch(spec)
+	if match == nil {
+		return "", "", false
+	}
+	// パッケージ名は大文字小文字と区切り文字を区別しない
+	name := strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(match[1]))
+	return name, strings.TrimSpace(match[3]), true
+}
+
+// requirements.txt（オプション行・URL 指定は無視）
+func parseRequirements(content []byte) (map[string]string, error) {
+	deps := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		if name, version, ok := parseRequirement(line); ok {
+			deps[name] = version
+		}
+	}
+	return deps, scanner.Err()
+}
+
+// TOML のテーブルごとに "key = value" 行を読む（依存定義に必要な範囲のみ）
+// fn にはテーブル名・キー・値（前後の空白を除いた生の文字列）を渡す
+func scanTomlTables(content []byte, fn func(table, key, value string)) error {
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		fn(table, strings.Trim(strings.TrimSpace(key), `"'`), strings.TrimSpace(value))
+	}
+	return scanner.Err()
+}
+
+var tomlVersionPattern = regexp.MustCompile(`version\s*=\s*["']([^"']*)["']`)
+
+// TOML の依存のバージョン指定（"1.0" または { version = "1.0", ... }）
+func tomlVersion(value string) string {
+	if strings.HasPrefix(value, "{") {
+		if match := tomlVersionPattern.FindStringSubmatch(value); match != nil {
+			return match[1]
+		}
+		return strings.TrimSpace(value)
+	}
+	return strings.Trim(value, `"'`)
+}
+
+// Cargo.toml の [dependencies] / [dev-dependencies] / [build-dependencies]
+// （[target.'cfg(...)'.dependencies] も含む）
+func parseCargoToml(content []byte) (map[string]string, error) {
+	deps := make(map[string]string)
+	err := scanTomlTables(content, func(table, key, value string) {
+		if isCargoDependencyTable(table) {
+			deps[key] = tomlVersion(value)
+		}
+	})
+	return deps, err
+}
+
+func isCargoDependencyTable(table string) bool {
+	for _, suffix := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+		if table == suffix || strings.HasSuffix(table, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pyproject.toml の [project] dependencies と [tool.poetry.*dependencies]
+func parsePyproject(content []byte) (map[string]string, error) {
+	deps := make(map[string]string)
+
+	// dependencies = [ ... ] は複数行にわたるため、配列の終わりまでまとめて読む
+	inArray := false
+	var array strings.Builder
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inArray {
+			array.WriteString(line)
+			if strings.Contains(line, "]") {
+				inArray = false
+				addPyprojectArray(deps, array.String())
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch {
+		case table == "project" && key == "dependencies":
+			array.Reset()
+			array.WriteString(value)
+			if strings.Contains(value, "]") {
+				addPyprojectArray(deps, value)
+			} else {
+				inArray = true
+			}
+		case strings.HasPrefix(table, "tool.poetry.") && strings.HasSuffix(table, "dependencies"):
+			// python 自体のバージョン指定は依存ではない
+			if name := strings.Trim(key, `"'`); name != "python" {
+				deps[strings.ToLower(name)] = tomlVersion(value)
+			}
+		}
+	}
+	return deps, scanner.Err()
+}
+
+var quotedPattern = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+// TOML の文字列配列の依存指定を追加
+func addPyprojectArray(deps map[string]string, array string) {
+	for _, match := range quotedPattern.FindAllStringSubmatch(array, -1) {
+		spec := match[1] + match[2]
+		if name, version, ok := parseRequirement(spec); ok {
+			deps[name] = version
+		}
+	}
+}
+
+var gemPattern = regexp.MustCompile(`^gem\s+["']([^"']+)["']\s*(?:,\s*["']([^"']+)["'])?`)
+
+// Gemfile の gem 行
+func parseGemfile(content []byte) (map[string]string, error) {
+	deps := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if match := gemPattern.FindStringSubmatch(strings.TrimSpace(scanner.Text())); match != nil {
+			deps[match[1]] = match[2]
+		}
+	}
+	return deps, scanner.Err()
+}
@@ -0,0 +1,162 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 変更を "<name>:<change>:<from>-><to>" の形式に並べる
+func formatChanges(changes []DependencyChange) string {
+	var parts []string
+	for _, c := range changes {
+		parts = append(parts, fmt.Sprintf("%s:%s:%s->%s", c.Name, c.Change, c.From, c.To))
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestIsManifest(t *testing.T) {
+	for _, file := range []string{"go.mod", "web/package.json", "requirements.txt", "crates/core/Cargo.toml", "Gemfile"} {
+		if !IsManifest(file) {
+			t.Errorf("%s should be a manifest", file)
+		}
+	}
+	for _, file := range []string{"go.sum", "package-lock.json", "README.md"} {
+		if IsManifest(file) {
+			t.Errorf("%s should not be a manifest", file)
+		}
+	}
+}
+
+func TestDiffDependencies_GoMod(t *testing.T) {
+	before := `module example.com/app
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	golang.org/x/sys v0.13.0 // indirect
+)
+`
+	after := `module example.com/app
+
+go 1.22
+
+require (
+	github.com/spf13/cobra v1.7.0
+	github.com/google/uuid v1.6.0
+	golang.org/x/sys v0.20.0 // indirect
+)
+`
+	changes, err := DiffDependencies("go.mod", []byte(before), []byte(after))
+	if err != nil {
+		t.Fatalf("DiffDependencies failed: %v", err)
+	}
+	want := "github.com/fsnotify/fsnotify:removed:v1.9.0->," +
+		"github.com/google/uuid:added:->v1.6.0," +
+		"github.com/spf13/cobra:downgraded:v1.8.0->v1.7.0," +
+		"golang.org/x/sys:upgraded:v0.13.0->v0.20.0"
+	if got := formatChanges(changes); got != want {
+		t.Errorf("unexpected changes:\n got: %s\nwant: %s", got, want)
+	}
+	if changes[0].Manifest != "go.mod" {
+		t.Errorf("manifest should be recorded: %+v", changes[0])
+	}
+}
+
+func TestDiffDependencies_PackageJSON(t *testing.T) {
+	before := `{"dependencies": {"react": "^18.2.0"}, "devDependencies": {"vitest": "^1.0.0"}}`
+	after := `{"dependencies": {"react": "^18.3.1", "zod": "^3.22.0"}, "devDependencies": {"vitest": "^1.0.0"}}`
+
+	// 新しく追加したファイルは変更前を空として比較する
+	changes, err := DiffDependencies("web/package.json", nil, []byte(before))
+	if err != nil || formatChanges(changes) != "react:added:->^18.2.0,vitest:added:->^1.0.0" {
+		t.Errorf("unexpected changes for new file: %s (%v)", formatChanges(changes), err)
+	}
+
+	changes, err = DiffDependencies("web/package.json", []byte(before), []byte(after))
+	if err != nil {
+		t.Fatalf("DiffDependencies failed: %v", err)
+	}
+	if got := formatChanges(changes); got != "react:upgraded:^18.2.0->^18.3.1,zod:added:->^3.22.0" {
+		t.Errorf("unexpected changes: %s", got)
+	}
+
+	if _, err := DiffDependencies("package.json", nil, []byte("{broken")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    map[string]string
+	}{
+		{
+			file:    "requirements.txt",
+			content: "# comment\nRequests[socks]>=2.31 ; python_version > '3.8'\nflask==3.0.0  # web\n-r base.txt\nnumpy\ngit+https://github.com/x/y.git\n",
+			want:    map[string]string{"requests": ">=2.31", "flask": "==3.0.0", "numpy": ""},
+		},
+		{
+			file:    "Cargo.toml",
+			content: "[package]\nname = \"app\"\nversion = \"0.1.0\"\n\n[dependencies]\nserde = { version = \"1.0\", features = [\"derive\"] }\ntokio = \"1.36\"\n\n[dev-dependencies]\ninsta = \"1.34\"\n",
+			want:    map[string]string{"serde": "1.0", "tokio": "1.36", "insta": "1.34"},
+		},
+		{
+			file:    "pyproject.toml",
+			content: "[project]\nname = \"app\"\ndependencies = [\n  \"httpx>=0.27\",\n  \"pydantic\",\n]\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\nrich = \"^13.0\"\n",
+			want:    map[string]string{"httpx": ">=0.27", "pydantic": "", "rich": "^13.0"},
+		},
+		{
+			file:    "Gemfile",
+			content: "source 'https://rubygems.org'\ngem 'rails', '~> 7.1'\ngem \"puma\"\n",
+			want:    map[string]string{"rails": "~> 7.1", "puma": ""},
+		},
+		{
+			file:    "composer.json",
+			content: `{"require": {"php": ">=8.2", "monolog/monolog": "^3.0"}, "require-dev": {"phpunit/phpunit": "^11"}}`,
+			want:    map[string]string{"php": ">=8.2", "monolog/monolog": "^3.0", "phpunit/phpunit": "^11"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := ParseManifest(tt.file, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("ParseManifest failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			for name, version := range tt.want {
+				if v, ok := got[name]; !ok || v != version {
+					t.Errorf("expected %s=%q, got %q (%v)", name, version, v, ok)
+				}
+			}
+		})
+	}
+
+	if _, err := ParseManifest("build.gradle", nil); err == nil {
+		t.Error("expected error for unsupported manifest")
+	}
+}
+
+func TestVersionChange(t *testing.T) {
+	tests := []struct {
+		from, to, want string
+	}{
+		{"v1.2.3", "v1.10.0", DependencyUpgraded},
+		{"^2.0", "^1.9.9", DependencyDowngraded},
+		{"1.2", "1.2.1", DependencyUpgraded},
+		{"^1.0", "~1.0", DependencyChanged},
+		{"latest", "1.0", DependencyChanged},
+	}
+	for _, tt := range tests {
+		if got := versionChange(tt.from, tt.to); got != tt.want {
+			t.Errorf("versionChange(%q, %q) = %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package analysis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 検出した秘密情報の置き換え（例: [REDACTED:aws_access_key]）
+const redactedFormat = "[REDACTED:%s]"
+
+// コミットされた .env ファイルの値の検出規則
+const RuleDotenv = "dotenv"
+
+// 秘密情報の検出規則
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+	// 秘密情報の部分のグループ番号（0 なら一致した全体）
+	group int
+	// 秘密情報の部分のエントロピーが下限未満なら検出しない
+	entropy bool
+}
+
+// 組み込みの検出規則
+var secretRules = []secretRule{
+	{name: "private_key", pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?-----END [A-Z ]*PRIVATE KEY-----)?`)},
+	{name: "aws_access_key", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "github_token", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{name: "slack_token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{name: "stripe_key", pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{16,}\b`)},
+	{name: "google_api_key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{name: "api_key", pattern: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	// 名前が秘密情報らしい変数への代入は、値のエントロピーが高い場合のみ検出する
+	{
+		name:    "generic_secret",
+		pattern: regexp.MustCompile(`(?i)\b[A-Za-z0-9_]*(?:api[_-]?key|secret|token|password|passwd|credential|access[_-]?key)[A-Za-z0-9_]*["']?\s*(?::|=|:=|=>)\s*["']?([A-Za-z0-9+/=_\-.~!@#%^&*]{8,})`),
+		group:   1,
+		entropy: true,
+	},
+}
+
+// 検出した秘密情報
+type SecretFinding struct {
+	// 検出規則の名前
+	Rule string `yaml:"rule"`
+	// 検出したファイル（リポジトリルートからの相対パス）・レポートのフィールドなど
+	File string `yaml:"file"`
+	// 行番号（1 始まり）
+	Line int `yaml:"line"`
+	// 先頭のみ残して伏せた値（例: AKIA****）
+	Secret string `yaml:"secret"`
+}
+
+// 検出結果の表示（例: "aws_access_key config/aws.go:12 (AKIA****)"）
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s %s:%d (%s)", f.Rule, f.File, f.Line, f.Secret)
+}
+
+// 正規表現とエントロピーによる秘密情報の検出（外部サービスには問い合わせない）
+type SecretScanner struct {
+	// generic_secret のエントロピーの下限（bit/文字）
+	entropyThreshold float64
+	// 一致した値・行は検出しない
+	allowlist []*regexp.Regexp
+}
+
+// 検出器を作成
+// allowlist は値または行に一致する正規表現（例やダミーの値を除外する）
+func NewSecretScanner(allowlist []string, entropyThreshold float64) (*SecretScanner, error) {
+	s := &SecretScanner{entropyThreshold: entropyThreshold}
+	for _, expr := range allowlist {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist pattern %q: %w", expr, err)
+		}
+		s.allowlist = append(s.allowlist, re)
+	}
+	return s, nil
+}
+
+// 許可された値・行か
+func (s *SecretScanner) allowed(secret, line string) bool {
+	for _, re := range s.allowlist {
+		if re.MatchString(secret) || re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// 一致した秘密情報の位置（text 内の [start, end)）
+type secretMatch struct {
+	rule       string
+	start, end int
+}
+
+// テキスト中の秘密情報の位置（重なる一致は先の規則を優先する）
+func (s *SecretScanner) matches(text string) []secretMatch {
+	var found []secretMatch
+	for _, rule := range secretRules {
+		for _, loc := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[2*rule.group], loc[2*rule.group+1]
+			if start < 0 {
+				continue
+			}
+			secret := text[start:end]
+			if rule.entropy && Entropy(secret) < s.entropyThreshold {
+				continue
+			}
+			if s.allowed(secret, lineAt(text, start)) || overlaps(found, start, end) {
+				continue
+			}
+			found = append(found, secretMatch{rule: rule.name, start: start, end: end})
+		}
+	}
+	return found
+}
+
+// 位置を含む行
+func lineAt(text string, pos int) string {
+	start := strings.LastIndexByte(text[:pos], '\n') + 1
+	end := strings.IndexByte(text[pos:], '\n')
+	if end < 0 {
+		return text[start:]
+	}
+	return text[start : pos+end]
+}
+
+// 既に検出した範囲と重なるか
+func overlaps(found []secretMatch, start, end int) bool {
+	for _, m := range found {
+		if start < m.end && m.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// テキスト中の秘密情報を検出（file はファイルのパスやレポートのフィールド名）
+// .env ファイルは空でない値をすべて秘密情報として扱う
+func (s *SecretScanner) ScanText(file, text string) []SecretFinding {
+	var findings []SecretFinding
+	for i, line := range strings.Split(text, "\n") {
+		findings = append(findings, s.scanLine(file, i+1, line)...)
+	}
+	return findings
+}
+
+// 1 行の秘密情報を検出
+func (s *SecretScanner) scanLine(file string, line int, text string) []SecretFinding {
+	if IsDotenv(file) {
+		if secret, ok := dotenvValue(text); ok && !s.allowed(secret, text) {
+			return []SecretFinding{{Rule: RuleDotenv, File: file, Line: line, Secret: maskSecret(secret)}}
+		}
+	}
+
+	var findings []SecretFinding
+	for _, m := range s.matches(text) {
+		findings = append(findings, SecretFinding{Rule: m.rule, File: file, Line: line, Secret: maskSecret(text[m.start:m.end])})
+	}
+	return findings
+}
+
+// unified diff の追加行から秘密情報を検出（削除されたファイル・行は対象外）
+func (s *SecretScanner) ScanDiff(diff string) []SecretFinding {
+	var findings []SecretFinding
+	var file string
+	var line int
+	inHunk := false
+
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			file, inHunk = "", false
+		case !inHunk && strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(text, "@@"):
+			line, inHunk = hunkStart(text), true
+		case !inHunk:
+		case strings.HasPrefix(text, "+"):
+			if file != "" {
+				findings = append(findings, s.scanLine(file, line, text[1:])...)
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// hunk ヘッダーの変更後の開始行（例: "@@ -1,2 +3,4 @@" → 3）
+func hunkStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 1
+	}
+	rest, _, _ = strings.Cut(rest, " ")
+	rest, _, _ = strings.Cut(rest, ",")
+	n, err := strconv.Atoi(rest)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// テキスト中の秘密情報を [REDACTED:<規則>] に置き換える
+func (s *SecretScanner) Redact(text string) string {
+	found := s.matches(text)
+	if len(found) == 0 {
+		return text
+	}
+
+	// 前から順に置き換える
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].start < found[j].start
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, m := range found {
+		b.WriteString(text[last:m.start])
+		fmt.Fprintf(&b, redactedFormat, m.rule)
+		last = m.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// .env ファイルか（.env.example などのひな形は除く）
+func IsDotenv(file string) bool {
+	base := path.Base(file)
+	if base != ".env" && !strings.HasPrefix(base, ".env.") {
+		return false
+	}
+	switch path.Ext(base) {
+	case ".example", ".sample", ".template", ".dist":
+		return false
+	}
+	return true
+}
+
+// .env の行の値（コメント・空の値は false）
+func dotenvValue(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	_, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	return value, ok && value != ""
+}
+
+// 先頭のみ残して伏せる
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + "****"
+}
+
+// 文字列のシャノンエントロピー（bit/文字）
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// 書き込む内容の秘密情報を伏せてから書き込む io.Writer（ログの出力先に使う）
+type redactingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	scanner *SecretScanner
+}
+
+// 秘密情報を伏せて w に書き込む io.Writer を作成
+func NewRedactingWriter(w io.Writer, scanner *SecretScanner) io.Writer {
+	return &redactingWriter{w: w, scanner: scanner}
+}
+
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := io.WriteString(r.w, r.scanner.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	// 呼び出し元には渡された長さを書き込んだものとして返す
+	return len(p), nil
+}
@@ -0,0 +1,299 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// テスト結果の形式
+const (
+	// go test -json
+	TestFormatGoJSON = "go-json"
+	// JUnit XML
+	TestFormatJUnit = "junit"
+	// TAP（Test Anything Protocol）
+	TestFormatTAP = "tap"
+)
+
+// テスト結果の集計
+type TestResults struct {
+	// 読み込んだ形式（複数の形式を合わせた場合はカンマ区切り）
+	Format  string `yaml:"format"`
+	Passed  int    `yaml:"passed"`
+	Failed  int    `yaml:"failed"`
+	Skipped int    `yaml:"skipped"`
+	// 失敗したテストの名前
+	Failures []string      `yaml:"failures,omitempty"`
+	Duration time.Duration `yaml:"duration"`
+	// 読み込んだファイル（worktree からの相対パス）
+	Sources []string `yaml:"sources,omitempty"`
+}
+
+// 実行したテストの数（スキップを除く）
+func (r TestResults) Total() int {
+	return r.Passed + r.Failed
+}
+
+// テスト結果の表示（例: "12 passed, 1 failed, 2 skipped (3.2s)"）
+func (r TestResults) String() string {
+	return fmt.Sprintf("%d passed, %d failed, %d skipped (%s)", r.Passed, r.Failed, r.Skipped, r.Duration)
+}
+
+// 別のテスト結果を合わせる
+func (r *TestResults) Merge(other TestResults) {
+	r.Passed += other.Passed
+	r.Failed += other.Failed
+	r.Skipped += other.Skipped
+	r.Failures = append(r.Failures, other.Failures...)
+	r.Duration += other.Duration
+	r.Sources = append(r.Sources, other.Sources...)
+	for _, format := range strings.Split(other.Format, ",") {
+		if format == "" || containsFormat(r.Format, format) {
+			continue
+		}
+		if r.Format != "" {
+			r.Format += ","
+		}
+		r.Format += format
+	}
+}
+
+func containsFormat(formats, format string) bool {
+	for _, f := range strings.Split(formats, ",") {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// テスト結果のファイルを解析（形式は拡張子、なければ内容から判定する）
+func ParseTestResults(file string, content []byte) (*TestResults, error) {
+	format := detectTestFormat(file, content)
+	var (
+		results *TestResults
+		err     error
+	)
+	switch format {
+	case TestFormatGoJSON:
+		results, err = parseGoTestJSON(content)
+	case TestFormatJUnit:
+		results, err = parseJUnit(content)
+	case TestFormatTAP:
+		results, err = parseTAP(content)
+	default:
+		return nil, fmt.Errorf("unknown test result format: %s", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	results.Format = format
+	results.Sources = []string{file}
+	return results, nil
+}
+
+// テスト結果の形式を判定
+func detectTestFormat(file string, content []byte) string {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".xml":
+		return TestFormatJUnit
+	case ".tap":
+		return TestFormatTAP
+	case ".json", ".jsonl", ".ndjson":
+		return TestFormatGoJSON
+	}
+
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return TestFormatJUnit
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return TestFormatGoJSON
+	case bytes.HasPrefix(trimmed, []byte("TAP version")), tapPlan.Match(firstLine(trimmed)), tapResult.Match(firstLine(trimmed)):
+		return TestFormatTAP
+	}
+	return ""
+}
+
+func firstLine(content []byte) []byte {
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		return content[:i]
+	}
+	return content
+}
+
+// go test -json のイベント
+type goTestEvent struct {
+	Action  string  `json:"Action"`
+	Package string  `json:"Package"`
+	Test    string  `json:"Test"`
+	Elapsed float64 `json:"Elapsed"`
+}
+
+// go test -json の出力を解析
+// パッケージ単位の失敗（ビルドエラーなど）で失敗したテストがなければ、パッケージを失敗として数える
+func parseGoTestJSON(content []byte) (*TestResults, error) {
+	results := &TestResults{}
+	failedTests := make(map[string]bool)
+	var failedPackages []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		// go test -json に混ざるビルド出力などは読み飛ばす
+		if !bytes.HasPrefix(line, []byte("{")) {
+			continue
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, err
+		}
+
+		if event.Test == "" {
+			switch event.Action {
+			case "pass", "fail", "skip":
+				results.Duration += secondsDuration(event.Elapsed)
+			}
+			if event.Action == "fail" {
+				failedPackages = append(failedPackages, event.Package)
+			}
+			continue
+		}
+
+		switch event.Action {
+		case "pass":
+			results.Passed++
+		case "fail":
+			results.Failed++
+			results.Failures = append(results.Failures, event.Package+"."+event.Test)
+			failedTests[event.Package] = true
+		case "skip":
+			results.Skipped++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range failedPackages {
+		if !failedTests[pkg] {
+			results.Failed++
+			results.Failures = append(results.Failures, pkg+" [build failed]")
+		}
+	}
+	return results, nil
+}
+
+// JUnit XML の testsuites / testsuite
+type junitSuite struct {
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+// JUnit XML の testcase
+type junitCase struct {
+	Name      string    `xml:"name,attr"`
+	Classname string    `xml:"classname,attr"`
+	Time      string    `xml:"time,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+	Skipped   *struct{} `xml:"skipped"`
+}
+
+// JUnit XML を解析（ルートは testsuites / testsuite のどちらでもよい）
+func parseJUnit(content []byte) (*TestResults, error) {
+	var root junitSuite
+	if err := xml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+
+	results := &TestResults{}
+	var walk func(suite junitSuite)
+	walk = func(suite junitSuite) {
+		for _, c := range suite.Cases {
+			if seconds, err := strconv.ParseFloat(c.Time, 64); err == nil {
+				results.Duration += secondsDuration(seconds)
+			}
+			switch {
+			case c.Failure != nil || c.Error != nil:
+				results.Failed++
+				name := c.Name
+				if c.Classname != "" {
+					name = c.Classname + "." + c.Name
+				}
+				results.Failures = append(results.Failures, name)
+			case c.Skipped != nil:
+				results.Skipped++
+			default:
+				results.Passed++
+			}
+		}
+		for _, s := range suite.Suites {
+			walk(s)
+		}
+	}
+	walk(root)
+	return results, nil
+}
+
+var (
+	// TAP の計画行（例: "1..4"）
+	tapPlan = regexp.MustCompile(`^1\.\.\d+`)
+	// TAP の結果行（例: "not ok 2 - login # TODO"）
+	tapResult = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(\w+).*)?$`)
+)
+
+// TAP を解析（字下げされたサブテストの行は数えない）
+// TODO 指定の失敗は失敗として数えない
+func parseTAP(content []byte) (*TestResults, error) {
+	results := &TestResults{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "Bail out!") {
+			results.Failed++
+			results.Failures = append(results.Failures, strings.TrimSpace(line))
+			continue
+		}
+		m := tapResult.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		directive := strings.ToUpper(m[4])
+		switch {
+		case strings.HasPrefix(directive, "SKIP"):
+			results.Skipped++
+		case m[1] == "":
+			results.Passed++
+		case strings.HasPrefix(directive, "TODO"):
+			results.Skipped++
+		default:
+			results.Failed++
+			name := m[3]
+			if name == "" {
+				name = "test " + m[2]
+			}
+			results.Failures = append(results.Failures, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// 秒数（小数）を時間に変換
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond)
+}
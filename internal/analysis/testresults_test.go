@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTestResults_GoJSON(t *testing.T) {
+	content := `{"Action":"run","Package":"example.com/app/auth","Test":"TestLogin"}
+{"Action":"output","Package":"example.com/app/auth","Test":"TestLogin","Output":"=== RUN   TestLogin\n"}
+{"Action":"pass","Package":"example.com/app/auth","Test":"TestLogin","Elapsed":0.01}
+{"Action":"fail","Package":"example.com/app/auth","Test":"TestRefresh","Elapsed":0.02}
+{"Action":"skip","Package":"example.com/app/auth","Test":"TestOAuth","Elapsed":0}
+{"Action":"fail","Package":"example.com/app/auth","Elapsed":0.5}
+# example.com/app/db
+{"Action":"fail","Package":"example.com/app/db","Elapsed":0.25}
+`
+	results, err := ParseTestResults("test-results/go.json", []byte(content))
+	if err != nil {
+		t.Fatalf("ParseTestResults failed: %v", err)
+	}
+	want := &TestResults{
+		Format:   TestFormatGoJSON,
+		Passed:   1,
+		Failed:   2,
+		Skipped:  1,
+		Failures: []string{"example.com/app/auth.TestRefresh", "example.com/app/db [build failed]"},
+		Duration: 750 * time.Millisecond,
+		Sources:  []string{"test-results/go.json"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("unexpected results:\n got: %+v\nwant: %+v", results, want)
+	}
+}
+
+func TestParseTestResults_JUnit(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="auth">
+    <testcase classname="auth.LoginTest" name="returns jwt" time="0.5"/>
+    <testcase classname="auth.LoginTest" name="rejects password" time="1.25">
+      <failure message="expected 401">AssertionError</failure>
+    </testcase>
+    <testcase classname="auth.LoginTest" name="oauth"><skipped/></testcase>
+  </testsuite>
+  <testsuite name="db">
+    <testcase name="connects" time="0.25"><error message="timeout"/></testcase>
+  </testsuite>
+</testsuites>
+`
+	results, err := ParseTestResults("junit.xml", []byte(content))
+	if err != nil {
+		t.Fatalf("ParseTestResults failed: %v", err)
+	}
+	if results.Passed != 1 || results.Failed != 2 || results.Skipped != 1 || results.Duration != 2*time.Second {
+		t.Errorf("unexpected counts: %+v", results)
+	}
+	if !reflect.DeepEqual(results.Failures, []string{"auth.LoginTest.rejects password", "connects"}) {
+		t.Errorf("unexpected failures: %v", results.Failures)
+	}
+}
+
+func TestParseTestResults_TAP(t *testing.T) {
+	content := `TAP version 13
+1..5
+ok 1 - returns jwt
+not ok 2 - rejects password
+  ---
+  message: expected 401
+  ...
+ok 3 - oauth # SKIP not configured
+not ok 4 - refresh # TODO not implemented
+    ok 1 - nested subtest
+not ok 5
+`
+	// 拡張子がなくても内容から判定する
+	results, err := ParseTestResults("results.out", []byte(content))
+	if err != nil {
+		t.Fatalf("ParseTestResults failed: %v", err)
+	}
+	if results.Format != TestFormatTAP || results.Passed != 1 || results.Failed != 2 || results.Skipped != 2 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if !reflect.DeepEqual(results.Failures, []string{"rejects password", "test 5"}) {
+		t.Errorf("unexpected failures: %v", results.Failures)
+	}
+
+	if _, err := ParseTestResults("notes.txt", []byte("all tests pass")); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestTestResults_Merge(t *testing.T) {
+	results := TestResults{Format: TestFormatGoJSON, Passed: 3, Duration: time.Second, Sources: []string{"go.json"}}
+	results.Merge(TestResults{Format: TestFormatJUnit, Passed: 1, Failed: 1, Failures: []string{"e2e.login"}, Duration: 2 * time.Second, Sources: []string{"e2e.xml"}})
+	results.Merge(TestResults{Format: TestFormatGoJSON, Skipped: 1, Sources: []string{"go2.json"}})
+
+	if results.Format != "go-json,junit" || results.Total() != 5 || results.Skipped != 1 || len(results.Sources) != 3 {
+		t.Errorf("unexpected merged results: %+v", results)
+	}
+	if got := results.String(); got != "4 passed, 1 failed, 1 skipped (3s)" {
+		t.Errorf("unexpected string: %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package communication
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 完了条件を満たしていない指令は completed にできない
+var ErrCriteriaUnmet = errors.New("acceptance criteria not met")
+
+// 完了条件の検証状態
+type CriterionStatus string
+
+const (
+	// 未検証
+	CriterionStatusUnverified CriterionStatus = "unverified"
+	// 検証に成功した
+	CriterionStatusPassed CriterionStatus = "passed"
+	// 検証に失敗した
+	CriterionStatusFailed CriterionStatus = "failed"
+	// 人間が検証を免除した
+	CriterionStatusWaived CriterionStatus = "waived"
+)
+
+// 指令の完了条件
+// 文字列だけの完了条件（従来の形式）も読み書きできる
+type AcceptanceCriterion struct {
+	Description string `yaml:"description"`
+	// 検証コマンド（sh -c、終了コード 0 で成功）
+	Verify string `yaml:"verify,omitempty"`
+	// 検証するテスト名（acceptance.test_command で実行）
+	Test string `yaml:"test,omitempty"`
+	// 検証状態（省略時は unverified）
+	Status CriterionStatus `yaml:"status,omitempty"`
+	// 検証結果の根拠（実行結果と出力の末尾、免除の理由など）
+	Evidence   string    `yaml:"evidence,omitempty"`
+	VerifiedAt time.Time `yaml:"verified_at,omitempty"`
+	// 免除した人
+	WaivedBy string `yaml:"waived_by,omitempty"`
+}
+
+// 検証コマンドかテスト名を持つか
+func (c AcceptanceCriterion) Executable() bool {
+	return c.Verify != "" || c.Test != ""
+}
+
+// 完了条件を満たしたか（passed または waived）
+func (c AcceptanceCriterion) Resolved() bool {
+	return c.Status == CriterionStatusPassed || c.Status == CriterionStatusWaived
+}
+
+// 現在の検証状態（省略時は unverified）
+func (c AcceptanceCriterion) CurrentStatus() CriterionStatus {
+	if c.Status == "" {
+		return CriterionStatusUnverified
+	}
+	return c.Status
+}
+
+// 文字列または mapping として読み込む
+func (c *AcceptanceCriterion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = AcceptanceCriterion{Description: node.Value}
+		return nil
+	}
+
+	type plain AcceptanceCriterion
+	var p plain
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	*c = AcceptanceCriterion(p)
+	return nil
+}
+
+// 説明だけの完了条件は文字列として書き込む
+func (c AcceptanceCriterion) MarshalYAML() (interface{}, error) {
+	if c == (AcceptanceCriterion{Description: c.Description}) {
+		return c.Description, nil
+	}
+
+	type plain AcceptanceCriterion
+	return plain(c), nil
+}
+
+// 満たしていない完了条件の番号（1 始まり）
+func (cmd Command) UnresolvedCriteria() []int {
+	var unresolved []int
+	for i, c := range cmd.AcceptanceCriteria {
+		if !c.Resolved() {
+			unresolved = append(unresolved, i+1)
+		}
+	}
+	return unresolved
+}
+
+// completed にできるか（すべての完了条件が passed または waived）
+func (cmd Command) CheckCriteria() error {
+	if unresolved := cmd.UnresolvedCriteria(); len(unresolved) > 0 {
+		return fmt.Errorf("%w: %s has unresolved criteria %v", ErrCriteriaUnmet, cmd.ID, unresolved)
+	}
+	return nil
+}
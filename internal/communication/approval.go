@@ -0,0 +1,208 @@
+package communication
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 承認の状態
+type ApprovalStatus string
+
+const (
+	// 判断待ち
+	ApprovalStatusPending ApprovalStatus = "pending"
+	// 承認された
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	// 却下された
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+)
+
+// 承認の対象
+type ApprovalKind string
+
+const (
+	// タスクの割り当て
+	ApprovalKindTask ApprovalKind = "task"
+	// タスクのブランチのマージ
+	ApprovalKindMerge ApprovalKind = "merge"
+)
+
+// 人間の承認が必要な変更
+type ApprovalRequest struct {
+	ID        string       `yaml:"id"`
+	Kind      ApprovalKind `yaml:"kind"`
+	TaskID    string       `yaml:"task_id"`
+	CommandID string       `yaml:"command_id,omitempty"`
+	// マージの承認ではブランチの先頭コミット（承認はこのコミットに対してのみ有効）
+	Commit string `yaml:"commit,omitempty"`
+	// 一致した判定条件（intervention.require_approval の名前）
+	Policies []string `yaml:"policies"`
+	// 一致した語・ファイル
+	Matches     []string       `yaml:"matches,omitempty"`
+	Status      ApprovalStatus `yaml:"status"`
+	RequestedAt time.Time      `yaml:"requested_at"`
+	// 判断した人と理由
+	DecidedBy      string    `yaml:"decided_by,omitempty"`
+	DecidedAt      time.Time `yaml:"decided_at,omitempty"`
+	DecisionReason string    `yaml:"decision_reason,omitempty"`
+}
+
+// 承認ファイルの内容
+type ApprovalLog struct {
+	Approvals []ApprovalRequest `yaml:"approvals"`
+}
+
+// 承認の記録を管理する
+// 保存先: agents/queue/approvals.yaml
+type ApprovalManager struct {
+	path string
+	mu   sync.Mutex
+}
+
+// 新しい承認マネージャーを作成
+func NewApprovalManager(queueDir string) *ApprovalManager {
+	return &ApprovalManager{
+		path: filepath.Join(queueDir, "approvals.yaml"),
+	}
+}
+
+// 承認を依頼する
+// 同じタスク・同じ対象・同じコミットの判断待ちが既にあれば新規作成しない（created = false）
+func (m *ApprovalManager) Request(req ApprovalRequest) (*ApprovalRequest, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alog, err := m.read()
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, existing := range alog.Approvals {
+		if existing.TaskID == req.TaskID && existing.Kind == req.Kind && existing.Commit == req.Commit && existing.Status == ApprovalStatusPending {
+			found := existing
+			return &found, false, nil
+		}
+	}
+
+	req.ID = fmt.Sprintf("apr_%03d", len(alog.Approvals)+1)
+	req.Status = ApprovalStatusPending
+	if req.RequestedAt.IsZero() {
+		req.RequestedAt = time.Now()
+	}
+	alog.Approvals = append(alog.Approvals, req)
+
+	if err := m.write(alog); err != nil {
+		return nil, false, err
+	}
+	return &req, true, nil
+}
+
+// 判断待ちの承認を承認・却下する
+func (m *ApprovalManager) Decide(id string, status ApprovalStatus, by, reason string) (*ApprovalRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alog, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range alog.Approvals {
+		req := &alog.Approvals[i]
+		if req.ID != id {
+			continue
+		}
+		if req.Status != ApprovalStatusPending {
+			return nil, fmt.Errorf("approval %s is already %s", id, req.Status)
+		}
+		req.Status = status
+		req.DecidedBy = by
+		req.DecidedAt = time.Now()
+		req.DecisionReason = reason
+
+		decided := *req
+		return &decided, m.write(alog)
+	}
+	return nil, fmt.Errorf("approval not found: %s", id)
+}
+
+// 承認を取得
+func (m *ApprovalManager) Get(id string) (*ApprovalRequest, error) {
+	approvals, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range approvals {
+		if req.ID == id {
+			return &req, nil
+		}
+	}
+	return nil, fmt.Errorf("approval not found: %s", id)
+}
+
+// すべての承認を取得（依頼順）
+func (m *ApprovalManager) List() ([]ApprovalRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alog, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+	return alog.Approvals, nil
+}
+
+// 判断待ちの承認を取得
+func (m *ApprovalManager) Pending() ([]ApprovalRequest, error) {
+	approvals, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []ApprovalRequest{}
+	for _, req := range approvals {
+		if req.Status == ApprovalStatusPending {
+			pending = append(pending, req)
+		}
+	}
+	return pending, nil
+}
+
+// 承認ファイルを読み込む（存在しない場合は空）
+func (m *ApprovalManager) read() (*ApprovalLog, error) {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ApprovalLog{}, nil
+		}
+		return nil, fmt.Errorf("failed to read approvals: %w", err)
+	}
+
+	var alog ApprovalLog
+	if err := yaml.Unmarshal(data, &alog); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal approvals: %w", err)
+	}
+	return &alog, nil
+}
+
+// 承認ファイルに書き込む
+func (m *ApprovalManager) write(alog *ApprovalLog) error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(alog)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approvals: %w", err)
+	}
+
+	if err := os.WriteFile(m.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write approvals: %w", err)
+	}
+	return nil
+}
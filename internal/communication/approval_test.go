@@ -0,0 +1,63 @@
+package communication
+
+import "testing"
+
+func TestApprovalManager_Request(t *testing.T) {
+	manager := NewApprovalManager(t.TempDir())
+
+	req, created, err := manager.Request(ApprovalRequest{Kind: ApprovalKindTask, TaskID: "task_001", Policies: []string{"security_changes"}})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !created || req.ID != "apr_001" || req.Status != ApprovalStatusPending {
+		t.Errorf("unexpected request: created=%v %+v", created, req)
+	}
+
+	// 同じタスク・同じ対象の判断待ちは重複して作成しない
+	again, created, err := manager.Request(ApprovalRequest{Kind: ApprovalKindTask, TaskID: "task_001"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if created || again.ID != req.ID {
+		t.Errorf("expected existing request %s, got created=%v %s", req.ID, created, again.ID)
+	}
+
+	// 対象が異なれば別の承認
+	merge, created, err := manager.Request(ApprovalRequest{Kind: ApprovalKindMerge, TaskID: "task_001"})
+	if err != nil || !created || merge.ID != "apr_002" {
+		t.Errorf("expected new merge approval, got created=%v %+v (%v)", created, merge, err)
+	}
+}
+
+func TestApprovalManager_Decide(t *testing.T) {
+	manager := NewApprovalManager(t.TempDir())
+
+	req, _, err := manager.Request(ApprovalRequest{Kind: ApprovalKindTask, TaskID: "task_001"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	decided, err := manager.Decide(req.ID, ApprovalStatusRejected, "alice", "本番 DB のため不可")
+	if err != nil {
+		t.Fatalf("Decide failed: %v", err)
+	}
+	if decided.Status != ApprovalStatusRejected || decided.DecidedBy != "alice" || decided.DecisionReason == "" || decided.DecidedAt.IsZero() {
+		t.Errorf("decision not recorded: %+v", decided)
+	}
+
+	// 判断済みの承認は変更できない
+	if _, err := manager.Decide(req.ID, ApprovalStatusApproved, "bob", ""); err == nil {
+		t.Error("expected error for decided approval")
+	}
+	if _, err := manager.Decide("apr_999", ApprovalStatusApproved, "bob", ""); err == nil {
+		t.Error("expected error for unknown approval")
+	}
+
+	pending, err := manager.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected no pending approvals, got %+v", pending)
+	}
+}
@@ -0,0 +1,67 @@
+package communication
+
+import (
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+)
+
+// 並列試行の 1 つの結果（勝者の選択に使う）
+type AttemptResult struct {
+	// 試行のタスク
+	Task       string     `yaml:"task"`
+	Specialist string     `yaml:"specialist,omitempty"`
+	Branch     string     `yaml:"branch,omitempty"`
+	Status     TaskStatus `yaml:"status"`
+	Summary    string     `yaml:"summary,omitempty"`
+	// 品質ゲートの結果（FormatGateResult の表示）
+	Gates []string `yaml:"gates,omitempty"`
+	// 品質ゲートがすべて成功したか（ゲートを使わない場合は true）
+	GatesPassed bool                     `yaml:"gates_passed"`
+	Tests       *analysis.TestResults    `yaml:"tests,omitempty"`
+	Coverage    *analysis.CoverageReport `yaml:"coverage,omitempty"`
+	// 評価のスコアの合計（評価がなければ省略）
+	Score *int `yaml:"score,omitempty"`
+	// 変更したファイルの数
+	ChangedFiles int `yaml:"changed_files,omitempty"`
+	// 割り当てから完了報告までの時間
+	Duration   time.Duration `yaml:"duration,omitempty"`
+	ReportedAt time.Time     `yaml:"reported_at,omitempty"`
+	// 選ばれなかった試行のブランチの退避先
+	ArchivedBranch string `yaml:"archived_branch,omitempty"`
+}
+
+// 並列試行の状態（attempts を指定したタスクに bastion が記録）
+type AttemptSelection struct {
+	// 試行のタスク
+	Tasks   []string        `yaml:"tasks"`
+	Results []AttemptResult `yaml:"results,omitempty"`
+	// 選ばれた試行のタスク
+	Winner string `yaml:"winner,omitempty"`
+	// 比較した基準と選んだ理由
+	Criteria  []string  `yaml:"criteria,omitempty"`
+	Reason    string    `yaml:"reason,omitempty"`
+	StartedAt time.Time `yaml:"started_at"`
+	// すべての試行が終了した時刻（評価を待つ起点）
+	FinishedAt time.Time `yaml:"finished_at,omitempty"`
+	SelectedAt time.Time `yaml:"selected_at,omitempty"`
+}
+
+// 試行の結果を取得
+func (s *AttemptSelection) Result(taskID string) *AttemptResult {
+	for i := range s.Results {
+		if s.Results[i].Task == taskID {
+			return &s.Results[i]
+		}
+	}
+	return nil
+}
+
+// 試行の結果を記録（同じ試行の結果は置き換える）
+func (s *AttemptSelection) SetResult(result AttemptResult) {
+	if r := s.Result(result.Task); r != nil {
+		*r = result
+		return
+	}
+	s.Results = append(s.Results, result)
+}
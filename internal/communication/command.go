@@ -1,6 +1,10 @@
 package communication
 
-import "time"
+import (
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
 
 // コマンド状態
 type CommandStatus string
@@ -18,14 +22,20 @@ const (
 
 // Envoy から Marshall への指令
 type Command struct {
-	ID                 string        `yaml:"id"`
-	Timestamp          time.Time     `yaml:"timestamp"`
-	Purpose            string        `yaml:"purpose"`
-	AcceptanceCriteria []string      `yaml:"acceptance_criteria"`
-	Command            string        `yaml:"command"`
-	Project            string        `yaml:"project"`
-	Priority           string        `yaml:"priority"`
-	Status             CommandStatus `yaml:"status"`
+	ID                 string                `yaml:"id"`
+	Timestamp          time.Time             `yaml:"timestamp"`
+	Purpose            string                `yaml:"purpose"`
+	AcceptanceCriteria []AcceptanceCriterion `yaml:"acceptance_criteria"`
+	Command            string                `yaml:"command"`
+	Project            string                `yaml:"project"`
+	Priority           string                `yaml:"priority"`
+	Status             CommandStatus         `yaml:"status"`
+	// 失敗したタスクの対応方針の上書き（省略時は設定の fallback）
+	Fallback *config.FallbackOverride `yaml:"fallback,omitempty"`
+	// 統合ブランチへのマージの記録（マージした順、bastion merge が記録）
+	Merges []CommandMerge `yaml:"merges,omitempty"`
+	// ロールバック用ブランチの状態（bastion command rollback が記録）
+	Rollback *Rollback `yaml:"rollback,omitempty"`
 }
 
 // 指令キュー
@@ -82,6 +82,11 @@ func (m *CommandQueueManager) Read() ([]Command, error) {
 			continue
 		}
 
+		// id を持たないファイルは Specialist 向けタスク
+		if cmd.ID == "" {
+			continue
+		}
+
 		commands = append(commands, *cmd)
 	}
 
@@ -118,7 +123,21 @@ func (m *CommandQueueManager) readTaskFile(path string) (*Command, error) {
 }
 
 // 指令の状態を更新
+// 完了条件をすべて満たしていない指令は completed にできない（ErrCriteriaUnmet）
 func (m *CommandQueueManager) UpdateStatus(id string, status CommandStatus) error {
+	return m.Update(id, func(cmd *Command) error {
+		if status == CommandStatusCompleted {
+			if err := cmd.CheckCriteria(); err != nil {
+				return err
+			}
+		}
+		cmd.Status = status
+		return nil
+	})
+}
+
+// 指令を読み込んで更新する（fn がエラーを返した場合は書き込まない）
+func (m *CommandQueueManager) Update(id string, fn func(*Command) error) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -130,8 +149,9 @@ func (m *CommandQueueManager) UpdateStatus(id string, status CommandStatus) erro
 		return fmt.Errorf("failed to read task: %w", err)
 	}
 
-	// 状態を更新
-	cmd.Status = status
+	if err := fn(cmd); err != nil {
+		return err
+	}
 
 	// タスクを YAML にシリアライズ
 	data, err := yaml.Marshal(cmd)
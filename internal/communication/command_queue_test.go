@@ -17,9 +17,9 @@ func TestCommandQueueManager_Write(t *testing.T) {
 		ID:        "cmd_001",
 		Timestamp: time.Now(),
 		Purpose:   "JWT認証を実装",
-		AcceptanceCriteria: []string{
-			"POST /auth/login が JWT を返す",
-			"テストがパスする",
+		AcceptanceCriteria: []AcceptanceCriterion{
+			{Description: "POST /auth/login が JWT を返す"},
+			{Description: "テストがパスする"},
 		},
 		Command:  "JWT認証を実装する",
 		Project:  "api-server",
@@ -59,6 +59,22 @@ func (m *InboxManager) Write(target, message string, msgType MessageType, from s
 	return nil
 }
 
+// 空の inbox を作成（既に存在する場合は何もしない）
+func (m *InboxManager) Create(target string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inboxPath := filepath.Join(m.queueDir, "inbox", target+".yaml")
+	if _, err := os.Stat(inboxPath); err == nil {
+		return nil
+	}
+
+	if err := m.writeInboxFile(inboxPath, &Inbox{Messages: []Message{}}); err != nil {
+		return fmt.Errorf("failed to create inbox: %w", err)
+	}
+	return nil
+}
+
 // inbox からメッセージを読み込む
 func (m *InboxManager) Read(target string) ([]Message, error) {
 	m.mu.Lock()
@@ -111,6 +127,39 @@ func (m *InboxManager) MarkAsProcessed(target, messageID string) error {
 	return nil
 }
 
+// メッセージの本文を書き換える（秘密情報を伏せるなど）
+// 返り値: 書き換えたメッセージの数（0 ならファイルを書き込まない）
+func (m *InboxManager) RewriteMessages(target string, rewrite func(string) string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inboxPath := filepath.Join(m.queueDir, "inbox", target+".yaml")
+
+	inbox, err := m.readInboxFile(inboxPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read inbox: %w", err)
+	}
+
+	changed := 0
+	for i := range inbox.Messages {
+		if message := rewrite(inbox.Messages[i].Message); message != inbox.Messages[i].Message {
+			inbox.Messages[i].Message = message
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+
+	if err := m.writeInboxFile(inboxPath, inbox); err != nil {
+		return 0, fmt.Errorf("failed to write inbox: %w", err)
+	}
+	return changed, nil
+}
+
 // 未処理のメッセージを取得
 func (m *InboxManager) GetPendingMessages(target string) ([]Message, error) {
 	messages, err := m.Read(target)
@@ -3,6 +3,7 @@ package communication
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -183,3 +184,65 @@ func TestInboxManager_ConcurrentWrites(t *testing.T) {
 		t.Fatalf("expected 10 messages, got %d", len(messages))
 	}
 }
+
+func TestInboxManager_Create(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewInboxManager(tmpDir)
+
+	if err := manager.Create("specialist_5"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	inboxPath := filepath.Join(tmpDir, "inbox", "specialist_5.yaml")
+	if _, err := os.Stat(inboxPath); err != nil {
+		t.Fatalf("inbox file was not created: %v", err)
+	}
+
+	// 既存の inbox は上書きしない
+	if err := manager.Write("specialist_5", "タスク1", MessageTypeTaskAssigned, "marshall"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := manager.Create("specialist_5"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	messages, err := manager.Read("specialist_5")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Errorf("expected existing message to be kept, got %d messages", len(messages))
+	}
+}
+
+func TestInboxManager_RewriteMessages(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewInboxManager(tmpDir)
+
+	for _, message := range []string{"キーは secret-123 です", "問題ありません"} {
+		if err := manager.Write("marshall", message, MessageTypeReportReceived, "specialist_1"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	mask := func(message string) string {
+		return strings.ReplaceAll(message, "secret-123", "[REDACTED]")
+	}
+	changed, err := manager.RewriteMessages("marshall", mask)
+	if err != nil {
+		t.Fatalf("RewriteMessages failed: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("expected 1 rewritten message, got %d", changed)
+	}
+
+	messages, _ := manager.Read("marshall")
+	if messages[0].Message != "キーは [REDACTED] です" || messages[1].Message != "問題ありません" {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+
+	// 存在しない inbox は何もしない
+	if changed, err := manager.RewriteMessages("envoy", mask); err != nil || changed != 0 {
+		t.Errorf("expected no change for missing inbox: changed=%d err=%v", changed, err)
+	}
+}
@@ -12,6 +12,20 @@ const (
 	MessageTypeReportReceived MessageType = "report_received"
 	// 起床通知
 	MessageTypeWakeUp MessageType = "wake_up"
+	// エージェントが権限確認で停止している
+	MessageTypePermissionRequested MessageType = "permission_requested"
+	// worktree の初期化に失敗した
+	MessageTypeBootstrapFailed MessageType = "bootstrap_failed"
+	// 人間の承認が必要な変更がある
+	MessageTypeApprovalRequested MessageType = "approval_requested"
+	// 秘密情報を検出した
+	MessageTypeSecretsDetected MessageType = "secrets_detected"
+	// 品質ゲートが失敗した
+	MessageTypeGateFailed MessageType = "gate_failed"
+	// カバレッジが基準を下回った
+	MessageTypeCoverageDropped MessageType = "coverage_dropped"
+	// レビューで修正を依頼された
+	MessageTypeChangesRequested MessageType = "changes_requested"
 )
 
 // メッセージ処理状態
@@ -0,0 +1,177 @@
+package communication
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 権限確認の状態
+type PermissionStatus string
+
+const (
+	// 応答待ち
+	PermissionStatusPending PermissionStatus = "pending"
+	// bastion approve で許可した
+	PermissionStatusApproved PermissionStatus = "approved"
+	// bastion approve --deny で拒否した
+	PermissionStatusDenied PermissionStatus = "denied"
+	// ペイン上で直接応答された（プロンプトが消えた）
+	PermissionStatusResolved PermissionStatus = "resolved"
+)
+
+// エージェントのペインで検出した権限確認プロンプト
+type PermissionRequest struct {
+	ID         string           `yaml:"id"`
+	Agent      string           `yaml:"agent"`
+	Action     string           `yaml:"action"`
+	Status     PermissionStatus `yaml:"status"`
+	DetectedAt time.Time        `yaml:"detected_at"`
+	ResolvedAt time.Time        `yaml:"resolved_at,omitempty"`
+	ResolvedBy string           `yaml:"resolved_by,omitempty"`
+}
+
+// 権限確認ファイルの内容
+type PermissionLog struct {
+	Requests []PermissionRequest `yaml:"requests"`
+}
+
+// 権限確認の記録を管理する
+// 保存先: agents/queue/permissions.yaml
+type PermissionManager struct {
+	path string
+	mu   sync.Mutex
+}
+
+// 新しい権限確認マネージャーを作成
+func NewPermissionManager(queueDir string) *PermissionManager {
+	return &PermissionManager{
+		path: filepath.Join(queueDir, "permissions.yaml"),
+	}
+}
+
+// 権限確認を記録する
+// 同じエージェント・同じ操作の応答待ちが既にあれば新規作成しない（created = false）
+// 別の操作の応答待ちが残っている場合は解決済みにしてから新規作成する
+func (m *PermissionManager) Record(agent, action string) (*PermissionRequest, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plog, err := m.read()
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	for i := range plog.Requests {
+		req := &plog.Requests[i]
+		if req.Agent != agent || req.Status != PermissionStatusPending {
+			continue
+		}
+		if req.Action == action {
+			found := *req
+			return &found, false, nil
+		}
+		req.Status = PermissionStatusResolved
+		req.ResolvedAt = now
+	}
+
+	req := PermissionRequest{
+		ID:         fmt.Sprintf("perm_%d", now.UnixNano()),
+		Agent:      agent,
+		Action:     action,
+		Status:     PermissionStatusPending,
+		DetectedAt: now,
+	}
+	plog.Requests = append(plog.Requests, req)
+
+	if err := m.write(plog); err != nil {
+		return nil, false, err
+	}
+	return &req, true, nil
+}
+
+// エージェントの応答待ちを解決済みにする
+// 応答待ちがなかった場合は false を返す
+func (m *PermissionManager) Resolve(agent string, status PermissionStatus, by string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plog, err := m.read()
+	if err != nil {
+		return false, err
+	}
+
+	resolved := false
+	for i := range plog.Requests {
+		req := &plog.Requests[i]
+		if req.Agent == agent && req.Status == PermissionStatusPending {
+			req.Status = status
+			req.ResolvedAt = time.Now()
+			req.ResolvedBy = by
+			resolved = true
+		}
+	}
+
+	if !resolved {
+		return false, nil
+	}
+	return true, m.write(plog)
+}
+
+// 応答待ちの権限確認を取得
+func (m *PermissionManager) Pending() ([]PermissionRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plog, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []PermissionRequest{}
+	for _, req := range plog.Requests {
+		if req.Status == PermissionStatusPending {
+			pending = append(pending, req)
+		}
+	}
+	return pending, nil
+}
+
+// 権限確認ファイルを読み込む（存在しない場合は空）
+func (m *PermissionManager) read() (*PermissionLog, error) {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &PermissionLog{}, nil
+		}
+		return nil, fmt.Errorf("failed to read permissions: %w", err)
+	}
+
+	var plog PermissionLog
+	if err := yaml.Unmarshal(data, &plog); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal permissions: %w", err)
+	}
+	return &plog, nil
+}
+
+// 権限確認ファイルに書き込む
+func (m *PermissionManager) write(plog *PermissionLog) error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(plog)
+	if err != nil {
+		return fmt.Errorf("failed to marshal permissions: %w", err)
+	}
+
+	if err := os.WriteFile(m.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write permissions: %w", err)
+	}
+	return nil
+}
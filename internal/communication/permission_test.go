@@ -0,0 +1,101 @@
+package communication
+
+import "testing"
+
+func TestPermissionManager_Record(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewPermissionManager(tmpDir)
+
+	req, created, err := manager.Record("marshall", "Edit file: Do you want to make this edit to cmd_001.yaml?")
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if !created {
+		t.Error("expected new request to be created")
+	}
+	if req.Status != PermissionStatusPending {
+		t.Errorf("expected status pending, got %s", req.Status)
+	}
+
+	// 同じ操作は重複して記録しない
+	again, created, err := manager.Record("marshall", "Edit file: Do you want to make this edit to cmd_001.yaml?")
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if created {
+		t.Error("expected duplicate request not to be created")
+	}
+	if again.ID != req.ID {
+		t.Errorf("expected same request ID %s, got %s", req.ID, again.ID)
+	}
+
+	pending, err := manager.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+}
+
+func TestPermissionManager_RecordDifferentAction(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewPermissionManager(tmpDir)
+
+	if _, _, err := manager.Record("marshall", "Create file: dashboard.md"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// 別の操作を要求された場合は前の要求を解決済みにする
+	if _, created, err := manager.Record("marshall", "Edit file: cmd_001.yaml"); err != nil || !created {
+		t.Fatalf("Record failed: created=%v err=%v", created, err)
+	}
+
+	pending, err := manager.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+	if pending[0].Action != "Edit file: cmd_001.yaml" {
+		t.Errorf("expected latest action to be pending, got %s", pending[0].Action)
+	}
+}
+
+func TestPermissionManager_Resolve(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewPermissionManager(tmpDir)
+
+	// 応答待ちがない場合は false
+	resolved, err := manager.Resolve("marshall", PermissionStatusApproved, "bastion approve")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if resolved {
+		t.Error("expected nothing to resolve")
+	}
+
+	if _, _, err := manager.Record("marshall", "Create file: dashboard.md"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, _, err := manager.Record("specialist_1", "Bash command: go test ./..."); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	resolved, err = manager.Resolve("marshall", PermissionStatusApproved, "bastion approve")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if !resolved {
+		t.Error("expected request to be resolved")
+	}
+
+	pending, err := manager.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Agent != "specialist_1" {
+		t.Errorf("expected only specialist_1 to be pending, got %+v", pending)
+	}
+}
@@ -0,0 +1,44 @@
+package communication
+
+import (
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+)
+
+// Specialist から Marshall への完了報告
+// queue/reports/<specialist_id>_report.yaml として保存される
+type Report struct {
+	TaskID       string     `yaml:"task_id"`
+	SpecialistID string     `yaml:"specialist_id"`
+	Status       TaskStatus `yaml:"status"`
+	Deliverables []string   `yaml:"deliverables"`
+	Summary      string     `yaml:"summary"`
+	Issues       []string   `yaml:"issues,omitempty"`
+	Timestamp    time.Time  `yaml:"timestamp"`
+	// タスクのブランチで変更された依存関係（bastion が記録）
+	Dependencies []analysis.DependencyChange `yaml:"dependencies,omitempty"`
+	// 品質ゲートの実行結果（bastion が記録）
+	Gates []GateResult `yaml:"gates,omitempty"`
+	// 品質ゲートを実行したレポートのタイムスタンプ（bastion が記録）
+	GatedAt time.Time `yaml:"gated_at,omitempty"`
+	// worktree のテスト結果（bastion が記録）
+	Tests *analysis.TestResults `yaml:"tests,omitempty"`
+	// ブランチの分岐点と先頭のカバレッジの比較（bastion が記録）
+	Coverage *analysis.CoverageReport `yaml:"coverage,omitempty"`
+	// レビュータスクの判定（レビュー担当が記入）
+	Review *ReviewResult `yaml:"review,omitempty"`
+}
+
+// 品質ゲートの実行結果
+type GateResult struct {
+	Name     string `yaml:"name"`
+	Command  string `yaml:"command"`
+	Passed   bool   `yaml:"passed"`
+	ExitCode int    `yaml:"exit_code"`
+	// タイムアウトで中断した
+	TimedOut bool          `yaml:"timed_out,omitempty"`
+	Duration time.Duration `yaml:"duration"`
+	// 出力の末尾（gates.output_limit バイトまで）
+	Output string `yaml:"output,omitempty"`
+}
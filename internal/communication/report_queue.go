@@ -0,0 +1,116 @@
+package communication
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 完了報告の読み書きを管理する
+type ReportManager struct {
+	reportsDir string
+}
+
+// 新しいレポートマネージャーを作成
+func NewReportManager(queueDir string) *ReportManager {
+	return &ReportManager{
+		reportsDir: filepath.Join(queueDir, "reports"),
+	}
+}
+
+// レポートディレクトリ
+func (m *ReportManager) Dir() string {
+	return m.reportsDir
+}
+
+// レポートを書き込む（reports/<specialist_id>_report.yaml）
+func (m *ReportManager) Write(report *Report) error {
+	if report.TaskID == "" || report.SpecialistID == "" {
+		return fmt.Errorf("task_id and specialist_id are required")
+	}
+
+	if err := os.MkdirAll(m.reportsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if err := os.WriteFile(m.Path(report.SpecialistID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// Specialist のレポートファイルのパス
+func (m *ReportManager) Path(specialistID string) string {
+	return filepath.Join(m.reportsDir, specialistID+"_report.yaml")
+}
+
+// レポートファイルを読み込む
+func (m *ReportManager) ReadFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReport(data)
+}
+
+// レポートの YAML を解析する
+func ParseReport(data []byte) (*Report, error) {
+	var report Report
+	if err := yaml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal report: %w", err)
+	}
+	return &report, nil
+}
+
+// タスクの最新のレポートを読み込む
+func (m *ReportManager) ReadByTaskID(taskID string) (*Report, error) {
+	reports, err := m.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(reports) - 1; i >= 0; i-- {
+		if reports[i].TaskID == taskID {
+			return &reports[i], nil
+		}
+	}
+	return nil, fmt.Errorf("report not found: %s", taskID)
+}
+
+// すべてのレポートを読み込む（タイムスタンプの古い順）
+func (m *ReportManager) Read() ([]Report, error) {
+	entries, err := os.ReadDir(m.reportsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Report{}, nil
+		}
+		return nil, fmt.Errorf("failed to read reports directory: %w", err)
+	}
+
+	reports := []Report{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+
+		report, err := m.ReadFile(filepath.Join(m.reportsDir, entry.Name()))
+		if err != nil || report.TaskID == "" {
+			// 壊れたファイルは読み飛ばす
+			continue
+		}
+		reports = append(reports, *report)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Timestamp.Before(reports[j].Timestamp)
+	})
+	return reports, nil
+}
@@ -0,0 +1,46 @@
+package communication
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportManager_WriteAndRead(t *testing.T) {
+	m := NewReportManager(t.TempDir())
+	now := time.Now()
+
+	if err := m.Write(&Report{TaskID: "task_001", SpecialistID: "specialist_1", Status: TaskStatusCompleted, Timestamp: now}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := m.Write(&Report{TaskID: "task_002", SpecialistID: "specialist_2", Status: TaskStatusFailed, Timestamp: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	reports, err := m.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(reports) != 2 || reports[0].TaskID != "task_001" {
+		t.Errorf("unexpected reports: %+v", reports)
+	}
+
+	report, err := m.ReadByTaskID("task_002")
+	if err != nil {
+		t.Fatalf("ReadByTaskID failed: %v", err)
+	}
+	if report.Status != TaskStatusFailed {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	if _, err := m.ReadByTaskID("task_999"); err == nil {
+		t.Error("expected error for unknown task")
+	}
+}
+
+func TestReportManager_WriteRequiresIDs(t *testing.T) {
+	m := NewReportManager(t.TempDir())
+
+	if err := m.Write(&Report{TaskID: "task_001"}); err == nil {
+		t.Error("expected error without specialist_id")
+	}
+}
@@ -0,0 +1,52 @@
+package communication
+
+import "time"
+
+// レビューの判定
+type ReviewVerdict string
+
+const (
+	// レビュー待ち
+	ReviewVerdictPending ReviewVerdict = "pending"
+	// 承認
+	ReviewVerdictApprove ReviewVerdict = "approve"
+	// 修正依頼
+	ReviewVerdictRequestChanges ReviewVerdict = "request_changes"
+)
+
+// レビューのコメント
+type ReviewComment struct {
+	// 対象のファイルと行（省略可）
+	File    string `yaml:"file,omitempty"`
+	Line    int    `yaml:"line,omitempty"`
+	Comment string `yaml:"comment"`
+}
+
+// レビュー担当がレビュータスクのレポートに書く判定
+type ReviewResult struct {
+	Verdict  ReviewVerdict   `yaml:"verdict"`
+	Comments []ReviewComment `yaml:"comments,omitempty"`
+}
+
+// レビュー対象のタスクに bastion が記録するレビューの状態
+type Review struct {
+	// レビュータスク
+	Task string `yaml:"task"`
+	// 何回目のレビューか（修正依頼のたびに増える）
+	Round       int             `yaml:"round"`
+	Reviewer    string          `yaml:"reviewer,omitempty"`
+	Verdict     ReviewVerdict   `yaml:"verdict"`
+	Comments    []ReviewComment `yaml:"comments,omitempty"`
+	RequestedAt time.Time       `yaml:"requested_at"`
+	ReviewedAt  time.Time       `yaml:"reviewed_at,omitempty"`
+}
+
+// 判定として有効か（pending は担当が書く判定ではない）
+func (v ReviewVerdict) IsValid() bool {
+	return v == ReviewVerdictApprove || v == ReviewVerdictRequestChanges
+}
+
+// 承認済みか
+func (r *Review) IsApproved() bool {
+	return r != nil && r.Verdict == ReviewVerdictApprove
+}
@@ -0,0 +1,38 @@
+package communication
+
+import "time"
+
+// 指令のタスクを統合ブランチにマージした記録
+type CommandMerge struct {
+	Task   string `yaml:"task"`
+	Branch string `yaml:"branch"`
+	// 統合ブランチへのマージコミット
+	Commit string `yaml:"commit"`
+	// タスクのブランチから取り込んだコミット（古い順）
+	Commits  []string  `yaml:"commits,omitempty"`
+	MergedAt time.Time `yaml:"merged_at"`
+}
+
+// マージコミットを打ち消した記録
+type RevertedCommit struct {
+	Task string `yaml:"task,omitempty"`
+	// 打ち消したマージコミット
+	Commit string `yaml:"commit"`
+	// 作成した revert コミット
+	Revert string `yaml:"revert"`
+}
+
+// 指令のロールバック用ブランチ（履歴は書き換えず、revert コミットを積んでレビューに回す）
+type Rollback struct {
+	Branch string `yaml:"branch"`
+	// ロールバック用ブランチの分岐元（マージを取り込んだブランチ）と、その時点のコミット
+	Base       string `yaml:"base"`
+	BaseCommit string `yaml:"base_commit"`
+	// 打ち消したマージコミット（新しい順）
+	Reverted []RevertedCommit `yaml:"reverted,omitempty"`
+	// revert がコンフリクトしたマージコミットとファイル（解消すると再実行できる）
+	Conflict      string    `yaml:"conflict,omitempty"`
+	ConflictFiles []string  `yaml:"conflict_files,omitempty"`
+	CreatedAt     time.Time `yaml:"created_at"`
+	UpdatedAt     time.Time `yaml:"updated_at,omitempty"`
+}
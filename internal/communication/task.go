@@ -0,0 +1,184 @@
+package communication
+
+import "time"
+
+// タスク状態
+type TaskStatus string
+
+const (
+	// 未割当・割当待ち
+	TaskStatusPending TaskStatus = "pending"
+	// スケジューラが割り当て済み・未着手
+	TaskStatusAssigned TaskStatus = "assigned"
+	// 作業中
+	TaskStatusInProgress TaskStatus = "in_progress"
+	// 完了
+	TaskStatusCompleted TaskStatus = "completed"
+	// 失敗
+	TaskStatusFailed TaskStatus = "failed"
+	// 人間の承認待ち（bastion approvals で承認されるまで割り当てない）
+	TaskStatusAwaitingApproval TaskStatus = "awaiting_approval"
+)
+
+// Marshall から Specialist へのタスク
+type Task struct {
+	TaskID       string     `yaml:"task_id"`
+	SpecialistID string     `yaml:"specialist_id"`
+	CommandID    string     `yaml:"command_id"`
+	Objective    string     `yaml:"objective"`
+	Deliverables []string   `yaml:"deliverables"`
+	Context      string     `yaml:"context,omitempty"`
+	Dependencies []string   `yaml:"dependencies,omitempty"`
+	Status       TaskStatus `yaml:"status"`
+	Timestamp    time.Time  `yaml:"timestamp,omitempty"`
+	// 変更予定のパス（ディレクトリ・ファイル・glob）。実行中のタスク同士で重ならないようリースを取る
+	Paths []string `yaml:"paths,omitempty"`
+	// 作業ブランチと worktree（worktree を使用する場合）
+	Branch   string `yaml:"branch,omitempty"`
+	Worktree string `yaml:"worktree,omitempty"`
+	// 統合ブランチへのマージコミット（bastion merge が記録）
+	MergedCommit string `yaml:"merged_commit,omitempty"`
+	// マージでタスクのブランチから取り込んだコミット（古い順、bastion merge が記録）
+	Commits []string `yaml:"commits,omitempty"`
+	// コンフリクト解消タスクの場合、マージに失敗した元のタスク
+	ResolvesConflictOf string `yaml:"resolves_conflict_of,omitempty"`
+	// レビュータスクの場合、レビュー対象のタスク
+	ReviewOf string `yaml:"review_of,omitempty"`
+	// レビューの状態（review.enabled の場合に bastion が記録）
+	Review *Review `yaml:"review,omitempty"`
+	// 並列に試行する数（2 以上ならスケジューラが別々の Specialist に試行を割り当て、最良の結果を選ぶ）
+	Attempts int `yaml:"attempts,omitempty"`
+	// 試行のタスクの場合、元のタスク
+	AttemptOf string `yaml:"attempt_of,omitempty"`
+	// 並列試行の状態（bastion が記録）
+	Selection *AttemptSelection `yaml:"selection,omitempty"`
+	// スケジューラが割り当てた時刻
+	AssignedAt time.Time `yaml:"assigned_at,omitempty"`
+	// ルーターによる担当候補の判定結果
+	Routing *RoutingDecision `yaml:"routing,omitempty"`
+	// 実際の変更と変更予定パスの照合結果
+	Scope *ScopeCheck `yaml:"scope,omitempty"`
+	// 優先度（high/medium/low、省略時は指令の優先度）
+	Priority string `yaml:"priority,omitempty"`
+	// 制限時間（省略時は優先度ごとの設定）
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// bastion が in_progress を検知した時刻
+	StartedAt time.Time `yaml:"started_at,omitempty"`
+	// 失敗の理由（timeout など、bastion が失敗にした場合）
+	Failure string `yaml:"failure,omitempty"`
+	// 失敗のレポートを受けて再実行した回数
+	RetryCount int `yaml:"retry_count,omitempty"`
+	// 再実行時に割り当てない Specialist（失敗した担当）
+	ExcludedSpecialists []string `yaml:"excluded_specialists,omitempty"`
+	// 割り当て前に依頼した承認の ID
+	Approval string `yaml:"approval,omitempty"`
+	// bastion による自動対応の履歴
+	History []TaskEvent `yaml:"history,omitempty"`
+
+	// 読み込み元のファイル（Marshall が specialist_N.yaml として書いたタスクを上書きするため）
+	path string
+}
+
+// タスクの担当 Specialist を選んだ根拠（監査用）
+type RoutingDecision struct {
+	// 選ばれた Specialist
+	Specialist string `yaml:"specialist"`
+	// 選ばれた Specialist のスコア
+	Score int `yaml:"score"`
+	// 一致した trigger_patterns / capabilities
+	Matched []string `yaml:"matched,omitempty"`
+	// 判定理由
+	Reason string `yaml:"reason"`
+	// 比較したすべての候補のスコア
+	Candidates []RoutingScore `yaml:"candidates,omitempty"`
+	DecidedAt  time.Time      `yaml:"decided_at"`
+}
+
+// 候補ごとのスコア
+type RoutingScore struct {
+	Specialist string `yaml:"specialist"`
+	Score      int    `yaml:"score"`
+	// 空きがない場合など、選択対象から外した理由
+	Skipped string `yaml:"skipped,omitempty"`
+}
+
+// タスク履歴の種類
+const (
+	// 作業開始を検知した
+	TaskEventStarted = "started"
+	// 停滞しているため nudge した
+	TaskEventNudged = "nudged"
+	// 停滞が続くため Marshall にエスカレーションした
+	TaskEventEscalated = "escalated"
+	// 制限時間を超えたため失敗にした
+	TaskEventTimedOut = "timed_out"
+	// failed のレポートを受け取った
+	TaskEventFailed = "failed"
+	// 失敗したタスクを再キューした
+	TaskEventRetried = "retried"
+	// 再実行の上限に達したため Envoy にエスカレーションした
+	TaskEventEscalatedToHuman = "escalated_to_human"
+	// 人間の承認を依頼した
+	TaskEventApprovalRequested = "approval_requested"
+	// 承認された
+	TaskEventApproved = "approved"
+	// 却下されたため失敗にした
+	TaskEventRejected = "rejected"
+	// ブランチの変更に秘密情報を検出したためマージを止めた
+	TaskEventSecretsDetected = "secrets_detected"
+	// 品質ゲートがすべて成功した
+	TaskEventGatesPassed = "gates_passed"
+	// 品質ゲートが失敗したため担当に差し戻した
+	TaskEventGateFailed = "gate_failed"
+	// カバレッジが基準を下回った
+	TaskEventCoverageDropped = "coverage_dropped"
+	// レビューを依頼した
+	TaskEventReviewRequested = "review_requested"
+	// レビューで承認された
+	TaskEventReviewApproved = "review_approved"
+	// レビューで修正を依頼されたため担当に差し戻した
+	TaskEventChangesRequested = "changes_requested"
+	// レビュータスクのレポートに判定がなかった
+	TaskEventVerdictMissing = "verdict_missing"
+	// 並列試行を開始した
+	TaskEventAttemptsStarted = "attempts_started"
+	// 並列試行の勝者を選んだ
+	TaskEventAttemptSelected = "attempt_selected"
+	// 並列試行がすべて失敗した
+	TaskEventAttemptsFailed = "attempts_failed"
+	// スケジューラが無効のため並列試行を行わない
+	TaskEventAttemptsUnscheduled = "attempts_unscheduled"
+)
+
+// タスク履歴
+type TaskEvent struct {
+	At     time.Time `yaml:"at"`
+	Event  string    `yaml:"event"`
+	Detail string    `yaml:"detail,omitempty"`
+}
+
+// 実際に変更したファイルと変更予定パスの照合結果
+type ScopeCheck struct {
+	// 変更されたファイル
+	Changed []string `yaml:"changed,omitempty"`
+	// 変更予定パスに含まれないファイル
+	Undeclared []string `yaml:"undeclared,omitempty"`
+	// 変更ファイルの取得元（diff: タスクのブランチの差分、report: レポートの成果物）
+	Source    string    `yaml:"source"`
+	CheckedAt time.Time `yaml:"checked_at"`
+}
+
+// 読み込み元のファイルパス（未保存のタスクは空）
+func (t *Task) Path() string {
+	return t.path
+}
+
+// 履歴を追加
+func (t *Task) AddEvent(at time.Time, event, detail string) {
+	t.History = append(t.History, TaskEvent{At: at, Event: event, Detail: detail})
+}
+
+// タスクが終了状態か
+func (t *Task) IsFinished() bool {
+	return t.Status == TaskStatusCompleted || t.Status == TaskStatusFailed
+}
@@ -0,0 +1,161 @@
+package communication
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Specialist 向けタスクの読み書きを管理する
+// タスクは指令と同じ tasks ディレクトリに置かれ、task_id の有無で区別する
+type TaskManager struct {
+	tasksDir string
+	mu       sync.Mutex
+}
+
+// 新しいタスクマネージャーを作成
+func NewTaskManager(queueDir string) *TaskManager {
+	return &TaskManager{
+		tasksDir: filepath.Join(queueDir, "tasks"),
+	}
+}
+
+// タスクを書き込む
+// 読み込んだタスクは元のファイルへ、新規タスクは tasks/<task_id>.yaml へ保存する
+func (m *TaskManager) Write(task *Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.write(task)
+}
+
+// すべてのタスクを読み込む（タイムスタンプの古い順）
+func (m *TaskManager) Read() ([]Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.readAll()
+}
+
+// 特定のタスクを読み込む
+func (m *TaskManager) ReadByID(id string) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.find(id)
+}
+
+// タスクを読み込んで更新し、書き戻す
+func (m *TaskManager) Update(id string, fn func(*Task) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, err := m.find(id)
+	if err != nil {
+		return err
+	}
+	if err := fn(task); err != nil {
+		return err
+	}
+	return m.write(task)
+}
+
+// タスクを ID で検索
+func (m *TaskManager) find(id string) (*Task, error) {
+	// 通常は tasks/<task_id>.yaml に保存されている
+	if task, err := m.readTaskFile(filepath.Join(m.tasksDir, id+".yaml")); err == nil && task.TaskID == id {
+		return task, nil
+	}
+
+	// Marshall が specialist_N.yaml として書いたタスクも探す
+	tasks, err := m.readAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].TaskID == id {
+			return &tasks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("task not found: %s", id)
+}
+
+// tasks ディレクトリ内のタスクをすべて読み込む
+func (m *TaskManager) readAll() ([]Task, error) {
+	entries, err := os.ReadDir(m.tasksDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Task{}, nil
+		}
+		return nil, fmt.Errorf("failed to read tasks directory: %w", err)
+	}
+
+	tasks := []Task{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+
+		taskPath := filepath.Join(m.tasksDir, entry.Name())
+		task, err := m.readTaskFile(taskPath)
+		if err != nil {
+			// 指令ファイルや壊れたファイルは読み飛ばす
+			continue
+		}
+
+		// task_id を持たないファイルは指令
+		if task.TaskID == "" {
+			continue
+		}
+		tasks = append(tasks, *task)
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Timestamp.Before(tasks[j].Timestamp)
+	})
+
+	return tasks, nil
+}
+
+// タスクファイルを読み込む
+func (m *TaskManager) readTaskFile(path string) (*Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var task Task
+	if err := yaml.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+	}
+	task.path = path
+
+	return &task, nil
+}
+
+// タスクファイルに書き込む
+func (m *TaskManager) write(task *Task) error {
+	if task.TaskID == "" {
+		return fmt.Errorf("task_id is required")
+	}
+
+	if err := os.MkdirAll(m.tasksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	if task.path == "" {
+		task.path = filepath.Join(m.tasksDir, task.TaskID+".yaml")
+	}
+
+	data, err := yaml.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	if err := os.WriteFile(task.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package communication
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTaskManager_WriteAndRead(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewTaskManager(tmpDir)
+
+	task := &Task{
+		TaskID:       "task_001",
+		SpecialistID: "specialist_1",
+		CommandID:    "cmd_001",
+		Objective:    "ログインエンドポイントを実装",
+		Deliverables: []string{"src/auth/login.go"},
+		Status:       TaskStatusPending,
+		Timestamp:    time.Now(),
+	}
+	if err := manager.Write(task); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// tasks/<task_id>.yaml に保存される
+	if _, err := os.Stat(filepath.Join(tmpDir, "tasks", "task_001.yaml")); err != nil {
+		t.Fatalf("task file was not created: %v", err)
+	}
+
+	got, err := manager.ReadByID("task_001")
+	if err != nil {
+		t.Fatalf("ReadByID failed: %v", err)
+	}
+	if got.Objective != task.Objective || got.SpecialistID != "specialist_1" {
+		t.Errorf("unexpected task: %+v", got)
+	}
+}
+
+func TestTaskManager_ReadSkipsCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// 同じ tasks ディレクトリに指令とタスクを置く
+	commands := NewCommandQueueManager(tmpDir)
+	if err := commands.Write(Command{ID: "cmd_001", Status: CommandStatusPending}); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+
+	manager := NewTaskManager(tmpDir)
+	if err := manager.Write(&Task{TaskID: "task_001", CommandID: "cmd_001", Status: TaskStatusPending}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	tasks, err := manager.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].TaskID != "task_001" {
+		t.Errorf("expected only task_001, got %+v", tasks)
+	}
+
+	// 指令側からもタスクは見えない
+	cmds, err := commands.Read()
+	if err != nil {
+		t.Fatalf("failed to read commands: %v", err)
+	}
+	if len(cmds) != 1 || cmds[0].ID != "cmd_001" {
+		t.Errorf("expected only cmd_001, got %+v", cmds)
+	}
+}
+
+func TestTaskManager_UpdatePreservesFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		t.Fatalf("failed to create tasks dir: %v", err)
+	}
+
+	// Marshall が specialist_N.yaml として書いたタスク
+	content := "task_id: subtask_001\nspecialist_id: specialist_2\ncommand_id: cmd_001\nobjective: テスト作成\nstatus: in_progress\n"
+	specialistFile := filepath.Join(tasksDir, "specialist_2.yaml")
+	if err := os.WriteFile(specialistFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write task file: %v", err)
+	}
+
+	manager := NewTaskManager(tmpDir)
+	err := manager.Update("subtask_001", func(task *Task) error {
+		task.Status = TaskStatusCompleted
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// 元のファイルが更新され、別ファイルは作られない
+	if _, err := os.Stat(filepath.Join(tasksDir, "subtask_001.yaml")); !os.IsNotExist(err) {
+		t.Error("update should not create a new task file")
+	}
+
+	got, err := manager.ReadByID("subtask_001")
+	if err != nil {
+		t.Fatalf("ReadByID failed: %v", err)
+	}
+	if got.Status != TaskStatusCompleted {
+		t.Errorf("expected status completed, got %s", got.Status)
+	}
+	if !got.IsFinished() {
+		t.Error("completed task should be finished")
+	}
+}
+
+func TestTaskManager_ReadByIDNotFound(t *testing.T) {
+	manager := NewTaskManager(t.TempDir())
+
+	if _, err := manager.ReadByID("missing"); err == nil {
+		t.Error("expected error for missing task")
+	}
+}
+
+func TestTaskManager_WriteRequiresID(t *testing.T) {
+	manager := NewTaskManager(t.TempDir())
+
+	if err := manager.Write(&Task{Objective: "no id"}); err == nil {
+		t.Error("expected error for task without task_id")
+	}
+}
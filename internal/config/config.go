@@ -0,0 +1,654 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"gopkg.in/yaml.v3"
+)
+
+// 設定ファイルのプロジェクトルートからの相対パス
+const FileName = "agents/config.yaml"
+
+// Bastion の設定
+type Config struct {
+	Escalation   EscalationConfig   `yaml:"escalation"`
+	Activity     ActivityConfig     `yaml:"activity"`
+	Restart      RestartConfig      `yaml:"restart"`
+	Scheduler    SchedulerConfig    `yaml:"scheduler"`
+	Worktree     WorktreeConfig     `yaml:"worktree"`
+	Resources    ResourcesConfig    `yaml:"resources"`
+	Leases       LeasesConfig       `yaml:"leases"`
+	Timeouts     TimeoutsConfig     `yaml:"timeouts"`
+	Fallback     FallbackConfig     `yaml:"fallback"`
+	Intervention InterventionConfig `yaml:"intervention"`
+	Secrets      SecretsConfig      `yaml:"secrets"`
+	Gates        GatesConfig        `yaml:"gates"`
+	Acceptance   AcceptanceConfig   `yaml:"acceptance"`
+	Tests        TestsConfig        `yaml:"tests"`
+	Coverage     CoverageConfig     `yaml:"coverage"`
+	Review       ReviewConfig       `yaml:"review"`
+	Attempts     AttemptsConfig     `yaml:"attempts"`
+}
+
+// wakeup エスカレーション設定
+// 未処理メッセージの経過時間に応じて Phase 1 → 2 → 3 と段階的に wakeup を強める
+type EscalationConfig struct {
+	// エスカレーションを有効にするか
+	Enabled bool `yaml:"enabled"`
+	// 未処理メッセージを確認する間隔
+	CheckInterval time.Duration `yaml:"check_interval"`
+	// Phase 1（inbox nudge の再送）までの経過時間
+	Phase1After time.Duration `yaml:"phase1_after"`
+	// Phase 2（Escape + nudge）までの経過時間
+	Phase2After time.Duration `yaml:"phase2_after"`
+	// Phase 3（引き継ぎノート保存 + /clear）までの経過時間
+	Phase3After time.Duration `yaml:"phase3_after"`
+}
+
+// エージェント活動状態の監視設定
+type ActivityConfig struct {
+	// ペインを確認する間隔（保留中の nudge の再送判定にも使用）
+	CheckInterval time.Duration `yaml:"check_interval"`
+	// 状態判定に使用するペイン末尾の行数
+	CaptureLines int `yaml:"capture_lines"`
+}
+
+// 終了したエージェントの自動再起動設定
+type RestartConfig struct {
+	// 自動再起動を有効にするか
+	Enabled bool `yaml:"enabled"`
+	// 再起動回数の上限（超えたら再起動を諦めて通知する）
+	MaxRestarts int `yaml:"max_restarts"`
+	// 初回の再起動待ち時間（以降は倍々に延ばす）
+	Backoff time.Duration `yaml:"backoff"`
+	// 再起動待ち時間の上限
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// この時間安定して動作したら再起動回数をリセット
+	ResetAfter time.Duration `yaml:"reset_after"`
+}
+
+// タスクスケジューラ設定
+// 依存関係が解決したタスクを空いている Specialist に自動で割り当てる
+type SchedulerConfig struct {
+	// スケジューラを有効にするか（無効の場合は Marshall が割り当てる）
+	Enabled bool `yaml:"enabled"`
+	// 割り当てを確認する間隔
+	Interval time.Duration `yaml:"interval"`
+	// 割り当て後この時間着手されなければ、空いている Specialist に付け替える
+	StealAfter time.Duration `yaml:"steal_after"`
+}
+
+// Specialist ごとの git worktree 設定
+type WorktreeConfig struct {
+	// Specialist を .worktrees/sp<N> で起動し、タスクごとのブランチで作業させるか
+	Enabled bool `yaml:"enabled"`
+	// 新しい worktree にプロジェクトルートからコピーするファイル（.env など git 管理外のファイル）
+	CopyFiles []string `yaml:"copy_files"`
+	// 新しい worktree で順に実行する初期化コマンド（依存パッケージのインストールなど）
+	Bootstrap []string `yaml:"bootstrap"`
+	// 初期化コマンドごとのタイムアウト
+	BootstrapTimeout time.Duration `yaml:"bootstrap_timeout"`
+	// worktree 間で共有するキャッシュディレクトリ（プロジェクトルートからの相対パス）
+	CacheDir string `yaml:"cache_dir"`
+	// 初期化コマンドに渡す環境変数（$BASTION_CACHE_DIR などを展開）
+	Env map[string]string `yaml:"env"`
+	// Specialist の種類ごとに checkout するディレクトリ（キーは外部定義の name、汎用 Specialist は specialist）
+	SparseCheckout map[string][]string `yaml:"sparse_checkout"`
+}
+
+// Specialist ごとのリソース割り当て設定
+// 並列で起動する開発サーバーやテスト用 DB がポート・ファイルを取り合わないよう、重ならない範囲を割り当てる
+type ResourcesConfig struct {
+	// 割り当てるポート範囲の先頭
+	PortBase int `yaml:"port_base"`
+	// Specialist 1 人あたりのポート数
+	PortsPerSpecialist int `yaml:"ports_per_specialist"`
+	// Specialist ごとの作業用ディレクトリの配置先（プロジェクトルートからの相対パス）
+	ScratchDir string `yaml:"scratch_dir"`
+}
+
+// 変更予定パスが実行中のタスクと重なる場合の動作
+const (
+	// リースを使用しない
+	LeaseModeOff = "off"
+	// 割り当てて Marshall に警告する
+	LeaseModeWarn = "warn"
+	// 重なるタスクが終わるまで割り当てない
+	LeaseModeBlock = "block"
+)
+
+// ファイル所有権（リース）設定
+// タスクの変更予定パス（paths）が実行中のタスクと重なる場合に、スケジューラの割り当てを警告・抑止する
+type LeasesConfig struct {
+	// off / warn / block
+	Mode string `yaml:"mode"`
+}
+
+// in_progress タスクのタイムアウト・停滞検知設定
+// ペイン出力と worktree の変更が止まったタスクを nudge → Marshall へエスカレーションし、
+// 制限時間を超えたタスクは failed（failure: timeout）にする
+type TimeoutsConfig struct {
+	// タイムアウト・停滞検知を有効にするか
+	Enabled bool `yaml:"enabled"`
+	// タスクを確認する間隔
+	CheckInterval time.Duration `yaml:"check_interval"`
+	// タスクの制限時間（タスクの timeout・優先度ごとの設定がない場合）
+	Default time.Duration `yaml:"default"`
+	// 優先度（high/medium/low）ごとの制限時間
+	Priorities map[string]time.Duration `yaml:"priorities"`
+	// ペイン出力・ファイル変更がこの時間なければ担当 Specialist を nudge
+	StallAfter time.Duration `yaml:"stall_after"`
+	// ペイン出力・ファイル変更がこの時間なければ Marshall にエスカレーション
+	EscalateAfter time.Duration `yaml:"escalate_after"`
+}
+
+// 失敗したタスクへの対応方針
+type FallbackPolicy struct {
+	// 再実行する最大回数（0 なら再実行しない）
+	MaxRetries int `yaml:"max_retries"`
+	// 再実行時は失敗した Specialist 以外に割り当て、別のアプローチを指示する
+	RetryWithDifferentApproach bool `yaml:"retry_with_different_approach"`
+	// 再実行しても失敗したら Envoy にエスカレーションする
+	EscalateToHuman bool `yaml:"escalate_to_human"`
+}
+
+// 対応方針の部分的な上書き（指定した項目のみ上書きする）
+type FallbackOverride struct {
+	MaxRetries                 *int  `yaml:"max_retries,omitempty"`
+	RetryWithDifferentApproach *bool `yaml:"retry_with_different_approach,omitempty"`
+	EscalateToHuman            *bool `yaml:"escalate_to_human,omitempty"`
+}
+
+// 失敗したタスクの再実行・エスカレーション設定
+// Specialist が failed のレポートを書いたタスクを、失敗の要約をコンテキストに追記して再キューし、
+// 再実行の上限に達したら Envoy の inbox にエスカレーションする
+type FallbackConfig struct {
+	// 既定の対応方針
+	FallbackPolicy `yaml:",inline"`
+	// 優先度（high/medium/low）ごとの上書き
+	Priorities map[string]FallbackOverride `yaml:"priorities"`
+}
+
+// 上書きを適用した対応方針
+func (o *FallbackOverride) Apply(policy FallbackPolicy) FallbackPolicy {
+	if o == nil {
+		return policy
+	}
+	if o.MaxRetries != nil {
+		policy.MaxRetries = *o.MaxRetries
+	}
+	if o.RetryWithDifferentApproach != nil {
+		policy.RetryWithDifferentApproach = *o.RetryWithDifferentApproach
+	}
+	if o.EscalateToHuman != nil {
+		policy.EscalateToHuman = *o.EscalateToHuman
+	}
+	return policy
+}
+
+// 優先度と指令ごとの上書きを適用した対応方針（指令 > 優先度 > 既定）
+func (c FallbackConfig) Policy(priority string, command *FallbackOverride) FallbackPolicy {
+	policy := c.FallbackPolicy
+	if override, ok := c.Priorities[priority]; ok {
+		policy = override.Apply(policy)
+	}
+	return command.Apply(policy)
+}
+
+// 承認が必要な変更の判定条件
+type ApprovalPolicy struct {
+	// タスクの objective / deliverables / context に含まれる語（大文字小文字を区別しない）
+	Keywords []string `yaml:"keywords"`
+	// 変更予定パス・変更したファイルに一致するパス（glob、** は任意の深さ）
+	Paths []string `yaml:"paths"`
+}
+
+// 人間の承認が必要な変更の設定
+// require_approval に挙げた判定条件に一致するタスクは割り当て前に、ブランチの変更はマージ前に
+// awaiting_approval にして bastion approvals で承認・却下されるまで止める
+type InterventionConfig struct {
+	// 承認を必要とする判定条件の名前（policies のキー）
+	RequireApproval []string `yaml:"require_approval"`
+	// 名前ごとの判定条件
+	Policies map[string]ApprovalPolicy `yaml:"policies"`
+}
+
+// 秘密情報の検出設定
+// タスクのブランチの差分と inbox・レポートの本文から API キーなどを検出し、検出したブランチはマージしない
+// inbox・レポート・タスクの履歴・ログに書かれた秘密情報は伏せる
+type SecretsConfig struct {
+	// 秘密情報の検出を有効にするか
+	Enabled bool `yaml:"enabled"`
+	// 秘密情報らしい名前の変数に代入された値を検出するエントロピーの下限（bit/文字）
+	EntropyThreshold float64 `yaml:"entropy_threshold"`
+	// 値または行に一致すれば検出しない正規表現（例やダミーの値）
+	Allowlist []string `yaml:"allowlist"`
+	// 検出しないファイル（glob、** は任意の深さ）
+	AllowPaths []string `yaml:"allow_paths"`
+}
+
+// 品質ゲートのコマンド
+type GateCommand struct {
+	// ゲートの名前（レポート・通知に表示する）
+	Name string `yaml:"name"`
+	// タスクの worktree で実行するシェルコマンド（sh -c）
+	Run string `yaml:"run"`
+	// タイムアウト（0 なら gates.timeout）
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// 品質ゲート設定
+// 完了報告が届いたらタスクの worktree でテストや lint を実行して結果をレポートに記録し、
+// 失敗したゲートがあればタスクを担当の Specialist に差し戻す
+type GatesConfig struct {
+	// 品質ゲートを有効にするか
+	Enabled bool `yaml:"enabled"`
+	// 順に実行するコマンド
+	Commands []GateCommand `yaml:"commands"`
+	// コマンドごとの既定のタイムアウト
+	Timeout time.Duration `yaml:"timeout"`
+	// レポートに記録する出力の末尾のバイト数
+	OutputLimit int `yaml:"output_limit"`
+}
+
+// 完了条件の検証設定
+// 指令の完了条件のうち verify（シェルコマンド）または test（テスト名）を持つものを実行して検証する
+type AcceptanceConfig struct {
+	// test を持つ完了条件を検証するシェルコマンド（テスト名は $BASTION_TEST）
+	TestCommand string `yaml:"test_command"`
+	// 完了条件ごとのタイムアウト
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// テスト結果の取り込み設定
+// 完了報告が届いたらタスクの worktree のテスト結果（go test -json / JUnit XML / TAP）を読み込んでレポートに記録し、
+// 失敗したテストの割合に応じて評価の correctness を抑える
+type TestsConfig struct {
+	// テスト結果を取り込むか
+	Enabled bool `yaml:"enabled"`
+	// テスト結果のファイル（worktree からの glob、** は任意の深さ）
+	Results []string `yaml:"results"`
+}
+
+// カバレッジの比較設定
+// 品質ゲートを通過した完了報告について、ブランチの分岐点と先頭でカバレッジを計測してパッケージごとの変化を記録し、
+// 基準を下回ったタスクを Marshall に通知する
+type CoverageConfig struct {
+	// カバレッジを比較するか
+	Enabled bool `yaml:"enabled"`
+	// カバレッジを $BASTION_COVERAGE_PROFILE に書き出すシェルコマンド
+	Command string `yaml:"command"`
+	// 書き出す形式（go / lcov）
+	Format string `yaml:"format"`
+	// 1 回の計測のタイムアウト
+	Timeout time.Duration `yaml:"timeout"`
+	// 全体・パッケージごとに許容する低下（ポイント）
+	MaxDrop float64 `yaml:"max_drop"`
+	// 下がった結果が下回ってはいけないカバレッジ（%、0 なら判定しない）
+	MinCoverage float64 `yaml:"min_coverage"`
+}
+
+// ピアレビューの設定
+// 完了したタスクを別の Specialist へのレビュータスクとして割り当て、修正依頼なら元の担当に差し戻す。
+// 承認されるまでタスクはマージしない
+type ReviewConfig struct {
+	// レビューを行うか
+	Enabled bool `yaml:"enabled"`
+	// 修正依頼で差し戻す回数の上限（超えたら Marshall に判断を依頼する）
+	MaxRounds int `yaml:"max_rounds"`
+}
+
+// 並列試行の勝者を選ぶ基準
+const (
+	// 品質ゲートがすべて成功した試行を優先
+	AttemptCriterionGates = "gates"
+	// 失敗したテストの割合が低い試行を優先
+	AttemptCriterionTests = "tests"
+	// 評価のスコアの合計が高い試行を優先
+	AttemptCriterionEvaluation = "evaluation"
+	// カバレッジの変化が大きい試行を優先
+	AttemptCriterionCoverage = "coverage"
+	// 変更したファイルが少ない試行を優先
+	AttemptCriterionChanges = "changes"
+	// 早く完了した試行を優先
+	AttemptCriterionDuration = "duration"
+)
+
+// 並列試行の基準か
+func IsAttemptCriterion(name string) bool {
+	switch name {
+	case AttemptCriterionGates, AttemptCriterionTests, AttemptCriterionEvaluation,
+		AttemptCriterionCoverage, AttemptCriterionChanges, AttemptCriterionDuration:
+		return true
+	}
+	return false
+}
+
+// 並列試行の設定
+// タスクに attempts: N（2 以上）を指定すると、スケジューラが同じタスクを N 個の試行に分けて別々の worktree で実行させ、
+// すべて終わったら基準に従って勝者を選ぶ。選ばれなかった試行のブランチは退避する
+type AttemptsConfig struct {
+	// 1 タスクあたりの試行の上限
+	Max int `yaml:"max"`
+	// 勝者を選ぶ基準（先頭から順に比較し、差がつかなければ次の基準で比較する）
+	Criteria []string `yaml:"criteria"`
+	// すべての試行の終了後、criteria の evaluation / coverage の結果が揃うのを待つ時間
+	Wait time.Duration `yaml:"wait"`
+	// 試行が揃ったかの確認間隔
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// デフォルト設定を返す
+func Default() *Config {
+	return &Config{
+		Escalation: EscalationConfig{
+			Enabled:       true,
+			CheckInterval: 30 * time.Second,
+			Phase1After:   2 * time.Minute,
+			Phase2After:   5 * time.Minute,
+			Phase3After:   15 * time.Minute,
+		},
+		Activity: ActivityConfig{
+			CheckInterval: 5 * time.Second,
+			CaptureLines:  40,
+		},
+		Restart: RestartConfig{
+			Enabled:     true,
+			MaxRestarts: 5,
+			Backoff:     30 * time.Second,
+			MaxBackoff:  10 * time.Minute,
+			ResetAfter:  30 * time.Minute,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:    false,
+			Interval:   15 * time.Second,
+			StealAfter: 5 * time.Minute,
+		},
+		Worktree: WorktreeConfig{
+			Enabled:          false,
+			BootstrapTimeout: 10 * time.Minute,
+			CacheDir:         ".worktrees/.cache",
+		},
+		Resources: ResourcesConfig{
+			PortBase:           20000,
+			PortsPerSpecialist: 100,
+			ScratchDir:         "agents/queue/scratch",
+		},
+		Leases: LeasesConfig{
+			Mode: LeaseModeWarn,
+		},
+		Timeouts: TimeoutsConfig{
+			Enabled:       true,
+			CheckInterval: time.Minute,
+			Default:       4 * time.Hour,
+			Priorities: map[string]time.Duration{
+				"high": 2 * time.Hour,
+				"low":  8 * time.Hour,
+			},
+			StallAfter:    15 * time.Minute,
+			EscalateAfter: 30 * time.Minute,
+		},
+		Fallback: FallbackConfig{
+			FallbackPolicy: FallbackPolicy{
+				MaxRetries:                 2,
+				RetryWithDifferentApproach: true,
+				EscalateToHuman:            true,
+			},
+		},
+		Intervention: InterventionConfig{
+			Policies: map[string]ApprovalPolicy{
+				"destructive_changes": {
+					Keywords: []string{"破壊的", "削除", "drop table", "drop column", "truncate", "rm -rf", "force push", "breaking change"},
+					Paths:    []string{"**/migrations/**"},
+				},
+				// マージ前はブランチの依存定義ファイルの差分から追加された依存を検出する
+				"new_dependencies": {
+					Keywords: []string{"依存を追加", "依存関係を追加", "ライブラリを追加", "パッケージを追加", "add dependency", "new dependency"},
+				},
+				"security_changes": {
+					Keywords: []string{"セキュリティ", "認証", "認可", "権限", "暗号", "パスワード", "security", "authentication", "authorization", "password", "credential", "secret"},
+					Paths:    []string{"**/auth/**", "**/security/**", "**/crypto/**"},
+				},
+			},
+		},
+		Secrets: SecretsConfig{
+			Enabled:          true,
+			EntropyThreshold: 3.5,
+			Allowlist:        []string{`(?i)example`, `(?i)dummy`, `(?i)placeholder`, `(?i)changeme`},
+			AllowPaths:       []string{"**/testdata/**"},
+		},
+		Gates: GatesConfig{
+			Enabled:     false,
+			Timeout:     10 * time.Minute,
+			OutputLimit: 4000,
+		},
+		Acceptance: AcceptanceConfig{
+			TestCommand: `go test ./... -run "^${BASTION_TEST}$"`,
+			Timeout:     10 * time.Minute,
+		},
+		Tests: TestsConfig{
+			Enabled: true,
+			Results: []string{"test-results/**", "**/junit*.xml", "**/*.tap"},
+		},
+		Coverage: CoverageConfig{
+			Enabled: false,
+			Command: `go test -coverprofile="$BASTION_COVERAGE_PROFILE" ./...`,
+			Format:  analysis.CoverageFormatGo,
+			Timeout: 10 * time.Minute,
+			MaxDrop: 1.0,
+		},
+		Review: ReviewConfig{
+			Enabled:   false,
+			MaxRounds: 3,
+		},
+		Attempts: AttemptsConfig{
+			Max: 3,
+			Criteria: []string{
+				AttemptCriterionGates,
+				AttemptCriterionTests,
+				AttemptCriterionEvaluation,
+				AttemptCriterionCoverage,
+				AttemptCriterionDuration,
+			},
+			Wait:          10 * time.Minute,
+			CheckInterval: time.Minute,
+		},
+	}
+}
+
+// プロジェクトルートの設定ファイルを読み込む
+func Load(projectRoot string) (*Config, error) {
+	return LoadFile(filepath.Join(projectRoot, FileName))
+}
+
+// 設定ファイルを読み込む（存在しない場合はデフォルト設定を返す）
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// 指定されたキーのみデフォルト値を上書き
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// 設定値の整合性を検証
+func (c *Config) Validate() error {
+	e := c.Escalation
+	if e.CheckInterval <= 0 {
+		return fmt.Errorf("escalation.check_interval must be positive")
+	}
+	if e.Phase1After <= 0 || e.Phase2After <= e.Phase1After || e.Phase3After <= e.Phase2After {
+		return fmt.Errorf("escalation phases must satisfy 0 < phase1_after < phase2_after < phase3_after")
+	}
+	if c.Activity.CheckInterval <= 0 {
+		return fmt.Errorf("activity.check_interval must be positive")
+	}
+	if c.Activity.CaptureLines <= 0 {
+		return fmt.Errorf("activity.capture_lines must be positive")
+	}
+	r := c.Restart
+	if r.MaxRestarts < 0 {
+		return fmt.Errorf("restart.max_restarts must not be negative")
+	}
+	if r.Backoff <= 0 || r.MaxBackoff < r.Backoff {
+		return fmt.Errorf("restart backoff must satisfy 0 < backoff <= max_backoff")
+	}
+	if c.Scheduler.Interval <= 0 {
+		return fmt.Errorf("scheduler.interval must be positive")
+	}
+	if c.Scheduler.StealAfter <= 0 {
+		return fmt.Errorf("scheduler.steal_after must be positive")
+	}
+	w := c.Worktree
+	if w.BootstrapTimeout <= 0 {
+		return fmt.Errorf("worktree.bootstrap_timeout must be positive")
+	}
+	for _, file := range w.CopyFiles {
+		if !isRelativePath(file) {
+			return fmt.Errorf("worktree.copy_files must be relative paths inside the project: %s", file)
+		}
+	}
+	for name, paths := range w.SparseCheckout {
+		for _, path := range paths {
+			if !isRelativePath(path) {
+				return fmt.Errorf("worktree.sparse_checkout.%s must be relative paths inside the project: %s", name, path)
+			}
+		}
+	}
+	res := c.Resources
+	if res.PortBase < 1024 || res.PortsPerSpecialist <= 0 || res.PortBase+res.PortsPerSpecialist-1 > 65535 {
+		return fmt.Errorf("resources must satisfy 1024 <= port_base and port_base + ports_per_specialist - 1 <= 65535")
+	}
+	if !isRelativePath(res.ScratchDir) {
+		return fmt.Errorf("resources.scratch_dir must be a relative path inside the project: %s", res.ScratchDir)
+	}
+	switch c.Leases.Mode {
+	case LeaseModeOff, LeaseModeWarn, LeaseModeBlock:
+	default:
+		return fmt.Errorf("leases.mode must be one of off, warn, block: %s", c.Leases.Mode)
+	}
+	t := c.Timeouts
+	if t.CheckInterval <= 0 || t.Default <= 0 {
+		return fmt.Errorf("timeouts.check_interval and timeouts.default must be positive")
+	}
+	for priority, timeout := range t.Priorities {
+		if timeout <= 0 {
+			return fmt.Errorf("timeouts.priorities.%s must be positive", priority)
+		}
+	}
+	if t.StallAfter <= 0 || t.EscalateAfter <= t.StallAfter {
+		return fmt.Errorf("timeouts must satisfy 0 < stall_after < escalate_after")
+	}
+	if c.Fallback.MaxRetries < 0 {
+		return fmt.Errorf("fallback.max_retries must not be negative")
+	}
+	for priority, override := range c.Fallback.Priorities {
+		if override.MaxRetries != nil && *override.MaxRetries < 0 {
+			return fmt.Errorf("fallback.priorities.%s.max_retries must not be negative", priority)
+		}
+	}
+	// 割り当て前の判定はスケジューラが行う
+	if len(c.Intervention.RequireApproval) > 0 && !c.Scheduler.Enabled {
+		return fmt.Errorf("intervention.require_approval requires scheduler.enabled")
+	}
+	for _, name := range c.Intervention.RequireApproval {
+		policy, ok := c.Intervention.Policies[name]
+		if !ok {
+			return fmt.Errorf("intervention.require_approval: unknown policy %s", name)
+		}
+		if len(policy.Keywords) == 0 && len(policy.Paths) == 0 {
+			return fmt.Errorf("intervention.policies.%s must have keywords or paths", name)
+		}
+	}
+	if c.Secrets.EntropyThreshold < 0 {
+		return fmt.Errorf("secrets.entropy_threshold must not be negative")
+	}
+	for _, expr := range c.Secrets.Allowlist {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("secrets.allowlist: invalid pattern %q: %w", expr, err)
+		}
+	}
+	g := c.Gates
+	if g.Timeout <= 0 || g.OutputLimit <= 0 {
+		return fmt.Errorf("gates.timeout and gates.output_limit must be positive")
+	}
+	names := make(map[string]bool)
+	for i, gate := range g.Commands {
+		if gate.Name == "" || strings.TrimSpace(gate.Run) == "" {
+			return fmt.Errorf("gates.commands[%d] must have name and run", i)
+		}
+		if names[gate.Name] {
+			return fmt.Errorf("gates.commands: duplicate name %s", gate.Name)
+		}
+		names[gate.Name] = true
+		if gate.Timeout < 0 {
+			return fmt.Errorf("gates.commands.%s.timeout must not be negative", gate.Name)
+		}
+	}
+	if strings.TrimSpace(c.Acceptance.TestCommand) == "" || c.Acceptance.Timeout <= 0 {
+		return fmt.Errorf("acceptance.test_command must be set and acceptance.timeout must be positive")
+	}
+	if c.Tests.Enabled && len(c.Tests.Results) == 0 {
+		return fmt.Errorf("tests.results must not be empty when tests are enabled")
+	}
+	cov := c.Coverage
+	if strings.TrimSpace(cov.Command) == "" || cov.Timeout <= 0 {
+		return fmt.Errorf("coverage.command must be set and coverage.timeout must be positive")
+	}
+	if !analysis.IsCoverageFormat(cov.Format) {
+		return fmt.Errorf("coverage.format: unknown format %s", cov.Format)
+	}
+	if cov.MaxDrop < 0 || cov.MinCoverage < 0 || cov.MinCoverage > 100 {
+		return fmt.Errorf("coverage.max_drop must not be negative and coverage.min_coverage must be between 0 and 100")
+	}
+	if c.Review.MaxRounds <= 0 {
+		return fmt.Errorf("review.max_rounds must be positive")
+	}
+	a := c.Attempts
+	if a.Max < 2 || len(a.Criteria) == 0 || a.Wait < 0 {
+		return fmt.Errorf("attempts.max must be at least 2, attempts.criteria must not be empty and attempts.wait must not be negative")
+	}
+	if a.CheckInterval <= 0 {
+		return fmt.Errorf("attempts.check_interval must be positive")
+	}
+	seen := make(map[string]bool)
+	for _, criterion := range a.Criteria {
+		if !IsAttemptCriterion(criterion) {
+			return fmt.Errorf("attempts.criteria: unknown criterion %s", criterion)
+		}
+		if seen[criterion] {
+			return fmt.Errorf("attempts.criteria: duplicate criterion %s", criterion)
+		}
+		seen[criterion] = true
+	}
+	return nil
+}
+
+// プロジェクト内を指す相対パスか
+func isRelativePath(path string) bool {
+	if path == "" || filepath.IsAbs(path) {
+		return false
+	}
+	clean := filepath.Clean(path)
+	return clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
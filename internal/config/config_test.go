@@ -0,0 +1,431 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad_MissingFileReturnsDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg, err := Load(tmpDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := Default()
+	if cfg.Escalation != want.Escalation {
+		t.Errorf("expected default escalation %+v, got %+v", want.Escalation, cfg.Escalation)
+	}
+}
+
+func TestLoad_OverridesOnlySpecifiedKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, FileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+
+	content := "escalation:\n  phase2_after: 7m\n  phase3_after: 20m\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(tmpDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Escalation.Phase2After != 7*time.Minute {
+		t.Errorf("expected phase2_after 7m, got %s", cfg.Escalation.Phase2After)
+	}
+	if cfg.Escalation.Phase3After != 20*time.Minute {
+		t.Errorf("expected phase3_after 20m, got %s", cfg.Escalation.Phase3After)
+	}
+	// 指定していないキーはデフォルト値のまま
+	if cfg.Escalation.Phase1After != Default().Escalation.Phase1After {
+		t.Errorf("expected default phase1_after, got %s", cfg.Escalation.Phase1After)
+	}
+	if !cfg.Escalation.Enabled {
+		t.Error("expected escalation to stay enabled")
+	}
+}
+
+func TestLoadFile_InvalidPhaseOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "escalation:\n  phase1_after: 10m\n  phase2_after: 5m\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for phase2_after < phase1_after")
+	}
+}
+
+func TestLoadFile_InvalidYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("escalation: ["), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for invalid yaml")
+	}
+}
+
+func TestLoadFile_InvalidRestartBackoff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "restart:\n  backoff: 5m\n  max_backoff: 1m\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for max_backoff < backoff")
+	}
+}
+
+func TestLoadFile_Scheduler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "scheduler:\n  enabled: true\n  steal_after: 1m\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if !cfg.Scheduler.Enabled || cfg.Scheduler.StealAfter != time.Minute {
+		t.Errorf("scheduler overrides not applied: %+v", cfg.Scheduler)
+	}
+	if cfg.Scheduler.Interval != Default().Scheduler.Interval {
+		t.Errorf("expected default interval, got %s", cfg.Scheduler.Interval)
+	}
+}
+
+func TestLoadFile_WorktreeBootstrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `worktree:
+  enabled: true
+  bootstrap:
+    - npm ci
+  env:
+    npm_config_cache: $BASTION_CACHE_DIR/npm
+  sparse_checkout:
+    frontend-dev: [apps/web]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	w := cfg.Worktree
+	if len(w.Bootstrap) != 1 || w.Env["npm_config_cache"] == "" || w.SparseCheckout["frontend-dev"][0] != "apps/web" {
+		t.Errorf("worktree overrides not applied: %+v", w)
+	}
+	if w.BootstrapTimeout != Default().Worktree.BootstrapTimeout || w.CacheDir != Default().Worktree.CacheDir {
+		t.Errorf("expected default timeout and cache dir, got %+v", w)
+	}
+}
+
+func TestLoadFile_InvalidWorktreePaths(t *testing.T) {
+	for _, content := range []string{
+		"worktree:\n  copy_files: [../secret.env]\n",
+		"worktree:\n  sparse_checkout:\n    specialist: [/etc]\n",
+	} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoadFile_InvalidResources(t *testing.T) {
+	for _, content := range []string{
+		"resources:\n  port_base: 80\n",
+		"resources:\n  port_base: 65500\n  ports_per_specialist: 100\n",
+		"resources:\n  scratch_dir: /tmp/scratch\n",
+	} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoadFile_InvalidLeaseMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("leases:\n  mode: strict\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for unknown lease mode")
+	}
+}
+
+func TestLoadFile_FallbackPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "fallback:\n  max_retries: 1\n  priorities:\n    high:\n      max_retries: 3\n    low:\n      escalate_to_human: false\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	f := cfg.Fallback
+	if f.MaxRetries != 1 || !f.RetryWithDifferentApproach || !f.EscalateToHuman {
+		t.Errorf("unexpected default policy: %+v", f.FallbackPolicy)
+	}
+	if got := f.Policy("high", nil); got.MaxRetries != 3 || !got.EscalateToHuman {
+		t.Errorf("priority override not applied: %+v", got)
+	}
+	if got := f.Policy("low", nil); got.MaxRetries != 1 || got.EscalateToHuman {
+		t.Errorf("priority override not applied: %+v", got)
+	}
+
+	// 指令の上書きは優先度より優先する
+	retries, different := 0, false
+	got := f.Policy("high", &FallbackOverride{MaxRetries: &retries, RetryWithDifferentApproach: &different})
+	if got.MaxRetries != 0 || got.RetryWithDifferentApproach || !got.EscalateToHuman {
+		t.Errorf("command override not applied: %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("fallback:\n  max_retries: -1\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for negative max_retries")
+	}
+}
+
+func TestLoadFile_Intervention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "scheduler:\n  enabled: true\nintervention:\n  require_approval: [new_dependencies, schema_changes]\n  policies:\n    schema_changes:\n      paths: [db/schema.sql]\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	// 追加した判定条件は既定の判定条件とあわせて使える
+	policies := cfg.Intervention.Policies
+	if len(policies["schema_changes"].Paths) != 1 || len(policies["new_dependencies"].Keywords) == 0 {
+		t.Errorf("unexpected policies: %+v", policies)
+	}
+
+	for _, content := range []string{
+		"scheduler:\n  enabled: true\nintervention:\n  require_approval: [unknown]\n",
+		"scheduler:\n  enabled: true\nintervention:\n  require_approval: [empty]\n  policies:\n    empty: {}\n",
+		// 割り当て前の判定にはスケジューラが必要
+		"intervention:\n  require_approval: [new_dependencies]\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoadFile_Secrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "secrets:\n  allowlist: ['^test_']\n  allow_paths: ['fixtures/**']\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	s := cfg.Secrets
+	if !s.Enabled || s.EntropyThreshold != 3.5 || len(s.Allowlist) != 1 || s.AllowPaths[0] != "fixtures/**" {
+		t.Errorf("unexpected secrets config: %+v", s)
+	}
+
+	for _, content := range []string{
+		"secrets:\n  allowlist: ['(']\n",
+		"secrets:\n  entropy_threshold: -1\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoadFile_Gates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "gates:\n  enabled: true\n  commands:\n    - name: test\n      run: go test ./...\n    - name: lint\n      run: golangci-lint run\n      timeout: 3m\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	g := cfg.Gates
+	if !g.Enabled || len(g.Commands) != 2 || g.Commands[1].Timeout != 3*time.Minute || g.Timeout != 10*time.Minute {
+		t.Errorf("unexpected gates config: %+v", g)
+	}
+
+	for _, content := range []string{
+		"gates:\n  commands:\n    - name: test\n",
+		"gates:\n  commands:\n    - {name: test, run: make test}\n    - {name: test, run: make lint}\n",
+		"gates:\n  output_limit: 0\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoadFile_Acceptance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "acceptance:\n  test_command: npx jest -t \"$BASTION_TEST\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if a := cfg.Acceptance; a.TestCommand != `npx jest -t "$BASTION_TEST"` || a.Timeout != 10*time.Minute {
+		t.Errorf("unexpected acceptance config: %+v", a)
+	}
+
+	if err := os.WriteFile(path, []byte("acceptance:\n  test_command: \"\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for empty test_command")
+	}
+}
+
+func TestLoadFile_Tests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "tests:\n  results: [\"build/reports/**/*.xml\"]\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if !cfg.Tests.Enabled || len(cfg.Tests.Results) != 1 || cfg.Tests.Results[0] != "build/reports/**/*.xml" {
+		t.Errorf("unexpected tests config: %+v", cfg.Tests)
+	}
+
+	if err := os.WriteFile(path, []byte("tests:\n  results: []\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for empty results")
+	}
+}
+
+func TestLoadFile_Coverage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "coverage:\n  enabled: true\n  command: npx jest --coverage --coverageReporters=lcovonly\n  format: lcov\n  min_coverage: 80\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if c := cfg.Coverage; !c.Enabled || c.Format != "lcov" || c.MinCoverage != 80 || c.MaxDrop != 1.0 || c.Timeout != 10*time.Minute {
+		t.Errorf("unexpected coverage config: %+v", c)
+	}
+
+	for _, content := range []string{
+		"coverage:\n  format: cobertura\n",
+		"coverage:\n  max_drop: -1\n",
+		"coverage:\n  min_coverage: 120\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoadFile_Review(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("review:\n  enabled: true\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if r := cfg.Review; !r.Enabled || r.MaxRounds != 3 {
+		t.Errorf("unexpected review config: %+v", r)
+	}
+
+	if err := os.WriteFile(path, []byte("review:\n  max_rounds: 0\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for max_rounds: 0")
+	}
+}
+
+func TestLoadFile_Attempts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("attempts:\n  max: 2\n  criteria: [tests, duration]\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if a := cfg.Attempts; a.Max != 2 || len(a.Criteria) != 2 || a.Criteria[0] != AttemptCriterionTests || a.Wait != 10*time.Minute || a.CheckInterval != time.Minute {
+		t.Errorf("unexpected attempts config: %+v", a)
+	}
+
+	for _, content := range []string{
+		"attempts:\n  max: 1\n",
+		"attempts:\n  criteria: [speed]\n",
+		"attempts:\n  criteria: [tests, tests]\n",
+		"attempts:\n  check_interval: 0s\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package evaluation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"gopkg.in/yaml.v3"
+)
+
+// スコアの上限
+const MaxScore = 5
+
+// 品質スコア（各項目 1-5、0 は未採点）
+type Scores struct {
+	// 要件充足度
+	Correctness int `yaml:"correctness"`
+	// コード品質
+	CodeQuality int `yaml:"code_quality"`
+	// 実行効率
+	Efficiency int `yaml:"efficiency"`
+}
+
+// スコアの合計
+func (s Scores) Total() int {
+	return s.Correctness + s.CodeQuality + s.Efficiency
+}
+
+// 抽出した知識
+type Knowledge struct {
+	// pattern / lesson / pitfall
+	Type    string `yaml:"type"`
+	Content string `yaml:"content"`
+}
+
+// Marshall による完了報告の評価
+// knowledge/evaluations/eval_<task_id>.yaml として保存される
+type Evaluation struct {
+	TaskID             string      `yaml:"task_id"`
+	Evaluator          string      `yaml:"evaluator"`
+	Timestamp          time.Time   `yaml:"timestamp"`
+	Scores             Scores      `yaml:"scores"`
+	IssuesFound        []string    `yaml:"issues_found,omitempty"`
+	KnowledgeExtracted []Knowledge `yaml:"knowledge_extracted,omitempty"`
+	// レポートのテスト結果（bastion が記録）
+	Tests *analysis.TestResults `yaml:"tests,omitempty"`
+	// カバレッジの変化（bastion が記録）
+	Coverage *analysis.CoverageReport `yaml:"coverage,omitempty"`
+	// bastion がスコアを調整した理由
+	Adjustments []string `yaml:"adjustments,omitempty"`
+}
+
+// 評価ファイルの形式（evaluation: の下に書く）
+type evaluationFile struct {
+	Evaluation *Evaluation `yaml:"evaluation"`
+}
+
+// 失敗したテストの割合に応じた correctness の上限
+// 失敗がなければ上限なし、すべて失敗していれば 1
+func CorrectnessCap(tests analysis.TestResults) int {
+	if tests.Failed == 0 {
+		return MaxScore
+	}
+	return max(1, MaxScore-1-(MaxScore-2)*tests.Failed/tests.Total())
+}
+
+// テスト結果を記録し、correctness を失敗したテストの割合に応じた上限までに抑える
+// 変更があれば true を返す
+func (e *Evaluation) ApplyTests(tests analysis.TestResults) bool {
+	changed := false
+	if e.Tests == nil || !reflect.DeepEqual(*e.Tests, tests) {
+		copied := tests
+		e.Tests = &copied
+		changed = true
+	}
+
+	limit := CorrectnessCap(tests)
+	if e.Scores.Correctness > limit {
+		e.Adjustments = append(e.Adjustments, fmt.Sprintf("correctness: %d -> %d (%d failing test(s))", e.Scores.Correctness, limit, tests.Failed))
+		e.Scores.Correctness = limit
+		changed = true
+	}
+	return changed
+}
+
+// カバレッジの変化を記録する（変更があれば true を返す）
+func (e *Evaluation) ApplyCoverage(coverage analysis.CoverageReport) bool {
+	if e.Coverage != nil && reflect.DeepEqual(*e.Coverage, coverage) {
+		return false
+	}
+	copied := coverage
+	e.Coverage = &copied
+	return true
+}
+
+// 評価の読み書きを管理する
+type Manager struct {
+	dir string
+}
+
+// 新しい評価マネージャーを作成
+func NewManager(projectRoot string) *Manager {
+	return &Manager{
+		dir: filepath.Join(projectRoot, "knowledge", "evaluations"),
+	}
+}
+
+// 評価ディレクトリ
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// タスクの評価ファイル
+func (m *Manager) Path(taskID string) string {
+	return filepath.Join(m.dir, "eval_"+taskID+".yaml")
+}
+
+// 評価ファイルを読み込む（evaluation: の下に書いた形式とトップレベルに書いた形式のどちらも読む）
+func (m *Manager) ReadFile(path string) (*Evaluation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file evaluationFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal evaluation: %w", err)
+	}
+	if file.Evaluation != nil {
+		return file.Evaluation, nil
+	}
+
+	var eval Evaluation
+	if err := yaml.Unmarshal(data, &eval); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal evaluation: %w", err)
+	}
+	return &eval, nil
+}
+
+// タスクの評価を読み込む
+func (m *Manager) Read(taskID string) (*Evaluation, error) {
+	return m.ReadFile(m.Path(taskID))
+}
+
+// 評価を書き込む（knowledge/evaluations/eval_<task_id>.yaml）
+func (m *Manager) Write(eval *Evaluation) error {
+	if eval.TaskID == "" {
+		return fmt.Errorf("task_id is required")
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create evaluations directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(evaluationFile{Evaluation: eval})
+	if err != nil {
+		return fmt.Errorf("failed to marshal evaluation: %w", err)
+	}
+	if err := os.WriteFile(m.Path(eval.TaskID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write evaluation file: %w", err)
+	}
+	return nil
+}
+
+// 評価ディレクトリのファイルか
+func (m *Manager) IsEvaluationFile(path string) bool {
+	return filepath.Dir(path) == filepath.Clean(m.dir) && strings.HasPrefix(filepath.Base(path), "eval_") && filepath.Ext(path) == ".yaml"
+}
@@ -0,0 +1,106 @@
+package evaluation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+)
+
+func TestCorrectnessCap(t *testing.T) {
+	for _, tc := range []struct {
+		passed, failed int
+		want           int
+	}{
+		{passed: 10, failed: 0, want: 5},
+		{passed: 99, failed: 1, want: 4},
+		{passed: 5, failed: 5, want: 3},
+		{passed: 1, failed: 3, want: 2},
+		{passed: 0, failed: 2, want: 1},
+	} {
+		if got := CorrectnessCap(analysis.TestResults{Passed: tc.passed, Failed: tc.failed}); got != tc.want {
+			t.Errorf("CorrectnessCap(%d passed, %d failed) = %d, want %d", tc.passed, tc.failed, got, tc.want)
+		}
+	}
+}
+
+func TestEvaluation_ApplyTests(t *testing.T) {
+	eval := &Evaluation{TaskID: "task_001", Scores: Scores{Correctness: 5, CodeQuality: 4}}
+	tests := analysis.TestResults{Format: analysis.TestFormatGoJSON, Passed: 5, Failed: 5, Failures: []string{"auth.TestLogin"}}
+
+	if !eval.ApplyTests(tests) {
+		t.Fatal("ApplyTests should report a change")
+	}
+	if eval.Scores.Correctness != 3 || eval.Scores.CodeQuality != 4 || len(eval.Adjustments) != 1 || eval.Tests.Failed != 5 {
+		t.Errorf("correctness should be capped: %+v", eval)
+	}
+	if eval.ApplyTests(tests) {
+		t.Error("applying the same results should not change the evaluation")
+	}
+}
+
+func TestEvaluation_ApplyCoverage(t *testing.T) {
+	eval := &Evaluation{TaskID: "task_001", Scores: Scores{Correctness: 5}}
+	coverage := analysis.CoverageReport{Format: analysis.CoverageFormatGo, Before: 80, After: 75, Delta: -5, Flags: []string{"total: 80.0% -> 75.0% (-5.0)"}}
+
+	if !eval.ApplyCoverage(coverage) || eval.Coverage.Delta != -5 {
+		t.Errorf("coverage should be recorded: %+v", eval)
+	}
+	if eval.ApplyCoverage(coverage) {
+		t.Error("applying the same coverage should not change the evaluation")
+	}
+	if eval.Scores.Correctness != 5 {
+		t.Errorf("coverage should not change scores: %+v", eval.Scores)
+	}
+}
+
+func TestManager_ReadWrite(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root)
+
+	// Marshall が evaluation: の下に書いた評価
+	content := `evaluation:
+  task_id: subtask_001
+  evaluator: marshall
+  scores:
+    correctness: 5
+    code_quality: 4
+    efficiency: 4
+  knowledge_extracted:
+    - type: pattern
+      content: "Go JWT実装では github.com/golang-jwt/jwt/v5 を使用"
+`
+	if err := os.MkdirAll(m.Dir(), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(m.Path("subtask_001"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write evaluation: %v", err)
+	}
+
+	eval, err := m.Read("subtask_001")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if eval.Evaluator != "marshall" || eval.Scores.Efficiency != 4 || len(eval.KnowledgeExtracted) != 1 {
+		t.Errorf("unexpected evaluation: %+v", eval)
+	}
+
+	// トップレベルに書いた形式も読む
+	flat := &Evaluation{TaskID: "subtask_002", Scores: Scores{Correctness: 3}}
+	if err := m.Write(flat); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := os.WriteFile(m.Path("subtask_003"), []byte("task_id: subtask_003\nscores:\n  correctness: 2\n"), 0644); err != nil {
+		t.Fatalf("failed to write evaluation: %v", err)
+	}
+	for id, want := range map[string]int{"subtask_002": 3, "subtask_003": 2} {
+		if got, err := m.Read(id); err != nil || got.Scores.Correctness != want {
+			t.Errorf("unexpected evaluation for %s: %+v (%v)", id, got, err)
+		}
+	}
+
+	if !m.IsEvaluationFile(filepath.Join(root, "knowledge", "evaluations", "eval_subtask_001.yaml")) || m.IsEvaluationFile(filepath.Join(root, "knowledge", "index.yaml")) {
+		t.Error("IsEvaluationFile should match only evaluation files")
+	}
+}
@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 完了条件の検証結果
+type VerifyReport struct {
+	Command *communication.Command
+	// 検証したディレクトリ（統合用 worktree またはプロジェクトルート）
+	Dir string
+	// 今回実行した完了条件の番号（1 始まり）
+	Ran []int
+	// 満たしていない完了条件の番号（1 始まり）
+	Unresolved []int
+	// 今回の検証で completed にしたか
+	Completed bool
+}
+
+// 完了条件を検証するディレクトリ（統合用 worktree があればそこ、なければプロジェクトルート）
+func (o *Orchestrator) acceptanceWorkdir(commandID string) string {
+	if o.worktrees != nil {
+		path := o.worktrees.Path(parallel.IntegrationWorktreeName(commandID))
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+	}
+	return o.projectRoot
+}
+
+// 完了条件を実行するゲート（verify はそのまま、test は acceptance.test_command で実行）
+func (o *Orchestrator) criterionGate(index int, c communication.AcceptanceCriterion) config.GateCommand {
+	gate := config.GateCommand{
+		Name:    fmt.Sprintf("criterion_%d", index),
+		Run:     c.Verify,
+		Timeout: o.config.Acceptance.Timeout,
+	}
+	if gate.Run == "" {
+		gate.Run = o.config.Acceptance.TestCommand
+	}
+	return gate
+}
+
+// 指令の完了条件のうち実行できるものを検証して結果を記録し、
+// すべて passed または waived になれば指令を completed にする
+func (o *Orchestrator) VerifyCommand(commandID string) (*VerifyReport, error) {
+	cmd, err := o.commands.ReadByID(commandID)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := o.acceptanceWorkdir(commandID)
+	report := &VerifyReport{Dir: dir}
+	results := make(map[int]communication.GateResult)
+	for i, c := range cmd.AcceptanceCriteria {
+		// 免除した完了条件は実行しない
+		if !c.Executable() || c.Status == communication.CriterionStatusWaived {
+			continue
+		}
+		env := append(os.Environ(),
+			"BASTION_PROJECT_ROOT="+o.projectRoot,
+			"BASTION_WORKTREE="+dir,
+			"BASTION_COMMAND_ID="+commandID,
+			"BASTION_TEST="+c.Test,
+		)
+		log.Printf("[acceptance] %s: 完了条件 %d（%s）を検証しています...", commandID, i+1, c.Description)
+		results[i] = o.runGate(dir, o.criterionGate(i+1, c), env)
+		report.Ran = append(report.Ran, i+1)
+	}
+
+	now := time.Now()
+	err = o.commands.Update(commandID, func(c *communication.Command) error {
+		for i, r := range results {
+			if i >= len(c.AcceptanceCriteria) {
+				continue
+			}
+			criterion := &c.AcceptanceCriteria[i]
+			criterion.Status = communication.CriterionStatusFailed
+			if r.Passed {
+				criterion.Status = communication.CriterionStatusPassed
+			}
+			criterion.Evidence = FormatGateResult(r)
+			if r.Output != "" {
+				criterion.Evidence += "\n" + r.Output
+			}
+			criterion.VerifiedAt = now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err = o.commands.ReadByID(commandID)
+	if err != nil {
+		return nil, err
+	}
+	report.Unresolved = cmd.UnresolvedCriteria()
+	if len(report.Unresolved) == 0 && cmd.Status != communication.CommandStatusCompleted && cmd.Status != communication.CommandStatusFailed {
+		if err := o.commands.UpdateStatus(commandID, communication.CommandStatusCompleted); err != nil {
+			return nil, err
+		}
+		log.Printf("[acceptance] %s の完了条件をすべて満たしたため completed にしました", commandID)
+		report.Completed = true
+		if cmd, err = o.commands.ReadByID(commandID); err != nil {
+			return nil, err
+		}
+	}
+	report.Command = cmd
+	return report, nil
+}
+
+// 完了条件の検証を免除する（index は 1 始まり）
+func (o *Orchestrator) WaiveCriterion(commandID string, index int, by, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("reason is required to waive a criterion")
+	}
+	return o.commands.Update(commandID, func(c *communication.Command) error {
+		if index < 1 || index > len(c.AcceptanceCriteria) {
+			return fmt.Errorf("%s has no criterion %d", commandID, index)
+		}
+		criterion := &c.AcceptanceCriteria[index-1]
+		criterion.Status = communication.CriterionStatusWaived
+		criterion.WaivedBy = by
+		criterion.Evidence = reason
+		criterion.VerifiedAt = time.Now()
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+func TestVerifyCommand(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Acceptance.TestCommand = `test "$BASTION_TEST" = TestLogin`
+
+	cmd := communication.Command{
+		ID:        "cmd_001",
+		Timestamp: time.Now(),
+		Purpose:   "JWT認証を実装",
+		AcceptanceCriteria: []communication.AcceptanceCriterion{
+			{Description: "テストがパスする", Verify: "echo ok"},
+			{Description: "ログインのテスト", Test: "TestLogin"},
+			{Description: "リフレッシュのテスト", Test: "TestRefresh"},
+			{Description: "README を更新する"},
+		},
+		Status: communication.CommandStatusInProgress,
+	}
+	if err := o.commands.Write(cmd); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+
+	report, err := o.VerifyCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("VerifyCommand failed: %v", err)
+	}
+	if !reflect.DeepEqual(report.Ran, []int{1, 2, 3}) || !reflect.DeepEqual(report.Unresolved, []int{3, 4}) || report.Completed {
+		t.Fatalf("unexpected verify report: %+v", report)
+	}
+	criteria := report.Command.AcceptanceCriteria
+	if criteria[0].Status != communication.CriterionStatusPassed || !strings.HasPrefix(criteria[0].Evidence, "criterion_1: passed") || criteria[0].VerifiedAt.IsZero() {
+		t.Errorf("criterion 1 should pass with evidence: %+v", criteria[0])
+	}
+	if criteria[1].Status != communication.CriterionStatusPassed {
+		t.Errorf("criterion 2 should pass: %+v", criteria[1])
+	}
+	if criteria[2].Status != communication.CriterionStatusFailed || !strings.HasPrefix(criteria[2].Evidence, "criterion_3: exit 1") {
+		t.Errorf("criterion 3 should fail: %+v", criteria[2])
+	}
+	if criteria[3].CurrentStatus() != communication.CriterionStatusUnverified {
+		t.Errorf("criterion 4 should stay unverified: %+v", criteria[3])
+	}
+	if report.Command.Status != communication.CommandStatusInProgress {
+		t.Errorf("command should not be completed: %s", report.Command.Status)
+	}
+
+	// 免除と修正で完了条件を満たせば completed になる
+	if err := o.WaiveCriterion("cmd_001", 4, "alice", ""); err == nil {
+		t.Error("waive should require a reason")
+	}
+	if err := o.WaiveCriterion("cmd_001", 5, "alice", "不要"); err == nil {
+		t.Error("waive should fail for unknown criterion")
+	}
+	if err := o.WaiveCriterion("cmd_001", 4, "alice", "README は別の指令で更新する"); err != nil {
+		t.Fatalf("WaiveCriterion failed: %v", err)
+	}
+	o.config.Acceptance.TestCommand = "true"
+
+	report, err = o.VerifyCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("VerifyCommand failed: %v", err)
+	}
+	if len(report.Unresolved) != 0 || !report.Completed || report.Command.Status != communication.CommandStatusCompleted {
+		t.Errorf("command should be completed: %+v", report)
+	}
+	if waived := report.Command.AcceptanceCriteria[3]; waived.Status != communication.CriterionStatusWaived || waived.WaivedBy != "alice" || waived.Evidence != "README は別の指令で更新する" {
+		t.Errorf("waived criterion should be kept: %+v", waived)
+	}
+}
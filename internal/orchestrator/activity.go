@@ -0,0 +1,255 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// エージェントの活動状態
+type AgentState string
+
+const (
+	// 入力待ち（nudge を受け付けられる）
+	AgentStateIdle AgentState = "idle"
+	// 思考中・ツール実行中
+	AgentStateBusy AgentState = "busy"
+	// 権限確認プロンプトで停止中
+	AgentStateAwaitingPermission AgentState = "awaiting_permission"
+	// claude が終了してシェルに戻っている
+	AgentStateCrashed AgentState = "crashed"
+	// 判定不能
+	AgentStateUnknown AgentState = "unknown"
+)
+
+// claude 終了後にペインに残るシェル
+var shellCommands = map[string]bool{
+	"bash": true,
+	"zsh":  true,
+	"sh":   true,
+	"dash": true,
+	"fish": true,
+	"ksh":  true,
+	"tcsh": true,
+}
+
+// 処理中に表示される文言
+var busyMarkers = []string{
+	"esc to interrupt",
+	"ctrl+c to interrupt",
+}
+
+// 権限確認プロンプトの文言
+var permissionMarkers = []string{
+	"do you want to proceed?",
+	"do you want to make this edit",
+	"do you want to create",
+	"do you want to allow",
+	"do you want to run",
+}
+
+// 入力待ちの文言
+var idleMarkers = []string{
+	"? for shortcuts",
+	"│ >",
+}
+
+// 判定に使用する末尾の行数（スクロールバック中の古い表示を無視する）
+const classifyTailLines = 15
+
+// エージェント名と tmux ターゲットの組
+type agentRef struct {
+	Name   string
+	Target string
+	// 登録時の起動時刻（登録がない場合はゼロ値）
+	StartedAt time.Time
+	// 外部 Specialist のペルソナ（汎用エージェントは空）
+	Persona string
+}
+
+// エージェントの状態
+type AgentStatus struct {
+	Name    string
+	Target  string
+	State   AgentState
+	Persona string
+}
+
+// ペインの内容と実行中コマンドからエージェントの状態を判定
+func ClassifyPane(output, currentCommand string, dead bool) AgentState {
+	if dead || shellCommands[currentCommand] {
+		return AgentStateCrashed
+	}
+
+	tail := strings.ToLower(lastLines(output, classifyTailLines))
+	if strings.TrimSpace(tail) == "" {
+		return AgentStateUnknown
+	}
+
+	for _, marker := range permissionMarkers {
+		if strings.Contains(tail, marker) {
+			return AgentStateAwaitingPermission
+		}
+	}
+	for _, marker := range busyMarkers {
+		if strings.Contains(tail, marker) {
+			return AgentStateBusy
+		}
+	}
+	for _, marker := range idleMarkers {
+		if strings.Contains(tail, marker) {
+			return AgentStateIdle
+		}
+	}
+
+	return AgentStateUnknown
+}
+
+// 空行を除いた末尾 n 行を返す
+func lastLines(output string, n int) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// エージェントの活動状態を検出
+func (o *Orchestrator) DetectState(agent string) (AgentState, error) {
+	target, ok := o.agentTarget(agent)
+	if !ok {
+		return AgentStateUnknown, fmt.Errorf("unknown agent: %s", agent)
+	}
+	return o.detectTargetState(target)
+}
+
+// tmux ターゲットの活動状態を検出
+func (o *Orchestrator) detectTargetState(target string) (AgentState, error) {
+	state, _, err := o.inspectTarget(target)
+	return state, err
+}
+
+// tmux ターゲットの活動状態と判定に使用したペイン内容を取得
+func (o *Orchestrator) inspectTarget(target string) (AgentState, string, error) {
+	info, err := o.sm.GetPaneInfo(target)
+	if err != nil {
+		return AgentStateUnknown, "", err
+	}
+
+	output, err := o.sm.CapturePane(target, o.config.Activity.CaptureLines)
+	if err != nil {
+		return AgentStateUnknown, "", err
+	}
+
+	return ClassifyPane(output, info.CurrentCommand, info.Dead), output, nil
+}
+
+// 起動中のエージェント一覧を取得
+func (o *Orchestrator) agents() []agentRef {
+	// bastion start で登録されたエージェントを優先
+	if registered, err := o.registry.List(); err == nil && len(registered) > 0 {
+		var agents []agentRef
+		for _, info := range registered {
+			ref := agentRef{Name: info.Name, Target: info.Target, StartedAt: info.StartedAt}
+			if info.Spec != nil {
+				ref.Persona = info.Spec.Persona
+			}
+			agents = append(agents, ref)
+		}
+		return agents
+	}
+
+	// 登録がない場合は既定のレイアウトから推定
+	agents := []agentRef{
+		{Name: AgentEnvoy, Target: "main.0"},
+		{Name: AgentMarshall, Target: "main.2"},
+	}
+
+	// Specialist 数は specialists ウィンドウのペイン数から求める
+	panes, err := o.sm.ListPanes(parallel.WindowSpecialists)
+	if err != nil {
+		return agents
+	}
+	for i := range panes {
+		name := fmt.Sprintf("%s_%d", AgentSpecialist, i+1)
+		agents = append(agents, agentRef{Name: name, Target: fmt.Sprintf("%s.%d", parallel.WindowSpecialists, i)})
+	}
+	return agents
+}
+
+// すべてのエージェントの活動状態を取得
+func (o *Orchestrator) AgentStates() []AgentStatus {
+	var statuses []AgentStatus
+	for _, agent := range o.agents() {
+		state, err := o.detectTargetState(agent.Target)
+		if err != nil {
+			log.Printf("warning: failed to detect state of %s: %v", agent.Name, err)
+		}
+		statuses = append(statuses, AgentStatus{Name: agent.Name, Target: agent.Target, State: state, Persona: agent.Persona})
+	}
+	return statuses
+}
+
+// nudge を送ってよい状態か
+// 判定不能な場合は従来どおり送信する
+func canNudge(state AgentState) bool {
+	return state == AgentStateIdle || state == AgentStateUnknown
+}
+
+// idle になるまで nudge を保留
+func (o *Orchestrator) deferNudge(agent string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.deferred[agent] = true
+}
+
+// 活動状態の監視ループを実行
+func (o *Orchestrator) runActivityMonitor() {
+	ticker := time.NewTicker(o.config.Activity.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case now := <-ticker.C:
+			o.checkCrashedAgents(now)
+			o.flushDeferredNudges()
+			o.checkPermissionPrompts()
+		}
+	}
+}
+
+// 保留中の nudge のうち、idle になったエージェントへ送信
+func (o *Orchestrator) flushDeferredNudges() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for agent := range o.deferred {
+		target, ok := o.agentTarget(agent)
+		if !ok {
+			delete(o.deferred, agent)
+			continue
+		}
+
+		state, err := o.detectTargetState(target)
+		if err != nil || !canNudge(state) {
+			continue
+		}
+
+		log.Printf("[watcher] %s が %s になったため保留中の wakeup を送信", agent, state)
+		if err := o.Wakeup(agent, target); err != nil {
+			log.Printf("[watcher] %s への wakeup に失敗: %v", agent, err)
+			continue
+		}
+		delete(o.deferred, agent)
+	}
+}
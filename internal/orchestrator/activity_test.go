@@ -0,0 +1,95 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyPane(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		command string
+		dead    bool
+		want    AgentState
+	}{
+		{
+			name:    "idle prompt",
+			output:  "╭────────╮\n│ >      │\n╰────────╯\n  ? for shortcuts\n",
+			command: "claude",
+			want:    AgentStateIdle,
+		},
+		{
+			name:    "thinking",
+			output:  "✻ Thinking… (12s · esc to interrupt)\n\n│ >      │\n",
+			command: "node",
+			want:    AgentStateBusy,
+		},
+		{
+			name: "permission prompt",
+			output: "Edit file\n queue/tasks/cmd_001.yaml\n" +
+				"Do you want to make this edit to cmd_001.yaml?\n❯ 1. Yes\n  2. No\n",
+			command: "claude",
+			want:    AgentStateAwaitingPermission,
+		},
+		{
+			name:    "returned to shell",
+			output:  "$ \n",
+			command: "bash",
+			want:    AgentStateCrashed,
+		},
+		{
+			name:    "dead pane",
+			output:  "",
+			command: "claude",
+			dead:    true,
+			want:    AgentStateCrashed,
+		},
+		{
+			name:    "empty pane",
+			output:  "\n\n",
+			command: "claude",
+			want:    AgentStateUnknown,
+		},
+		{
+			name: "old busy marker scrolled away",
+			output: "✻ Thinking… (esc to interrupt)\n" + strings.Repeat("output line\n", classifyTailLines) +
+				"│ >      │\n",
+			command: "claude",
+			want:    AgentStateIdle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyPane(tt.output, tt.command, tt.dead); got != tt.want {
+				t.Errorf("ClassifyPane() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	output := "a\n\nb\nc\n\n"
+	if got := lastLines(output, 2); got != "b\nc" {
+		t.Errorf("expected \"b\\nc\", got %q", got)
+	}
+	if got := lastLines(output, 10); got != "a\nb\nc" {
+		t.Errorf("expected all non-empty lines, got %q", got)
+	}
+}
+
+func TestCanNudge(t *testing.T) {
+	tests := map[AgentState]bool{
+		AgentStateIdle:               true,
+		AgentStateUnknown:            true,
+		AgentStateBusy:               false,
+		AgentStateAwaitingPermission: false,
+		AgentStateCrashed:            false,
+	}
+	for state, want := range tests {
+		if got := canNudge(state); got != want {
+			t.Errorf("canNudge(%s) = %v, want %v", state, got, want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+// 却下されたタスクの failure
+const failureRejected = "rejected"
+
+// 承認・却下の結果
+type ApprovalResult struct {
+	Request *communication.ApprovalRequest
+	// マージの承認で再開したマージの結果（それ以外は nil）
+	Merge *MergeReport
+}
+
+// 承認が必要な変更を判定するか
+func (o *Orchestrator) useApprovals() bool {
+	return len(o.config.Intervention.RequireApproval) > 0
+}
+
+// 文章と変更予定パス・変更したファイルを承認の判定条件と照合
+// 返り値: 一致した判定条件の名前と、一致した語・パス（"<名前>: <語>"）
+func matchApprovalPolicies(cfg config.InterventionConfig, text string, paths []string) ([]string, []string) {
+	text = strings.ToLower(text)
+
+	var policies, matches []string
+	for _, name := range cfg.RequireApproval {
+		policy := cfg.Policies[name]
+
+		var found []string
+		for _, keyword := range policy.Keywords {
+			keyword = strings.ToLower(strings.TrimSpace(keyword))
+			if keyword != "" && strings.Contains(text, keyword) {
+				found = append(found, name+": "+keyword)
+			}
+		}
+		for _, p := range paths {
+			for _, pattern := range policy.Paths {
+				if coversPath(pattern, p) {
+					found = append(found, name+": "+p)
+					break
+				}
+			}
+		}
+
+		if len(found) > 0 {
+			policies = append(policies, name)
+			matches = append(matches, found...)
+		}
+	}
+	return policies, matches
+}
+
+// 承認の判定に使うタスクの文章
+func approvalText(task communication.Task) string {
+	return task.Objective + "\n" + strings.Join(task.Deliverables, "\n") + "\n" + task.Context
+}
+
+// タスクについて承認済みの判定条件
+// 割り当ての承認とマージの承認は区別し、マージの承認は同じコミットに対するものだけを数える
+func (o *Orchestrator) approvedPolicies(taskID string, kind communication.ApprovalKind, commit string) (map[string]bool, error) {
+	approvals, err := o.approvals.List()
+	if err != nil {
+		return nil, err
+	}
+
+	approved := make(map[string]bool)
+	for _, req := range approvals {
+		if req.TaskID == taskID && req.Kind == kind && req.Commit == commit && req.Status == communication.ApprovalStatusApproved {
+			for _, policy := range req.Policies {
+				approved[policy] = true
+			}
+		}
+	}
+	return approved, nil
+}
+
+// 承認済みの判定条件を除く
+func (o *Orchestrator) unapprovedPolicies(taskID string, kind communication.ApprovalKind, commit string, policies, matches []string) ([]string, []string, error) {
+	if len(policies) == 0 {
+		return nil, nil, nil
+	}
+
+	approved, err := o.approvedPolicies(taskID, kind, commit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var restPolicies, restMatches []string
+	for _, policy := range policies {
+		if !approved[policy] {
+			restPolicies = append(restPolicies, policy)
+		}
+	}
+	for _, match := range matches {
+		if name, _, _ := strings.Cut(match, ": "); !approved[name] {
+			restMatches = append(restMatches, match)
+		}
+	}
+	return restPolicies, restMatches, nil
+}
+
+// 承認が必要なタスクを awaiting_approval にして承認を依頼
+// 返り値: 割り当てを止めたか
+func (o *Orchestrator) gateTask(task communication.Task, now time.Time) (bool, error) {
+	if !o.useApprovals() {
+		return false, nil
+	}
+
+	policies, matches := matchApprovalPolicies(o.config.Intervention, approvalText(task), taskPaths(task))
+	policies, matches, err := o.unapprovedPolicies(task.TaskID, communication.ApprovalKindTask, "", policies, matches)
+	if err != nil || len(policies) == 0 {
+		return false, err
+	}
+
+	req, created, err := o.approvals.Request(communication.ApprovalRequest{
+		Kind:        communication.ApprovalKindTask,
+		TaskID:      task.TaskID,
+		CommandID:   task.CommandID,
+		Policies:    policies,
+		Matches:     matches,
+		RequestedAt: now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	err = o.recordTaskEvent(task.TaskID, now, communication.TaskEventApprovalRequested, req.ID+": "+strings.Join(policies, ", "), func(t *communication.Task) {
+		t.Status = communication.TaskStatusAwaitingApproval
+		t.Approval = req.ID
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to hold task %s: %w", task.TaskID, err)
+	}
+
+	if created {
+		o.notifyApprovalRequest(req, "タスク "+task.TaskID+" の割り当て")
+	}
+	return true, nil
+}
+
+// 承認が必要な変更を含むタスクのブランチについて承認を依頼
+// 返り値: 判断待ちの承認（承認が不要なら nil）
+func (o *Orchestrator) gateMerge(task communication.Task, branch string) (*communication.ApprovalRequest, error) {
+	if !o.useApprovals() {
+		return nil, nil
+	}
+
+	commit, err := o.worktrees.Commit(branch)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := o.worktrees.ChangedFiles(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, matches := matchApprovalPolicies(o.config.Intervention, "", changed)
+
+	// 依存定義ファイルの差分から追加された依存を検出する
+	if containsString(o.config.Intervention.RequireApproval, policyNewDependencies) {
+		deps, err := o.branchDependencyChanges(branch)
+		if err != nil {
+			return nil, err
+		}
+		if added := addedDependencies(deps); len(added) > 0 {
+			if !containsString(policies, policyNewDependencies) {
+				policies = append(policies, policyNewDependencies)
+			}
+			for _, dep := range added {
+				matches = append(matches, policyNewDependencies+": added "+dep)
+			}
+		}
+	}
+
+	policies, matches, err = o.unapprovedPolicies(task.TaskID, communication.ApprovalKindMerge, commit, policies, matches)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+
+	req, created, err := o.approvals.Request(communication.ApprovalRequest{
+		Kind:      communication.ApprovalKindMerge,
+		TaskID:    task.TaskID,
+		CommandID: task.CommandID,
+		Commit:    commit,
+		Policies:  policies,
+		Matches:   matches,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		if err := o.recordTaskEvent(task.TaskID, req.RequestedAt, communication.TaskEventApprovalRequested, req.ID+": "+strings.Join(policies, ", "), nil); err != nil {
+			log.Printf("warning: failed to record approval request of %s: %v", task.TaskID, err)
+		}
+		o.notifyApprovalRequest(req, task.TaskID+" のブランチ "+branch+" のマージ")
+	}
+	return req, nil
+}
+
+// 承認の依頼を Envoy と Marshall の inbox に通知
+func (o *Orchestrator) notifyApprovalRequest(req *communication.ApprovalRequest, target string) {
+	log.Printf("[approval] %s に承認が必要です（%s）", target, strings.Join(req.Policies, ", "))
+
+	message := fmt.Sprintf("%s に人間の承認が必要です（%s）: %s。ユーザーに確認し、`bastion approvals approve %s` または `bastion approvals reject %s --reason <理由>` で判断を記録してください",
+		target, strings.Join(req.Policies, ", "), strings.Join(req.Matches, " / "), req.ID, req.ID)
+	if err := o.inbox.Write(AgentEnvoy, message, communication.MessageTypeApprovalRequested, "bastion"); err != nil {
+		log.Printf("[approval] envoy への通知に失敗: %v", err)
+	}
+
+	message = fmt.Sprintf("%s は承認待ち（%s）のため保留しています。承認されると再開し、却下されると中止します", target, req.ID)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeApprovalRequested, "bastion"); err != nil {
+		log.Printf("[approval] marshall への通知に失敗: %v", err)
+	}
+}
+
+// 承認の一覧（all が false なら判断待ちのみ）
+func (o *Orchestrator) Approvals(all bool) ([]communication.ApprovalRequest, error) {
+	if all {
+		return o.approvals.List()
+	}
+	return o.approvals.Pending()
+}
+
+// 承認・却下を記録し、対象の作業を再開・中止する
+// タスクは承認されると pending に戻してスケジューラの割り当て対象にし、却下されると failed（failure: rejected）にする
+// マージは承認されると指令のマージを再実行し、却下されるとタスクを failed にしてマージ対象から外す
+func (o *Orchestrator) DecideApproval(id string, approve bool, by, reason string) (*ApprovalResult, error) {
+	status := communication.ApprovalStatusRejected
+	if approve {
+		status = communication.ApprovalStatusApproved
+	}
+
+	req, err := o.approvals.Decide(id, status, by, reason)
+	if err != nil {
+		return nil, err
+	}
+	result := &ApprovalResult{Request: req}
+
+	detail := fmt.Sprintf("%s by %s", req.ID, by)
+	if reason != "" {
+		detail += ": " + reason
+	}
+
+	if approve {
+		err = o.recordTaskEvent(req.TaskID, req.DecidedAt, communication.TaskEventApproved, detail, func(t *communication.Task) {
+			if t.Status == communication.TaskStatusAwaitingApproval {
+				t.Status = communication.TaskStatusPending
+			}
+		})
+	} else {
+		err = o.recordTaskEvent(req.TaskID, req.DecidedAt, communication.TaskEventRejected, detail, func(t *communication.Task) {
+			t.Status = communication.TaskStatusFailed
+			t.Failure = failureRejected
+		})
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to update task %s: %w", req.TaskID, err)
+	}
+
+	message := fmt.Sprintf("%s（%s）は %s が承認しました", req.TaskID, req.ID, by)
+	if !approve {
+		message = fmt.Sprintf("%s（%s）は %s が却下したため failed（failure: rejected）にしました", req.TaskID, req.ID, by)
+	}
+	if reason != "" {
+		message += "。理由: " + reason
+	}
+
+	if approve && req.Kind == communication.ApprovalKindMerge && req.CommandID != "" {
+		report, err := o.MergeCommand(req.CommandID)
+		if err != nil {
+			message += fmt.Sprintf("。マージの再開に失敗しました: %v", err)
+			log.Printf("[approval] %s のマージの再開に失敗: %v", req.CommandID, err)
+		} else {
+			result.Merge = report
+			message += "。" + req.CommandID + " のマージを再開しました"
+		}
+	}
+
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeApprovalRequested, "bastion"); err != nil {
+		log.Printf("[approval] marshall への通知に失敗: %v", err)
+	}
+	return result, nil
+}
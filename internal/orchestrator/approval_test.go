@@ -0,0 +1,179 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestMatchApprovalPolicies(t *testing.T) {
+	cfg := config.Default().Intervention
+	cfg.RequireApproval = []string{"new_dependencies", "security_changes"}
+
+	policies, matches := matchApprovalPolicies(cfg, "ログインの認証を JWT に置き換える", []string{"internal/auth", "docs/README.md"})
+	if strings.Join(policies, ",") != "security_changes" {
+		t.Errorf("expected security_changes, got %v (%v)", policies, matches)
+	}
+	if strings.Join(matches, ",") != "security_changes: 認証,security_changes: internal/auth" {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+
+	policies, _ = matchApprovalPolicies(cfg, "", []string{"internal/security/hash.go", "web/package.json"})
+	if strings.Join(policies, ",") != "security_changes" {
+		t.Errorf("expected security_changes, got %v", policies)
+	}
+
+	// require_approval に挙げていない判定条件は使わない
+	if policies, _ := matchApprovalPolicies(cfg, "古いテーブルを削除する", nil); len(policies) != 0 {
+		t.Errorf("expected no policies, got %v", policies)
+	}
+}
+
+func TestDispatch_HoldsTaskForApproval(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Intervention.RequireApproval = []string{"security_changes"}
+	now := time.Now()
+
+	task := &communication.Task{TaskID: "task_001", CommandID: "cmd_001", Objective: "パスワードのハッシュ方式を変更", Status: communication.TaskStatusPending}
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	// 再実行しても承認の依頼は 1 件
+	for i := 0; i < 2; i++ {
+		if _, err := o.Dispatch(now); err != nil {
+			t.Fatalf("Dispatch failed: %v", err)
+		}
+	}
+
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusAwaitingApproval || got.Approval != "apr_001" {
+		t.Fatalf("task should await approval: %+v", got)
+	}
+	pending, _ := o.Approvals(false)
+	if len(pending) != 1 || pending[0].Kind != communication.ApprovalKindTask || pending[0].Policies[0] != "security_changes" {
+		t.Errorf("unexpected approvals: %+v", pending)
+	}
+	if messages, _ := o.inbox.Read(AgentEnvoy); len(messages) != 1 || !strings.Contains(messages[0].Message, "apr_001") {
+		t.Errorf("envoy should be asked once, got %+v", messages)
+	}
+
+	// 承認されたら pending に戻り、以後は同じ判定条件で止めない
+	result, err := o.DecideApproval("apr_001", true, "alice", "影響範囲を確認済み")
+	if err != nil {
+		t.Fatalf("DecideApproval failed: %v", err)
+	}
+	if result.Request.DecidedBy != "alice" || result.Request.DecisionReason != "影響範囲を確認済み" {
+		t.Errorf("decision should be recorded: %+v", result.Request)
+	}
+	got, _ = o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusPending {
+		t.Errorf("approved task should be pending: %+v", got)
+	}
+	if held, err := o.gateTask(*got, now); err != nil || held {
+		t.Errorf("approved task should not be held again: held=%v err=%v", held, err)
+	}
+}
+
+func TestDecideApproval_RejectCancelsTask(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Intervention.RequireApproval = []string{"destructive_changes"}
+
+	task := communication.Task{TaskID: "task_001", Objective: "古いテーブルを削除する", Status: communication.TaskStatusPending}
+	if err := o.tasks.Write(&task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if held, err := o.gateTask(task, time.Now()); err != nil || !held {
+		t.Fatalf("task should be held: held=%v err=%v", held, err)
+	}
+
+	if _, err := o.DecideApproval("apr_001", false, "bob", "本番データが消えるため"); err != nil {
+		t.Fatalf("DecideApproval failed: %v", err)
+	}
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusFailed || got.Failure != failureRejected {
+		t.Errorf("rejected task should fail: %+v", got)
+	}
+	if last := got.History[len(got.History)-1]; last.Event != communication.TaskEventRejected || !strings.Contains(last.Detail, "本番データが消えるため") {
+		t.Errorf("rejection should be recorded: %+v", last)
+	}
+
+	// 判断済みの承認は変更できない
+	if _, err := o.DecideApproval("apr_001", true, "bob", ""); err == nil {
+		t.Error("expected error for decided approval")
+	}
+}
+
+func TestMergeCommand_AwaitsApproval(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	o.config.Intervention.RequireApproval = []string{"new_dependencies"}
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+
+	// 依存を追加したブランチはマージ前に承認を依頼する
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001", CommandID: "cmd_001"}, "go.mod",
+		"module example.com/app\n\nrequire github.com/google/uuid v1.6.0\n")
+
+	report, err := o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if len(report.Merged) != 0 || len(report.Skipped) != 1 || report.Skipped[0].Reason != "awaiting approval: apr_001" {
+		t.Fatalf("merge should wait for approval: %+v", report)
+	}
+	pending, _ := o.Approvals(false)
+	if len(pending) != 1 || strings.Join(pending[0].Matches, ",") != "new_dependencies: added github.com/google/uuid (go.mod)" {
+		t.Errorf("added dependency should be reported: %+v", pending)
+	}
+
+	// 承認されたらマージを再開する
+	result, err := o.DecideApproval("apr_001", true, "alice", "")
+	if err != nil {
+		t.Fatalf("DecideApproval failed: %v", err)
+	}
+	if result.Merge == nil || len(result.Merge.Merged) != 1 || result.Merge.Merged[0].TaskID != "task_001" {
+		t.Errorf("merge should resume after approval: %+v", result.Merge)
+	}
+}
+
+func TestGateMerge_ApprovalBoundToKindAndCommit(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	o.config.Intervention.RequireApproval = []string{"new_dependencies"}
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+
+	task := &communication.Task{TaskID: "task_001", CommandID: "cmd_001"}
+	completeTaskOnBranch(t, o, sp1, task, "go.mod", "module example.com/app\n\nrequire github.com/google/uuid v1.6.0\n")
+	branch := parallel.TaskBranch("task_001")
+
+	// 割り当て前の承認はマージの承認にならない
+	if _, _, err := o.approvals.Request(communication.ApprovalRequest{Kind: communication.ApprovalKindTask, TaskID: "task_001", Policies: []string{"new_dependencies"}}); err != nil {
+		t.Fatalf("failed to request approval: %v", err)
+	}
+	if _, err := o.DecideApproval("apr_001", true, "alice", ""); err != nil {
+		t.Fatalf("DecideApproval failed: %v", err)
+	}
+	req, err := o.gateMerge(*task, branch)
+	if err != nil || req == nil || req.ID != "apr_002" {
+		t.Fatalf("merge should need its own approval: %+v err=%v", req, err)
+	}
+	head, _ := o.worktrees.Commit(branch)
+	if req.Commit != head {
+		t.Errorf("approval should record the branch head %s: %+v", head, req)
+	}
+	if _, err := o.approvals.Decide("apr_002", communication.ApprovalStatusApproved, "alice", ""); err != nil {
+		t.Fatalf("Decide failed: %v", err)
+	}
+	if req, err := o.gateMerge(*task, branch); err != nil || req != nil {
+		t.Fatalf("approved branch should merge: %+v err=%v", req, err)
+	}
+
+	// 承認後にブランチが進んだら承認し直す
+	completeTaskOnBranch(t, o, sp1, task, "go.mod", "module example.com/app\n\nrequire (\n\tgithub.com/google/uuid v1.6.0\n\tgithub.com/pkg/errors v0.9.1\n)\n")
+	req, err = o.gateMerge(*task, branch)
+	if err != nil || req == nil || req.ID != "apr_003" {
+		t.Errorf("new commits should need a new approval: %+v err=%v", req, err)
+	}
+}
@@ -0,0 +1,605 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 試行のタスクの ID（例: task_001_attempt_2）
+func attemptTaskID(taskID string, n int) string {
+	return fmt.Sprintf("%s_attempt_%d", taskID, n)
+}
+
+// 同じタスクのほかの試行か（試行同士は同時にマージしないため変更予定パスが重なってもよい）
+func isSiblingAttempt(taskID, parent string) bool {
+	return parent != "" && strings.HasPrefix(taskID, parent+"_attempt_")
+}
+
+// タスクのブランチ（記録がなければ既定のブランチ名）
+func taskBranchName(task communication.Task) string {
+	if task.Branch != "" {
+		return task.Branch
+	}
+	return parallel.TaskBranch(task.TaskID)
+}
+
+// タスクを並列試行に分けて試行のタスクを作成し、元のタスクは勝者が決まるまで in_progress にする
+func (o *Orchestrator) fanOutAttempts(task communication.Task, now time.Time) ([]communication.Task, error) {
+	n := task.Attempts
+	if n > o.config.Attempts.Max {
+		log.Printf("[attempts] %s の試行を上限の %d 回にします（attempts: %d）", task.TaskID, o.config.Attempts.Max, n)
+		n = o.config.Attempts.Max
+	}
+
+	attempts := make([]communication.Task, 0, n)
+	ids := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		attempt := communication.Task{
+			TaskID:       attemptTaskID(task.TaskID, i),
+			CommandID:    task.CommandID,
+			Objective:    task.Objective,
+			Deliverables: task.Deliverables,
+			Context: strings.TrimSpace(task.Context + fmt.Sprintf("\n\n並列試行 %d/%d: ほかの Specialist も別のブランチで同じタスクに取り組んでいます。"+
+				"すべての試行が終わると最も良い結果を採用します。", i, n)),
+			Paths:     task.Paths,
+			Priority:  task.Priority,
+			Timeout:   task.Timeout,
+			Status:    communication.TaskStatusPending,
+			Timestamp: now,
+			AttemptOf: task.TaskID,
+		}
+		if err := o.tasks.Write(&attempt); err != nil {
+			return nil, fmt.Errorf("failed to create attempt: %w", err)
+		}
+		attempts = append(attempts, attempt)
+		ids = append(ids, attempt.TaskID)
+	}
+
+	err := o.recordTaskEvent(task.TaskID, now, communication.TaskEventAttemptsStarted, strings.Join(ids, ", "), func(t *communication.Task) {
+		t.Status = communication.TaskStatusInProgress
+		t.Selection = &communication.AttemptSelection{
+			Tasks:     ids,
+			Criteria:  o.config.Attempts.Criteria,
+			StartedAt: now,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[attempts] %s を %d 個の試行に分けました", task.TaskID, n)
+	message := fmt.Sprintf("%s を %d 個の試行（%s）に分けて並列に実行します。すべて終わったら %s の順に比較して採用する試行を選びます",
+		task.TaskID, n, strings.Join(ids, ", "), strings.Join(o.config.Attempts.Criteria, " > "))
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeTaskAssigned, "bastion"); err != nil {
+		log.Printf("[attempts] marshall への通知に失敗: %v", err)
+	}
+	return attempts, nil
+}
+
+// 試行の完了報告の結果を元のタスクに記録し、すべての試行が終わっていれば勝者を選ぶ
+// 品質ゲートを使う場合はゲートの実行後のレポートを記録する
+func (o *Orchestrator) recordAttempt(report communication.Report) error {
+	if report.Status != communication.TaskStatusCompleted {
+		return nil
+	}
+	task, err := o.tasks.ReadByID(report.TaskID)
+	if err != nil || task.AttemptOf == "" {
+		return nil
+	}
+	if o.useGates() && !gatesRecorded(report) {
+		return nil
+	}
+
+	result := communication.AttemptResult{
+		Task:        task.TaskID,
+		Specialist:  report.SpecialistID,
+		Branch:      taskBranchName(*task),
+		Status:      communication.TaskStatusCompleted,
+		Summary:     report.Summary,
+		GatesPassed: len(failedGates(report.Gates)) == 0,
+		Tests:       report.Tests,
+		Coverage:    report.Coverage,
+		ReportedAt:  report.Timestamp,
+	}
+	for _, g := range report.Gates {
+		result.Gates = append(result.Gates, FormatGateResult(g))
+	}
+	if since := task.AssignedAt; !since.IsZero() && report.Timestamp.After(since) {
+		result.Duration = report.Timestamp.Sub(since).Round(time.Second)
+	}
+	if o.worktrees.IsRepository() && o.worktrees.BranchExists(result.Branch) {
+		if changed, err := o.worktrees.ChangedFiles(result.Branch); err == nil {
+			result.ChangedFiles = len(changed)
+		}
+	}
+
+	err = o.tasks.Update(task.AttemptOf, func(t *communication.Task) error {
+		if t.Selection == nil {
+			return fmt.Errorf("task has no attempts: %s", t.TaskID)
+		}
+		// 古いレポートで新しい結果を上書きしない
+		if r := t.Selection.Result(result.Task); r != nil && r.ReportedAt.After(result.ReportedAt) {
+			return nil
+		}
+		t.Selection.SetResult(result)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = o.selectAttempt(task.AttemptOf, time.Now(), false)
+	return err
+}
+
+// 並列試行の監視ループを実行
+func (o *Orchestrator) runAttemptMonitor() {
+	ticker := time.NewTicker(o.config.Attempts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case now := <-ticker.C:
+			tasks, err := o.tasks.Read()
+			if err != nil {
+				log.Printf("[attempts] タスクの読み込みに失敗: %v", err)
+				continue
+			}
+			o.checkAttempts(tasks, now)
+			if !o.config.Scheduler.Enabled {
+				o.checkUnscheduledAttempts(tasks, now)
+			}
+		}
+	}
+}
+
+// attempts を指定したタスクはスケジューラが試行に分けるため、無効なら Marshall に知らせる（タスクごとに 1 回）
+func (o *Orchestrator) checkUnscheduledAttempts(tasks []communication.Task, now time.Time) {
+	for _, task := range tasks {
+		if task.Attempts <= 1 || task.AttemptOf != "" || task.Selection != nil || task.Status != communication.TaskStatusPending {
+			continue
+		}
+		if hasTaskEvent(task, communication.TaskEventAttemptsUnscheduled) {
+			continue
+		}
+
+		detail := fmt.Sprintf("attempts: %d", task.Attempts)
+		if err := o.recordTaskEvent(task.TaskID, now, communication.TaskEventAttemptsUnscheduled, detail, nil); err != nil {
+			log.Printf("[attempts] %s の記録に失敗: %v", task.TaskID, err)
+			continue
+		}
+		log.Printf("[attempts] スケジューラが無効のため %s の並列試行を行いません", task.TaskID)
+		message := fmt.Sprintf("%s は attempts: %d を指定していますが、スケジューラが無効のため並列試行は行いません。"+
+			"scheduler.enabled を true にするか、1 つのタスクとして空いている Specialist に割り当ててください", task.TaskID, task.Attempts)
+		if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion"); err != nil {
+			log.Printf("[attempts] marshall への通知に失敗: %v", err)
+		}
+	}
+}
+
+// タスクの履歴に event があるか
+func hasTaskEvent(task communication.Task, event string) bool {
+	for _, e := range task.History {
+		if e.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// 勝者が決まっていない並列試行を確認し、揃っていれば勝者を選ぶ
+func (o *Orchestrator) checkAttempts(tasks []communication.Task, now time.Time) {
+	for _, task := range tasks {
+		if task.Selection == nil || task.Selection.Winner != "" || task.IsFinished() {
+			continue
+		}
+		if _, err := o.selectAttempt(task.TaskID, now, false); err != nil {
+			log.Printf("[attempts] %s の勝者の選択に失敗: %v", task.TaskID, err)
+		}
+	}
+}
+
+// 並列試行の状態
+func (o *Orchestrator) Attempts(taskID string) (*communication.AttemptSelection, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Selection == nil {
+		return nil, fmt.Errorf("task has no attempts: %s", taskID)
+	}
+	return task.Selection, nil
+}
+
+// 評価・カバレッジを待たずに並列試行の勝者を選ぶ（すべての試行が終わっている必要がある）
+func (o *Orchestrator) SelectAttempt(taskID string) (*communication.AttemptSelection, error) {
+	return o.selectAttempt(taskID, time.Now(), true)
+}
+
+// すべての試行が終わっていれば基準に従って勝者を選び、元のタスクを勝者のブランチで completed にする
+// criteria の evaluation / coverage の結果が揃うまでは attempts.wait だけ待つ（force なら待たない）
+// 同じタスクの選択が実行中なら nil を返す
+func (o *Orchestrator) selectAttempt(taskID string, now time.Time, force bool) (*communication.AttemptSelection, error) {
+	o.mu.Lock()
+	if o.selecting[taskID] {
+		o.mu.Unlock()
+		return nil, nil
+	}
+	o.selecting[taskID] = true
+	o.mu.Unlock()
+	defer func() {
+		o.mu.Lock()
+		delete(o.selecting, taskID)
+		o.mu.Unlock()
+	}()
+
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	sel := task.Selection
+	if sel == nil {
+		return nil, fmt.Errorf("task has no attempts: %s", taskID)
+	}
+	if sel.Winner != "" || task.IsFinished() {
+		return sel, nil
+	}
+
+	results, running, waiting, err := o.attemptResults(*sel, force)
+	if err != nil {
+		return nil, err
+	}
+	if len(running) > 0 {
+		if force {
+			return nil, fmt.Errorf("attempts still running: %s", strings.Join(running, ", "))
+		}
+		return sel, nil
+	}
+
+	if len(waiting) > 0 && !force {
+		if sel.FinishedAt.IsZero() {
+			return sel, o.waitForAttemptResults(taskID, waiting, now)
+		}
+		if now.Sub(sel.FinishedAt) < o.config.Attempts.Wait {
+			return sel, nil
+		}
+		log.Printf("[attempts] %s の %s を待ちきれなかったため揃った結果で選びます", taskID, strings.Join(waiting, ", "))
+	}
+
+	var completed []communication.AttemptResult
+	for _, r := range results {
+		if r.Status == communication.TaskStatusCompleted {
+			completed = append(completed, r)
+		}
+	}
+	winner := ""
+	reason := "all attempts failed"
+	if len(completed) > 0 {
+		var ranked []communication.AttemptResult
+		ranked, reason = rankAttempts(completed, o.config.Attempts.Criteria)
+		winner = ranked[0].Task
+	}
+
+	// 選ばれなかった試行のブランチを退避
+	for i := range results {
+		if results[i].Task != winner {
+			results[i].ArchivedBranch = o.archiveAttempt(results[i])
+		}
+	}
+
+	var winnerTask *communication.Task
+	if winner != "" {
+		if winnerTask, err = o.tasks.ReadByID(winner); err != nil {
+			return nil, err
+		}
+	}
+
+	event := communication.TaskEventAttemptSelected
+	detail := winner + ": " + reason
+	if winner == "" {
+		event = communication.TaskEventAttemptsFailed
+		detail = reason
+	}
+	var selected *communication.AttemptSelection
+	err = o.recordTaskEvent(taskID, now, event, detail, func(t *communication.Task) {
+		s := t.Selection
+		s.Results = results
+		s.Winner = winner
+		s.Criteria = o.config.Attempts.Criteria
+		s.Reason = reason
+		if s.FinishedAt.IsZero() {
+			s.FinishedAt = now
+		}
+		s.SelectedAt = now
+		if winnerTask == nil {
+			t.Status = communication.TaskStatusFailed
+			t.Failure = communication.TaskEventAttemptsFailed
+		} else {
+			t.Status = communication.TaskStatusCompleted
+			t.SpecialistID = winnerTask.SpecialistID
+			t.Branch = taskBranchName(*winnerTask)
+			t.Worktree = winnerTask.Worktree
+		}
+		copied := *s
+		selected = &copied
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var message string
+	if winnerTask == nil {
+		log.Printf("[attempts] %s の試行はすべて失敗しました", taskID)
+		message = fmt.Sprintf("%s の試行（%s）はすべて失敗したため %s を failed にしました。タスクを見直してください",
+			taskID, strings.Join(sel.Tasks, ", "), taskID)
+	} else {
+		log.Printf("[attempts] %s の勝者は %s です（%s）", taskID, winner, reason)
+		message = fmt.Sprintf("%s の並列試行から %s（%s）を採用しました（%s）。ほかの試行のブランチは %s 以下に退避しました",
+			taskID, winner, winnerTask.SpecialistID, reason, parallel.BranchPrefix+"archive/")
+	}
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion"); err != nil {
+		log.Printf("[attempts] marshall への通知に失敗: %v", err)
+	}
+
+	// 試行のタスクはレビューしないため、勝者のブランチで元のタスクのレビューを依頼する
+	if winnerTask != nil && o.config.Review.Enabled {
+		if err := o.reviewAttemptWinner(taskID, *winnerTask, results); err != nil {
+			log.Printf("[review] %s のレビューの依頼に失敗: %v", taskID, err)
+		}
+	}
+	return selected, nil
+}
+
+// 勝者を選んだ元のタスクのレビューを依頼する
+func (o *Orchestrator) reviewAttemptWinner(taskID string, winner communication.Task, results []communication.AttemptResult) error {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return err
+	}
+	if task.Review != nil {
+		return nil
+	}
+	summary := ""
+	for _, r := range results {
+		if r.Task == winner.TaskID {
+			summary = r.Summary
+		}
+	}
+	return o.startReview(task, winner.SpecialistID, summary)
+}
+
+// 試行ごとの結果（評価のスコアを含む）を集める
+// running はまだ終わっていない試行（force でなければ完了報告の記録待ちを含む）、waiting は揃っていない criteria の結果
+func (o *Orchestrator) attemptResults(sel communication.AttemptSelection, force bool) (results []communication.AttemptResult, running, waiting []string, err error) {
+	criteria := make(map[string]bool)
+	for _, c := range sel.Criteria {
+		criteria[c] = true
+	}
+
+	for _, id := range sel.Tasks {
+		attempt, err := o.tasks.ReadByID(id)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !attempt.IsFinished() {
+			running = append(running, id)
+			continue
+		}
+
+		result := communication.AttemptResult{
+			Task:       id,
+			Specialist: attempt.SpecialistID,
+			Branch:     taskBranchName(*attempt),
+			Status:     attempt.Status,
+			// 完了報告の結果がない場合はゲートを成功として扱う
+			GatesPassed: true,
+		}
+		if r := sel.Result(id); r != nil {
+			result = *r
+			result.Status = attempt.Status
+		} else if attempt.Status == communication.TaskStatusCompleted && !force {
+			// 完了報告（品質ゲートを使う場合はゲートの結果）の記録を待つ
+			running = append(running, id)
+			continue
+		}
+
+		if attempt.Status == communication.TaskStatusCompleted {
+			if eval, err := o.evaluations.Read(id); err == nil {
+				total := eval.Scores.Total()
+				result.Score = &total
+			} else if criteria[config.AttemptCriterionEvaluation] && !containsString(waiting, config.AttemptCriterionEvaluation) {
+				waiting = append(waiting, config.AttemptCriterionEvaluation)
+			}
+			if criteria[config.AttemptCriterionCoverage] && o.config.Coverage.Enabled && result.GatesPassed && result.Coverage == nil &&
+				!containsString(waiting, config.AttemptCriterionCoverage) {
+				waiting = append(waiting, config.AttemptCriterionCoverage)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, running, waiting, nil
+}
+
+// すべての試行が終わった時刻を記録し、評価を待つ場合は Marshall に評価を依頼する
+func (o *Orchestrator) waitForAttemptResults(taskID string, waiting []string, now time.Time) error {
+	var ids []string
+	err := o.tasks.Update(taskID, func(t *communication.Task) error {
+		t.Selection.FinishedAt = now
+		ids = t.Selection.Tasks
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[attempts] %s の試行がすべて終わりました。%s を待っています", taskID, strings.Join(waiting, ", "))
+	if !containsString(waiting, config.AttemptCriterionEvaluation) {
+		return nil
+	}
+	message := fmt.Sprintf("%s の試行（%s）がすべて終わりました。試行ごとの評価（knowledge/evaluations/eval_<試行のタスク>.yaml）を書いてください。"+
+		"%s 後には揃った結果で採用する試行を選びます", taskID, strings.Join(ids, ", "), o.config.Attempts.Wait)
+	return o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion")
+}
+
+// 選ばれなかった試行のブランチを退避する（退避先を返す。ブランチがなければ空）
+// 担当の worktree がブランチを使用中なら Specialist 用のブランチに戻してから退避する
+func (o *Orchestrator) archiveAttempt(result communication.AttemptResult) string {
+	if !o.worktrees.IsRepository() || !o.worktrees.BranchExists(result.Branch) {
+		return ""
+	}
+	if err := o.releaseBranch(result.Specialist, result.Branch); err != nil {
+		log.Printf("[attempts] %s の worktree を戻せないため %s を退避しません: %v", result.Specialist, result.Branch, err)
+		return ""
+	}
+
+	archived, err := o.worktrees.ArchiveBranch(result.Branch)
+	if err != nil {
+		log.Printf("[attempts] %s の退避に失敗: %v", result.Branch, err)
+	}
+	return archived
+}
+
+// 試行を基準の順に比較して良い順に並べ、1 位と 2 位の差がついた基準を理由として返す
+// すべての基準で並んだ場合は先に作成した試行を優先する
+func rankAttempts(results []communication.AttemptResult, criteria []string) ([]communication.AttemptResult, string) {
+	ranked := append([]communication.AttemptResult(nil), results...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		for _, c := range criteria {
+			if d := compareAttempts(c, ranked[i], ranked[j]); d != 0 {
+				return d < 0
+			}
+		}
+		return false
+	})
+
+	if len(ranked) == 1 {
+		return ranked, "only completed attempt"
+	}
+	for _, c := range criteria {
+		if compareAttempts(c, ranked[0], ranked[1]) != 0 {
+			return ranked, fmt.Sprintf("%s: %s vs %s", c, attemptValue(c, ranked[0]), attemptValue(c, ranked[1]))
+		}
+	}
+	return ranked, "tie: first attempt"
+}
+
+// 基準で 2 つの試行を比較（a が良ければ負、b が良ければ正）
+func compareAttempts(criterion string, a, b communication.AttemptResult) int {
+	switch criterion {
+	case config.AttemptCriterionGates:
+		return compareFloat(boolScore(b.GatesPassed), boolScore(a.GatesPassed))
+	case config.AttemptCriterionTests:
+		return compareFloat(failureRate(a), failureRate(b))
+	case config.AttemptCriterionEvaluation:
+		return compareFloat(float64(scoreOf(b)), float64(scoreOf(a)))
+	case config.AttemptCriterionCoverage:
+		return compareFloat(coverageDelta(b), coverageDelta(a))
+	case config.AttemptCriterionChanges:
+		return compareFloat(float64(a.ChangedFiles), float64(b.ChangedFiles))
+	case config.AttemptCriterionDuration:
+		return compareFloat(durationOf(a), durationOf(b))
+	}
+	return 0
+}
+
+// 試行の結果の基準ごとの表示（例: "gates=passed, tests=0/3 failed"）
+func FormatAttemptResult(r communication.AttemptResult, criteria []string) string {
+	values := make([]string, 0, len(criteria))
+	for _, c := range criteria {
+		values = append(values, c+"="+attemptValue(c, r))
+	}
+	return strings.Join(values, ", ")
+}
+
+// 基準での試行の値の表示
+func attemptValue(criterion string, r communication.AttemptResult) string {
+	switch criterion {
+	case config.AttemptCriterionGates:
+		if r.GatesPassed {
+			return "passed"
+		}
+		return "failed"
+	case config.AttemptCriterionTests:
+		if r.Tests == nil {
+			return "no results"
+		}
+		return fmt.Sprintf("%d/%d failed", r.Tests.Failed, r.Tests.Total())
+	case config.AttemptCriterionEvaluation:
+		if r.Score == nil {
+			return "not evaluated"
+		}
+		return fmt.Sprintf("score %d", *r.Score)
+	case config.AttemptCriterionCoverage:
+		if r.Coverage == nil {
+			return "not measured"
+		}
+		return fmt.Sprintf("%+.1f", r.Coverage.Delta)
+	case config.AttemptCriterionChanges:
+		return fmt.Sprintf("%d files", r.ChangedFiles)
+	case config.AttemptCriterionDuration:
+		if r.Duration == 0 {
+			return "unknown"
+		}
+		return r.Duration.String()
+	}
+	return ""
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolScore(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// 失敗したテストの割合（テスト結果がなければ最も悪い値として扱う）
+func failureRate(r communication.AttemptResult) float64 {
+	if r.Tests == nil || r.Tests.Total() == 0 {
+		return math.Inf(1)
+	}
+	return float64(r.Tests.Failed) / float64(r.Tests.Total())
+}
+
+// 評価のスコアの合計（評価がなければ -1）
+func scoreOf(r communication.AttemptResult) int {
+	if r.Score == nil {
+		return -1
+	}
+	return *r.Score
+}
+
+// カバレッジの変化（計測していなければ 0）
+func coverageDelta(r communication.AttemptResult) float64 {
+	if r.Coverage == nil {
+		return 0
+	}
+	return r.Coverage.Delta
+}
+
+// 所要時間（不明なら最も遅いものとして扱う）
+func durationOf(r communication.AttemptResult) float64 {
+	if r.Duration == 0 {
+		return float64(1<<63 - 1)
+	}
+	return float64(r.Duration)
+}
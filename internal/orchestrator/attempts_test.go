@@ -0,0 +1,258 @@
+package orchestrator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/evaluation"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestRankAttempts(t *testing.T) {
+	score := func(n int) *int { return &n }
+	results := []communication.AttemptResult{
+		{Task: "a1", GatesPassed: false, Duration: time.Minute},
+		{Task: "a2", GatesPassed: true, Tests: &analysis.TestResults{Passed: 9, Failed: 1}, Score: score(12), Duration: 3 * time.Minute},
+		{Task: "a3", GatesPassed: true, Tests: &analysis.TestResults{Passed: 10}, Score: score(10), Duration: 2 * time.Minute},
+	}
+
+	for _, tc := range []struct {
+		criteria []string
+		order    []string
+		reason   string
+	}{
+		{
+			criteria: []string{config.AttemptCriterionGates, config.AttemptCriterionTests},
+			order:    []string{"a3", "a2", "a1"},
+			reason:   "tests: 0/10 failed vs 1/10 failed",
+		},
+		{
+			// テスト結果のない試行は最も悪い
+			criteria: []string{config.AttemptCriterionTests},
+			order:    []string{"a3", "a2", "a1"},
+			reason:   "tests: 0/10 failed vs 1/10 failed",
+		},
+		{
+			criteria: []string{config.AttemptCriterionEvaluation},
+			order:    []string{"a2", "a3", "a1"},
+			reason:   "evaluation: score 12 vs score 10",
+		},
+		{
+			criteria: []string{config.AttemptCriterionDuration},
+			order:    []string{"a1", "a3", "a2"},
+			reason:   "duration: 1m0s vs 2m0s",
+		},
+		{
+			criteria: []string{config.AttemptCriterionChanges},
+			order:    []string{"a1", "a2", "a3"},
+			reason:   "tie: first attempt",
+		},
+	} {
+		ranked, reason := rankAttempts(results, tc.criteria)
+		var order []string
+		for _, r := range ranked {
+			order = append(order, r.Task)
+		}
+		if !reflect.DeepEqual(order, tc.order) || reason != tc.reason {
+			t.Errorf("criteria %v: got %v (%s), want %v (%s)", tc.criteria, order, reason, tc.order, tc.reason)
+		}
+	}
+}
+
+func TestAttempts_SelectWinner(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	sp2 := registerWorktreeSpecialist(t, o, 2)
+	o.config.Attempts.Criteria = []string{config.AttemptCriterionGates, config.AttemptCriterionTests}
+	o.config.Review.Enabled = true
+	now := time.Now()
+
+	parent := &communication.Task{TaskID: "task_001", CommandID: "cmd_001", Objective: "ログイン機能", Attempts: 5, Status: communication.TaskStatusPending}
+	if err := o.tasks.Write(parent); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	o.config.Attempts.Max = 2
+	attempts, err := o.fanOutAttempts(*parent, now)
+	if err != nil {
+		t.Fatalf("fanOutAttempts failed: %v", err)
+	}
+	if len(attempts) != 2 || attempts[1].TaskID != "task_001_attempt_2" || attempts[1].AttemptOf != "task_001" || attempts[1].Status != communication.TaskStatusPending {
+		t.Fatalf("attempts should be capped and pending: %+v", attempts)
+	}
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusInProgress || got.Selection == nil || len(got.Selection.Tasks) != 2 {
+		t.Fatalf("parent should wait for the attempts: %+v", got)
+	}
+
+	// 1 つ目の試行はテストが失敗、2 つ目は成功
+	for i, sp := range []string{sp1, sp2} {
+		attempt := attempts[i]
+		attempt.AssignedAt = now
+		completeTaskOnBranch(t, o, sp, &attempt, "login.go", "package app\n// attempt "+attempt.TaskID+"\n")
+	}
+	reports := []communication.Report{
+		{TaskID: "task_001_attempt_1", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Timestamp: now.Add(time.Minute),
+			Tests: &analysis.TestResults{Passed: 2, Failed: 1}},
+		{TaskID: "task_001_attempt_2", SpecialistID: sp2, Status: communication.TaskStatusCompleted, Timestamp: now.Add(2 * time.Minute),
+			Tests: &analysis.TestResults{Passed: 3}},
+	}
+
+	if err := o.recordAttempt(reports[0]); err != nil {
+		t.Fatalf("recordAttempt failed: %v", err)
+	}
+	if got, _ := o.tasks.ReadByID("task_001"); got.Selection.Winner != "" || len(got.Selection.Results) != 1 {
+		t.Fatalf("winner should wait for every attempt: %+v", got.Selection)
+	}
+	if err := o.recordAttempt(reports[1]); err != nil {
+		t.Fatalf("recordAttempt failed: %v", err)
+	}
+
+	got, _ = o.tasks.ReadByID("task_001")
+	sel := got.Selection
+	if sel.Winner != "task_001_attempt_2" || sel.Reason != "tests: 0/3 failed vs 1/3 failed" {
+		t.Fatalf("attempt 2 should win: %+v", sel)
+	}
+	if got.Status != communication.TaskStatusCompleted || got.SpecialistID != sp2 || got.Branch != parallel.TaskBranch("task_001_attempt_2") {
+		t.Errorf("parent should take over the winning branch: %+v", got)
+	}
+	loser := sel.Result("task_001_attempt_1")
+	if loser == nil || loser.ArchivedBranch != "bastion/archive/task/task_001_attempt_1" || loser.Duration != time.Minute {
+		t.Errorf("losing attempt should be archived: %+v", loser)
+	}
+	if o.worktrees.BranchExists(parallel.TaskBranch("task_001_attempt_1")) || !o.worktrees.BranchExists(loser.ArchivedBranch) {
+		t.Error("losing branch should be moved to the archive")
+	}
+	if n := countMarshallMessages(t, o, "task_001 の並列試行から task_001_attempt_2"); n != 1 {
+		t.Errorf("marshall should be notified once, got %d", n)
+	}
+
+	// 試行はレビューしないため、元のタスクを勝者の担当以外にレビューしてもらう
+	review, err := o.tasks.ReadByID("task_001_review")
+	if err != nil || review.ReviewOf != "task_001" || strings.Join(review.ExcludedSpecialists, ",") != sp2 {
+		t.Fatalf("parent should be reviewed by someone other than the winner: %+v err=%v", review, err)
+	}
+	if _, err := o.ApproveReview("task_001", "alice"); err != nil {
+		t.Fatalf("ApproveReview failed: %v", err)
+	}
+
+	// 元のタスクを勝者のブランチでマージする
+	if tasks, _ := o.commandTasks("cmd_001"); len(tasks) != 1 || tasks[0].TaskID != "task_001" {
+		t.Errorf("attempts should not be command tasks: %+v", tasks)
+	}
+	report, err := o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if len(report.Merged) != 1 || report.Merged[0].Branch != parallel.TaskBranch("task_001_attempt_2") {
+		t.Errorf("winning branch should be merged: %+v", report)
+	}
+}
+
+func TestAttempts_WaitForEvaluations(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Attempts.Criteria = []string{config.AttemptCriterionEvaluation}
+	now := time.Now()
+
+	parent := communication.Task{TaskID: "task_001", Attempts: 2, Status: communication.TaskStatusPending}
+	if err := o.tasks.Write(&parent); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	attempts, err := o.fanOutAttempts(parent, now)
+	if err != nil {
+		t.Fatalf("fanOutAttempts failed: %v", err)
+	}
+	for i := range attempts {
+		attempts[i].SpecialistID = agentName(AgentSpecialist, i+1)
+		attempts[i].Status = communication.TaskStatusCompleted
+		if err := o.tasks.Write(&attempts[i]); err != nil {
+			t.Fatalf("failed to write task: %v", err)
+		}
+		if err := o.recordAttempt(communication.Report{TaskID: attempts[i].TaskID, SpecialistID: attempts[i].SpecialistID,
+			Status: communication.TaskStatusCompleted, Timestamp: now}); err != nil {
+			t.Fatalf("recordAttempt failed: %v", err)
+		}
+	}
+
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Selection.Winner != "" || got.Selection.FinishedAt.IsZero() {
+		t.Fatalf("selection should wait for evaluations: %+v", got.Selection)
+	}
+	if n := countMarshallMessages(t, o, "試行ごとの評価"); n != 1 {
+		t.Errorf("marshall should be asked for evaluations, got %d", n)
+	}
+
+	for id, correctness := range map[string]int{"task_001_attempt_1": 3, "task_001_attempt_2": 5} {
+		if err := o.evaluations.Write(&evaluation.Evaluation{TaskID: id, Scores: evaluation.Scores{Correctness: correctness}}); err != nil {
+			t.Fatalf("failed to write evaluation: %v", err)
+		}
+	}
+	if err := o.handleEvaluationChange(o.evaluations.Path("task_001_attempt_2")); err != nil {
+		t.Fatalf("handleEvaluationChange failed: %v", err)
+	}
+	if got, _ := o.tasks.ReadByID("task_001"); got.Selection.Winner != "task_001_attempt_2" || got.Status != communication.TaskStatusCompleted {
+		t.Errorf("higher evaluation should win: %+v", got.Selection)
+	}
+}
+
+func TestSelectAttempt_AllFailed(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	parent := communication.Task{TaskID: "task_001", Attempts: 2, Status: communication.TaskStatusPending}
+	if err := o.tasks.Write(&parent); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	attempts, err := o.fanOutAttempts(parent, time.Now())
+	if err != nil {
+		t.Fatalf("fanOutAttempts failed: %v", err)
+	}
+	if _, err := o.SelectAttempt("task_001"); err == nil {
+		t.Error("selection should fail while attempts are running")
+	}
+
+	for i := range attempts {
+		attempts[i].Status = communication.TaskStatusFailed
+		if err := o.tasks.Write(&attempts[i]); err != nil {
+			t.Fatalf("failed to write task: %v", err)
+		}
+	}
+	sel, err := o.SelectAttempt("task_001")
+	if err != nil {
+		t.Fatalf("SelectAttempt failed: %v", err)
+	}
+	got, _ := o.tasks.ReadByID("task_001")
+	if sel.Winner != "" || got.Status != communication.TaskStatusFailed || got.Failure != communication.TaskEventAttemptsFailed {
+		t.Errorf("parent should fail when every attempt failed: %+v %+v", got, sel)
+	}
+}
+
+func TestCheckUnscheduledAttempts(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	now := time.Now()
+
+	for _, task := range []communication.Task{
+		{TaskID: "task_001", Attempts: 3, Status: communication.TaskStatusPending},
+		{TaskID: "task_002", Status: communication.TaskStatusPending},
+	} {
+		if err := o.tasks.Write(&task); err != nil {
+			t.Fatalf("failed to write task: %v", err)
+		}
+	}
+
+	// 確認のたびに届いても通知はタスクごとに 1 回
+	for i := 0; i < 2; i++ {
+		tasks, _ := o.tasks.Read()
+		o.checkUnscheduledAttempts(tasks, now)
+	}
+	if n := countMarshallMessages(t, o, "スケジューラが無効のため並列試行は行いません"); n != 1 {
+		t.Errorf("marshall should be notified once, got %d", n)
+	}
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusPending || got.Selection != nil || !hasTaskEvent(*got, communication.TaskEventAttemptsUnscheduled) {
+		t.Errorf("task should stay pending without attempts: %+v", got)
+	}
+}
@@ -0,0 +1,179 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// カバレッジを計測する一時的な worktree の名前の接頭辞
+const coverageWorktreePrefix = "coverage_"
+
+// カバレッジを計測する一時的な worktree の名前
+func coverageWorktreeName(taskID, side string) string {
+	return coverageWorktreePrefix + taskID + "_" + side
+}
+
+// コミットを一時的な worktree に checkout してカバレッジを計測
+// テストが失敗してもカバレッジが書き出されていれば使う
+func (o *Orchestrator) measureCoverage(taskID, side, rev string) (analysis.CoverageProfile, error) {
+	name := coverageWorktreeName(taskID, side)
+	dir, err := o.worktrees.Checkout(name, rev)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := o.worktrees.Discard(name); err != nil {
+			log.Printf("[coverage] %s の削除に失敗: %v", name, err)
+		}
+	}()
+
+	outDir, err := os.MkdirTemp("", "bastion-coverage-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(outDir)
+	profile := filepath.Join(outDir, "coverage.out")
+
+	cfg := o.config.Coverage
+	env := append(os.Environ(),
+		"BASTION_PROJECT_ROOT="+o.projectRoot,
+		"BASTION_WORKTREE="+dir,
+		"BASTION_TASK_ID="+taskID,
+		"BASTION_COVERAGE_PROFILE="+profile,
+	)
+	log.Printf("[coverage] %s: %s（%s）のカバレッジを計測しています...", taskID, side, rev)
+	result := o.runGate(dir, config.GateCommand{Name: "coverage", Run: cfg.Command, Timeout: cfg.Timeout}, env)
+
+	content, err := os.ReadFile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("coverage was not written for %s (%s): %s", side, FormatGateResult(result), result.Output)
+	}
+	return analysis.ParseCoverage(cfg.Format, content, dir)
+}
+
+// タスクのブランチの分岐点と先頭でカバレッジを計測して比較し、基準を下回った理由を記録する
+func (o *Orchestrator) MeasureCoverage(taskID string) (*analysis.CoverageReport, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := task.Branch
+	if branch == "" {
+		branch = parallel.TaskBranch(task.TaskID)
+	}
+	if !o.worktrees.IsRepository() || !o.worktrees.BranchExists(branch) {
+		return nil, fmt.Errorf("branch not found: %s", branch)
+	}
+	base, err := o.worktrees.MergeBase(branch)
+	if err != nil {
+		return nil, err
+	}
+	head, err := o.worktrees.Commit(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := o.measureCoverage(taskID, "base", base)
+	if err != nil {
+		return nil, err
+	}
+	after, err := o.measureCoverage(taskID, "head", head)
+	if err != nil {
+		return nil, err
+	}
+
+	report := analysis.CompareCoverage(before, after)
+	report.Format = o.config.Coverage.Format
+	report.BaseCommit = base
+	report.HeadCommit = head
+	report.Flag(o.config.Coverage.MaxDrop, o.config.Coverage.MinCoverage)
+	return &report, nil
+}
+
+// カバレッジの変化の表示（例: "72.0% -> 70.5% (-1.5)"）
+func FormatCoverage(r analysis.CoverageReport) string {
+	return fmt.Sprintf("%.1f%% -> %.1f%% (%+.1f)", r.Before, r.After, r.Delta)
+}
+
+// 完了報告のカバレッジをバックグラウンドで比較し、終わったら以降の段階を再開する
+// 品質ゲートを使う場合はゲートがすべて成功したレポートだけを比較する
+// 比較の結果を待つ（比較を始めたか、同じタスクの比較が実行中）なら true
+func (o *Orchestrator) startCoverage(report communication.Report) bool {
+	if !o.config.Coverage.Enabled || report.Status != communication.TaskStatusCompleted || report.Coverage != nil {
+		return false
+	}
+	if o.useGates() && (!gatesRecorded(report) || len(failedGates(report.Gates)) > 0) {
+		return false
+	}
+
+	o.mu.Lock()
+	if o.covering[report.TaskID] {
+		o.mu.Unlock()
+		return true
+	}
+	o.covering[report.TaskID] = true
+	o.mu.Unlock()
+
+	go func() {
+		err := o.coverReport(report)
+		o.mu.Lock()
+		delete(o.covering, report.TaskID)
+		o.mu.Unlock()
+		if err != nil {
+			log.Printf("[coverage] %s のカバレッジの比較に失敗: %v", report.TaskID, err)
+		}
+		o.resumeReport(report, "coverage", "review_request")
+	}()
+	return true
+}
+
+// 完了報告のカバレッジを比較してレポートと評価に記録し、基準を下回っていれば Marshall に通知
+func (o *Orchestrator) coverReport(report communication.Report) error {
+	coverage, err := o.MeasureCoverage(report.TaskID)
+	if err != nil {
+		return err
+	}
+
+	// 計測中に新しいレポートが書かれていれば結果は捨てる
+	current, err := o.reports.ReadFile(o.reports.Path(report.SpecialistID))
+	if err != nil {
+		return err
+	}
+	if current.TaskID != report.TaskID || !current.Timestamp.Equal(report.Timestamp) {
+		log.Printf("[coverage] %s のレポートが更新されたため結果を破棄します", report.TaskID)
+		return nil
+	}
+	current.Coverage = coverage
+	if err := o.writeReport(current); err != nil {
+		return err
+	}
+	if err := o.applyReportToEvaluation(*current); err != nil {
+		log.Printf("[coverage] %s の評価への反映に失敗: %v", report.TaskID, err)
+	}
+
+	if len(coverage.Flags) == 0 {
+		log.Printf("[coverage] %s のカバレッジ: %s", report.TaskID, FormatCoverage(*coverage))
+		return nil
+	}
+
+	detail := strings.Join(coverage.Flags, ", ")
+	log.Printf("[coverage] %s のカバレッジが基準を下回りました: %s", report.TaskID, detail)
+	if err := o.recordTaskEvent(report.TaskID, time.Now(), communication.TaskEventCoverageDropped, detail, nil); err != nil {
+		return err
+	}
+	message := fmt.Sprintf("%s でカバレッジが下がりました（%s）: %s。テストの追加を検討してください", report.TaskID, FormatCoverage(*coverage), detail)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeCoverageDropped, "bastion"); err != nil {
+		log.Printf("[coverage] marshall への通知に失敗: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/evaluation"
+)
+
+// app.go があれば b.go の分だけカバレッジが下がるプロファイルを書き出すコマンド
+const testCoverageCommand = `{
+  echo 'mode: set'
+  echo 'example.com/app/a.go:1.1,2.1 10 1'
+  if [ -f app.go ]; then echo 'example.com/app/b/b.go:1.1,2.1 10 0'; fi
+} > "$BASTION_COVERAGE_PROFILE"`
+
+func TestCoverReport(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	o.config.Coverage.Enabled = true
+	o.config.Coverage.Command = testCoverageCommand
+
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001", CommandID: "cmd_001"}, "app.go", "package app\n")
+	report := &communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Timestamp: time.Now()}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if err := o.evaluations.Write(&evaluation.Evaluation{TaskID: "task_001", Evaluator: "marshall", Scores: evaluation.Scores{Correctness: 5}}); err != nil {
+		t.Fatalf("failed to write evaluation: %v", err)
+	}
+
+	if err := o.coverReport(*report); err != nil {
+		t.Fatalf("coverReport failed: %v", err)
+	}
+
+	got, _ := o.reports.ReadByTaskID("task_001")
+	coverage := got.Coverage
+	if coverage == nil || coverage.Before != 100 || coverage.After != 50 || coverage.Delta != -50 || coverage.BaseCommit == coverage.HeadCommit {
+		t.Fatalf("coverage should be recorded: %+v", coverage)
+	}
+	if len(coverage.Packages) != 1 || coverage.Packages[0].Package != "example.com/app/b" || !coverage.Packages[0].Added {
+		t.Errorf("added package should be listed: %+v", coverage.Packages)
+	}
+	if len(coverage.Flags) != 1 || coverage.Flags[0] != "total: 100.0% -> 50.0% (-50.0)" {
+		t.Errorf("drop should be flagged: %q", coverage.Flags)
+	}
+
+	if eval, _ := o.evaluations.Read("task_001"); eval.Coverage == nil || eval.Coverage.Delta != -50 {
+		t.Errorf("coverage should be attached to the evaluation: %+v", eval)
+	}
+	task, _ := o.tasks.ReadByID("task_001")
+	if last := task.History[len(task.History)-1]; last.Event != communication.TaskEventCoverageDropped {
+		t.Errorf("drop should be recorded: %+v", last)
+	}
+	if n := countMarshallMessages(t, o, "task_001 でカバレッジが下がりました"); n != 1 {
+		t.Errorf("marshall should be notified once, got %d", n)
+	}
+	// 計測用の worktree は残さない
+	if o.worktrees.Exists(coverageWorktreeName("task_001", "base")) || o.worktrees.Exists(coverageWorktreeName("task_001", "head")) {
+		t.Error("coverage worktrees should be removed")
+	}
+}
+
+func TestMeasureCoverage_Errors(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.MeasureCoverage("task_001"); err == nil || !strings.Contains(err.Error(), "branch not found") {
+		t.Errorf("expected error without a branch: %v", err)
+	}
+
+	// カバレッジを書き出さないコマンドはエラー
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_002"}, "app.go", "package app\n")
+	o.config.Coverage.Command = "echo 'no tests' >&2; exit 1"
+	if _, err := o.MeasureCoverage("task_002"); err == nil || !strings.Contains(err.Error(), "no tests") {
+		t.Errorf("expected error with the command output: %v", err)
+	}
+}
+
+func TestStartCoverage_WaitsForGates(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Coverage.Enabled = true
+	o.config.Gates.Enabled = true
+	o.config.Gates.Commands = []config.GateCommand{{Name: "test", Run: "true"}}
+
+	report := communication.Report{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted}
+	for _, gates := range [][]communication.GateResult{
+		nil,
+		{{Name: "test", Passed: false, ExitCode: 1}},
+	} {
+		report.Gates = gates
+		o.startCoverage(report)
+		o.mu.Lock()
+		running := o.covering["task_001"]
+		o.mu.Unlock()
+		if running {
+			t.Errorf("coverage should not start with gates %+v", gates)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 追加された依存をマージ前の承認対象にする判定条件
+const policyNewDependencies = "new_dependencies"
+
+// ブランチで変更された依存関係（分岐点とブランチの依存定義ファイルを比較）
+func (o *Orchestrator) branchDependencyChanges(branch string) ([]analysis.DependencyChange, error) {
+	changed, err := o.worktrees.ChangedFiles(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []string
+	for _, file := range changed {
+		if analysis.IsManifest(file) {
+			manifests = append(manifests, file)
+		}
+	}
+	if len(manifests) == 0 {
+		return nil, nil
+	}
+
+	base, err := o.worktrees.MergeBase(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []analysis.DependencyChange
+	for _, file := range manifests {
+		// 追加・削除されたファイルは空の内容として比較する
+		before, _, err := o.worktrees.FileAt(base, file)
+		if err != nil {
+			return nil, err
+		}
+		after, _, err := o.worktrees.FileAt(branch, file)
+		if err != nil {
+			return nil, err
+		}
+
+		diff, err := analysis.DiffDependencies(file, before, after)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, diff...)
+	}
+	return changes, nil
+}
+
+// タスクのブランチで変更された依存関係
+func (o *Orchestrator) DependencyChanges(taskID string) ([]analysis.DependencyChange, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !o.worktrees.IsRepository() {
+		return nil, fmt.Errorf("project root is not a git repository: %s", o.projectRoot)
+	}
+
+	branch := task.Branch
+	if branch == "" {
+		branch = parallel.TaskBranch(task.TaskID)
+	}
+	if !o.worktrees.BranchExists(branch) {
+		return nil, fmt.Errorf("branch not found: %s", branch)
+	}
+	return o.branchDependencyChanges(branch)
+}
+
+// 追加された依存（"<名前> (<依存定義ファイル>)"）
+func addedDependencies(changes []analysis.DependencyChange) []string {
+	var added []string
+	for _, c := range changes {
+		if c.Change == analysis.DependencyAdded {
+			added = append(added, fmt.Sprintf("%s (%s)", c.Name, c.Manifest))
+		}
+	}
+	return added
+}
+
+// 依存関係の変更の表示（例: "github.com/google/uuid: added v1.6.0"）
+func FormatDependencyChange(c analysis.DependencyChange) string {
+	switch c.Change {
+	case analysis.DependencyAdded:
+		return strings.TrimSpace(fmt.Sprintf("%s: %s %s", c.Name, c.Change, c.To))
+	case analysis.DependencyRemoved:
+		return strings.TrimSpace(fmt.Sprintf("%s: %s %s", c.Name, c.Change, c.From))
+	default:
+		return fmt.Sprintf("%s: %s %s -> %s", c.Name, c.Change, c.From, c.To)
+	}
+}
+
+// 完了報告にタスクのブランチで変更された依存関係を記録し、追加された依存を Marshall に通知
+// レポートを書き直すと再度イベントが届くため、記録済みの内容と同じなら何もしない
+func (o *Orchestrator) recordDependencyChanges(report *communication.Report) error {
+	task, err := o.tasks.ReadByID(report.TaskID)
+	if err != nil {
+		return err
+	}
+
+	branch := task.Branch
+	if branch == "" {
+		branch = parallel.TaskBranch(task.TaskID)
+	}
+	if !o.worktrees.IsRepository() || !o.worktrees.BranchExists(branch) {
+		return nil
+	}
+
+	changes, err := o.branchDependencyChanges(branch)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 || reflect.DeepEqual(changes, report.Dependencies) {
+		return nil
+	}
+
+	report.Dependencies = changes
+	if err := o.writeReport(report); err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, FormatDependencyChange(c))
+	}
+	log.Printf("[deps] %s の依存関係の変更: %s", report.TaskID, strings.Join(lines, ", "))
+
+	added := addedDependencies(changes)
+	if len(added) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("%s で依存関係が追加されました: %s。必要な依存か確認してください", report.TaskID, strings.Join(added, ", "))
+	return o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion")
+}
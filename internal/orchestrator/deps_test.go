@@ -0,0 +1,76 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+func TestFormatDependencyChange(t *testing.T) {
+	tests := []struct {
+		change analysis.DependencyChange
+		want   string
+	}{
+		{analysis.DependencyChange{Name: "zod", Change: analysis.DependencyAdded, To: "^3.22.0"}, "zod: added ^3.22.0"},
+		{analysis.DependencyChange{Name: "numpy", Change: analysis.DependencyAdded}, "numpy: added"},
+		{analysis.DependencyChange{Name: "lodash", Change: analysis.DependencyRemoved, From: "^4.17.21"}, "lodash: removed ^4.17.21"},
+		{analysis.DependencyChange{Name: "react", Change: analysis.DependencyUpgraded, From: "^18.2.0", To: "^18.3.1"}, "react: upgraded ^18.2.0 -> ^18.3.1"},
+	}
+	for _, tt := range tests {
+		if got := FormatDependencyChange(tt.change); got != tt.want {
+			t.Errorf("FormatDependencyChange(%+v) = %q, want %q", tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestHandleReportChange_RecordsDependencies(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001", CommandID: "cmd_001"}, "go.mod",
+		"module example.com/app\n\nrequire github.com/google/uuid v1.6.0\n")
+
+	changes, err := o.DependencyChanges("task_001")
+	if err != nil {
+		t.Fatalf("DependencyChanges failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Name != "github.com/google/uuid" || changes[0].Change != analysis.DependencyAdded {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	report := &communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Summary: "UUID で ID を採番", Timestamp: time.Now()}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	path := filepath.Join(o.reports.Dir(), sp1+"_report.yaml")
+
+	// レポートの書き直しで再度イベントが届いても通知は 1 回
+	for i := 0; i < 2; i++ {
+		if err := o.handleReportChange(path); err != nil {
+			t.Fatalf("handleReportChange failed: %v", err)
+		}
+	}
+
+	got, err := o.reports.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if len(got.Dependencies) != 1 || got.Dependencies[0].Manifest != "go.mod" || got.Dependencies[0].To != "v1.6.0" {
+		t.Errorf("dependencies should be recorded: %+v", got.Dependencies)
+	}
+
+	messages, _ := o.inbox.Read(AgentMarshall)
+	var notified int
+	for _, m := range messages {
+		if strings.Contains(m.Message, "github.com/google/uuid (go.mod)") {
+			notified++
+		}
+	}
+	if notified != 1 {
+		t.Errorf("marshall should be notified once, got %+v", messages)
+	}
+}
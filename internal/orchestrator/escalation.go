@@ -0,0 +1,190 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+// エージェントごとのエスカレーション状態
+type escalationState struct {
+	// 追跡中の最古の未処理メッセージ
+	messageID string
+	// 送信済みの最大フェーズ
+	phase int
+}
+
+// エスカレーションループを実行
+func (o *Orchestrator) runEscalation() {
+	ticker := time.NewTicker(o.config.Escalation.CheckInterval)
+	defer ticker.Stop()
+
+	log.Printf("[escalation] 未処理メッセージの監視を開始しました（間隔: %s）", o.config.Escalation.CheckInterval)
+	for {
+		select {
+		case <-o.done:
+			return
+		case now := <-ticker.C:
+			o.checkEscalations(now)
+		}
+	}
+}
+
+// 各エージェントの未処理メッセージを確認し、必要ならエスカレーション
+func (o *Orchestrator) checkEscalations(now time.Time) {
+	agents, err := o.inboxAgents()
+	if err != nil {
+		log.Printf("[escalation] inbox 一覧の取得に失敗: %v", err)
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, agent := range agents {
+		pending, err := o.inbox.GetPendingMessages(agent)
+		if err != nil {
+			log.Printf("[escalation] %s の inbox 読み込みに失敗: %v", agent, err)
+			continue
+		}
+
+		// 未処理メッセージがなければ状態をリセット
+		oldest, ok := oldestMessage(pending)
+		if !ok {
+			delete(o.escalations, agent)
+			continue
+		}
+
+		// 最古のメッセージが変わった（処理が進んだ）場合は Phase 0 からやり直す
+		state := o.escalations[agent]
+		if state == nil || state.messageID != oldest.ID {
+			state = &escalationState{messageID: oldest.ID}
+			o.escalations[agent] = state
+		}
+
+		// 一度に進めるのは 1 フェーズまで（watcher 再起動直後にいきなり /clear しない）
+		age := now.Sub(oldest.Timestamp)
+		phase := escalationPhase(age, o.config.Escalation)
+		if phase > state.phase+1 {
+			phase = state.phase + 1
+		}
+		if phase <= state.phase {
+			continue
+		}
+
+		target, ok := o.agentTarget(agent)
+		if !ok {
+			continue
+		}
+
+		// 作業中のエージェントを Escape や /clear で中断しない
+		agentState, err := o.detectTargetState(target)
+		if err == nil && !canNudge(agentState) {
+			continue
+		}
+
+		log.Printf("[escalation] %s: 未処理メッセージ %s が %s 経過 → Phase %d",
+			agent, oldest.ID, age.Truncate(time.Second), phase)
+		if err := o.WakeupWithEscalation(agent, target, phase); err != nil {
+			log.Printf("[escalation] %s の Phase %d に失敗: %v", agent, phase, err)
+			continue
+		}
+		state.phase = phase
+	}
+}
+
+// 経過時間から到達すべきフェーズを求める（0 はエスカレーション不要）
+func escalationPhase(age time.Duration, cfg config.EscalationConfig) int {
+	switch {
+	case age >= cfg.Phase3After:
+		return 3
+	case age >= cfg.Phase2After:
+		return 2
+	case age >= cfg.Phase1After:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// 最も古いメッセージを返す
+func oldestMessage(messages []communication.Message) (communication.Message, bool) {
+	if len(messages) == 0 {
+		return communication.Message{}, false
+	}
+	oldest := messages[0]
+	for _, msg := range messages[1:] {
+		if msg.Timestamp.Before(oldest.Timestamp) {
+			oldest = msg
+		}
+	}
+	return oldest, true
+}
+
+// inbox ファイルが存在するエージェント名の一覧を取得
+func (o *Orchestrator) inboxAgents() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(o.queueDir, "inbox"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var agents []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		agents = append(agents, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(agents)
+	return agents, nil
+}
+
+// /clear 前に文脈を引き継ぐためのノートを保存
+// 保存先: agents/queue/handoff/<agent>.md
+func (o *Orchestrator) writeHandoffNote(agent string) (string, error) {
+	pending, err := o.inbox.GetPendingMessages(agent)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pending messages: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 引き継ぎノート: %s\n\n", agent)
+	fmt.Fprintf(&b, "- 作成日時: %s\n", time.Now().Format(time.RFC3339))
+	b.WriteString("- 理由: inbox の未処理メッセージに応答がなかったため、セッションを /clear でリセットしました\n\n")
+	b.WriteString("## 未処理メッセージ\n\n")
+	if len(pending) == 0 {
+		b.WriteString("(なし)\n")
+	}
+	for _, msg := range pending {
+		fmt.Fprintf(&b, "- [%s] %s from %s (%s): %s\n",
+			msg.ID, msg.Timestamp.Format(time.RFC3339), msg.From, msg.Type, msg.Message)
+	}
+	b.WriteString("\n## 再開手順\n\n")
+	fmt.Fprintf(&b, "1. %s を読み、未処理メッセージを上から順に処理する\n",
+		filepath.Join(o.queueDir, "inbox", agent+".yaml"))
+	fmt.Fprintf(&b, "2. 関連するタスクファイル（%s）で進捗を確認する\n",
+		filepath.Join(o.queueDir, "tasks"))
+
+	handoffDir := filepath.Join(o.queueDir, "handoff")
+	if err := os.MkdirAll(handoffDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create handoff directory: %w", err)
+	}
+
+	notePath := filepath.Join(handoffDir, agent+".md")
+	if err := os.WriteFile(notePath, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write handoff note: %w", err)
+	}
+
+	log.Printf("[escalation] %s の引き継ぎノートを保存しました: %s", agent, notePath)
+	return notePath, nil
+}
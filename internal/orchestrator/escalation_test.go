@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+func TestEscalationPhase(t *testing.T) {
+	cfg := config.Default().Escalation
+
+	tests := []struct {
+		name string
+		age  time.Duration
+		want int
+	}{
+		{"fresh", 10 * time.Second, 0},
+		{"phase1", cfg.Phase1After, 1},
+		{"phase2", cfg.Phase2After + time.Second, 2},
+		{"phase3", cfg.Phase3After, 3},
+		{"long after phase3", 24 * time.Hour, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escalationPhase(tt.age, cfg); got != tt.want {
+				t.Errorf("escalationPhase(%s) = %d, want %d", tt.age, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOldestMessage(t *testing.T) {
+	now := time.Now()
+	messages := []communication.Message{
+		{ID: "msg_b", Timestamp: now},
+		{ID: "msg_a", Timestamp: now.Add(-time.Minute)},
+		{ID: "msg_c", Timestamp: now.Add(time.Minute)},
+	}
+
+	oldest, ok := oldestMessage(messages)
+	if !ok {
+		t.Fatal("expected a message")
+	}
+	if oldest.ID != "msg_a" {
+		t.Errorf("expected msg_a, got %s", oldest.ID)
+	}
+
+	if _, ok := oldestMessage(nil); ok {
+		t.Error("expected no message for empty slice")
+	}
+}
+
+func TestAgentTarget(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	tests := []struct {
+		agent  string
+		want   string
+		wantOK bool
+	}{
+		{"envoy", "main.0", true},
+		{"marshall", "main.2", true},
+		{"specialist_1", "specialists.0", true},
+		{"specialist_3", "specialists.2", true},
+		{"specialist_0", "", false},
+		{"specialist_x", "", false},
+		{"unknown", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := o.agentTarget(tt.agent)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("agentTarget(%q) = (%q, %v), want (%q, %v)", tt.agent, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestWriteHandoffNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	o := newTestOrchestrator(t, tmpDir)
+
+	if err := o.inbox.Write("marshall", "cmd_001 を分解してください", communication.MessageTypeTaskAssigned, "envoy"); err != nil {
+		t.Fatalf("failed to write inbox: %v", err)
+	}
+
+	path, err := o.writeHandoffNote("marshall")
+	if err != nil {
+		t.Fatalf("writeHandoffNote failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read handoff note: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "cmd_001 を分解してください") {
+		t.Errorf("handoff note should contain pending message, got:\n%s", content)
+	}
+	if !strings.Contains(content, "marshall.yaml") {
+		t.Errorf("handoff note should point to inbox file, got:\n%s", content)
+	}
+}
+
+func TestInboxAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	o := newTestOrchestrator(t, tmpDir)
+
+	// inbox ディレクトリが無い場合は空
+	agents, err := o.inboxAgents()
+	if err != nil {
+		t.Fatalf("inboxAgents failed: %v", err)
+	}
+	if len(agents) != 0 {
+		t.Errorf("expected no agents, got %v", agents)
+	}
+
+	for _, agent := range []string{"marshall", "envoy", "specialist_1"} {
+		if err := o.inbox.Write(agent, "test", communication.MessageTypeWakeUp, "test"); err != nil {
+			t.Fatalf("failed to write inbox: %v", err)
+		}
+	}
+
+	agents, err = o.inboxAgents()
+	if err != nil {
+		t.Fatalf("inboxAgents failed: %v", err)
+	}
+	want := []string{"envoy", "marshall", "specialist_1"}
+	if strings.Join(agents, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, agents)
+	}
+}
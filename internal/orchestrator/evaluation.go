@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+// レポートのテスト結果とカバレッジを評価に反映する（評価がまだなければ何もしない）
+func (o *Orchestrator) applyReportToEvaluation(report communication.Report) error {
+	eval, err := o.evaluations.Read(report.TaskID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	changed := false
+	if report.Tests != nil && eval.ApplyTests(*report.Tests) {
+		changed = true
+	}
+	if report.Coverage != nil && eval.ApplyCoverage(*report.Coverage) {
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	log.Printf("[evaluation] %s の評価にレポートの結果を反映しました（correctness: %d）", report.TaskID, eval.Scores.Correctness)
+	return o.evaluations.Write(eval)
+}
+
+// 評価の変更を処理（Marshall が書いた評価にレポートのテスト結果とカバレッジを反映する）
+// 並列試行の評価なら勝者を選べるか確認する
+func (o *Orchestrator) handleEvaluationChange(path string) error {
+	eval, err := o.evaluations.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if eval.TaskID == "" {
+		return nil
+	}
+	if task, err := o.tasks.ReadByID(eval.TaskID); err == nil && task.AttemptOf != "" {
+		if _, err := o.selectAttempt(task.AttemptOf, time.Now(), false); err != nil {
+			log.Printf("[attempts] %s の勝者の選択に失敗: %v", task.AttemptOf, err)
+		}
+	}
+	report, err := o.reports.ReadByTaskID(eval.TaskID)
+	if err != nil {
+		return nil
+	}
+	return o.applyReportToEvaluation(*report)
+}
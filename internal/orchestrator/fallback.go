@@ -0,0 +1,223 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 再実行の上限に達したタスクの failure
+const failureRetriesExhausted = "retries_exhausted"
+
+// 担当が失敗を報告した試行の failure（試行は再実行しない）
+const failureReported = "reported"
+
+// タスクに適用する失敗時の対応方針（指令の fallback > 優先度ごとの設定 > 既定）
+func (o *Orchestrator) fallbackPolicy(task communication.Task) config.FallbackPolicy {
+	var override *config.FallbackOverride
+	if task.CommandID != "" {
+		if cmd, err := o.commands.ReadByID(task.CommandID); err == nil {
+			override = cmd.Fallback
+		}
+	}
+	return o.config.Fallback.Policy(o.taskPriority(task), override)
+}
+
+// レポートの失敗の要約（summary と issues）
+func failureSummary(report *communication.Report) string {
+	parts := []string{strings.TrimSpace(report.Summary)}
+	for _, issue := range report.Issues {
+		if issue = strings.TrimSpace(issue); issue != "" {
+			parts = append(parts, issue)
+		}
+	}
+	summary := strings.Trim(strings.Join(parts, " / "), " /")
+	if summary == "" {
+		return "（要約なし）"
+	}
+	return summary
+}
+
+// 再実行するタスクのコンテキストに前回の失敗を追記
+func retryContext(context string, attempt int, specialist, summary string, differentApproach bool) string {
+	note := fmt.Sprintf("[再実行 %d 回目] 前回の担当 %s は失敗しました: %s", attempt, specialist, summary)
+	if differentApproach {
+		note += "。前回とは別のアプローチで取り組んでください"
+	}
+	if context == "" {
+		return note
+	}
+	return context + "\n\n" + note
+}
+
+// failed のレポートを処理
+// 同じレポートの書き込みイベントが複数届くため、処理済みのレポートや付け替え済みのタスクは無視する
+func (o *Orchestrator) handleFailedReport(path string, report *communication.Report) error {
+	task, err := o.tasks.ReadByID(report.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.SpecialistID != report.SpecialistID || task.Status == communication.TaskStatusCompleted {
+		return nil
+	}
+
+	failedAt := report.Timestamp
+	if failedAt.IsZero() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		failedAt = info.ModTime()
+	}
+	for _, e := range task.History {
+		if e.Event == communication.TaskEventFailed && e.At.Equal(failedAt) {
+			return nil
+		}
+	}
+
+	return o.handleTaskFailure(*task, failureSummary(report), failedAt)
+}
+
+// 失敗したタスクを対応方針に従って再キューし、上限に達していればエスカレーションする
+// 失敗した作業のブランチは退避し、再実行は分岐点から新しいブランチで始める
+func (o *Orchestrator) handleTaskFailure(task communication.Task, summary string, at time.Time) error {
+	if task.AttemptOf != "" {
+		return o.failAttempt(task, summary, at)
+	}
+
+	policy := o.fallbackPolicy(task)
+	specialist := task.SpecialistID
+
+	if task.RetryCount >= policy.MaxRetries {
+		return o.exhaustRetries(task, policy, summary, at)
+	}
+
+	attempt := task.RetryCount + 1
+	archived, err := o.archiveFailedBranch(task, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to archive branch of %s: %w", task.TaskID, err)
+	}
+
+	err = o.tasks.Update(task.TaskID, func(t *communication.Task) error {
+		t.AddEvent(at, communication.TaskEventFailed, fmt.Sprintf("%s: %s", specialist, summary))
+		t.RetryCount = attempt
+		t.Context = retryContext(t.Context, attempt, specialist, summary, policy.RetryWithDifferentApproach)
+		if policy.RetryWithDifferentApproach && !containsString(t.ExcludedSpecialists, specialist) {
+			t.ExcludedSpecialists = append(t.ExcludedSpecialists, specialist)
+		}
+		t.Status = communication.TaskStatusPending
+		t.SpecialistID = ""
+		t.AssignedAt = time.Time{}
+		t.StartedAt = time.Time{}
+		// 次の担当の worktree で改めて準備する（コンフリクト解消タスクは元のタスクのブランチで作業するため残す）
+		t.Worktree = ""
+		if t.ResolvesConflictOf == "" {
+			t.Branch = ""
+		}
+		t.AddEvent(at, communication.TaskEventRetried, fmt.Sprintf("%d/%d 回目", attempt, policy.MaxRetries))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to requeue task %s: %w", task.TaskID, err)
+	}
+	log.Printf("[fallback] %s (%s) の失敗を受けて再キューしました（%d/%d 回目）", task.TaskID, specialist, attempt, policy.MaxRetries)
+
+	message := fmt.Sprintf("タスク %s は %s が失敗を報告したため、失敗の要約をコンテキストに追記して再キューしました（再実行 %d/%d 回目）: %s",
+		task.TaskID, specialist, attempt, policy.MaxRetries, summary)
+	if archived != "" {
+		message += "。失敗した作業のブランチは " + archived + " に退避しました"
+	}
+	if policy.RetryWithDifferentApproach {
+		message += fmt.Sprintf("。%s 以外に割り当て、別のアプローチで取り組ませてください", specialist)
+	}
+	if !o.config.Scheduler.Enabled {
+		message += "。スケジューラが無効のため、空いている Specialist に割り当ててください"
+	}
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion"); err != nil {
+		log.Printf("[fallback] marshall への通知に失敗: %v", err)
+	}
+	return nil
+}
+
+// 失敗したタスクのブランチを退避する（例: bastion/archive/task/task_001_failed_1。ブランチがなければ空）
+// 担当の worktree がブランチを使用中なら Specialist 用のブランチに戻してから退避する
+// コンフリクト解消タスクは元のタスクのブランチで作業するため退避しない
+func (o *Orchestrator) archiveFailedBranch(task communication.Task, attempt int) (string, error) {
+	branch := taskBranchName(task)
+	if task.ResolvesConflictOf != "" {
+		return "", o.releaseBranch(task.SpecialistID, branch)
+	}
+	if !o.worktrees.IsRepository() || !o.worktrees.BranchExists(branch) {
+		return "", nil
+	}
+	if err := o.releaseBranch(task.SpecialistID, branch); err != nil {
+		return "", err
+	}
+	return o.worktrees.ArchiveBranchAs(branch, fmt.Sprintf("%s_failed_%d", parallel.ArchivedBranch(branch), attempt))
+}
+
+// 失敗した試行を failed にして勝者の選択に任せる（再実行しない）
+func (o *Orchestrator) failAttempt(task communication.Task, summary string, at time.Time) error {
+	err := o.tasks.Update(task.TaskID, func(t *communication.Task) error {
+		t.AddEvent(at, communication.TaskEventFailed, fmt.Sprintf("%s: %s", task.SpecialistID, summary))
+		t.Status = communication.TaskStatusFailed
+		t.Failure = failureReported
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark attempt %s as failed: %w", task.TaskID, err)
+	}
+	log.Printf("[fallback] 試行 %s (%s) は失敗したため再実行せず、%s の勝者の選択に任せます", task.TaskID, task.SpecialistID, task.AttemptOf)
+
+	_, err = o.selectAttempt(task.AttemptOf, at, false)
+	return err
+}
+
+// 再実行の上限に達したタスクを failed にし、Envoy にエスカレーション
+func (o *Orchestrator) exhaustRetries(task communication.Task, policy config.FallbackPolicy, summary string, at time.Time) error {
+	specialist := task.SpecialistID
+
+	err := o.tasks.Update(task.TaskID, func(t *communication.Task) error {
+		t.AddEvent(at, communication.TaskEventFailed, fmt.Sprintf("%s: %s", specialist, summary))
+		t.Status = communication.TaskStatusFailed
+		t.Failure = failureRetriesExhausted
+		if policy.EscalateToHuman {
+			t.AddEvent(at, communication.TaskEventEscalatedToHuman, fmt.Sprintf("%d 回再実行しても失敗", t.RetryCount))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark task %s as failed: %w", task.TaskID, err)
+	}
+	log.Printf("[fallback] %s は再実行の上限（%d 回）に達したため failed にしました", task.TaskID, policy.MaxRetries)
+
+	message := fmt.Sprintf("タスク %s は %d 回再実行しましたが失敗しました（最後の担当: %s）: %s",
+		task.TaskID, task.RetryCount, specialist, summary)
+	if policy.EscalateToHuman {
+		if err := o.inbox.Write(AgentEnvoy, message+"。自動での対応を打ち切りました。ユーザーに状況を伝え、指令を見直すか判断を仰いでください",
+			communication.MessageTypeReportReceived, "bastion"); err != nil {
+			log.Printf("[fallback] envoy への通知に失敗: %v", err)
+		}
+		message += "。Envoy にエスカレーションしました"
+	}
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion"); err != nil {
+		log.Printf("[fallback] marshall への通知に失敗: %v", err)
+	}
+	return nil
+}
+
+// スライスに文字列が含まれるか
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
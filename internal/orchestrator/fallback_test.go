@@ -0,0 +1,203 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestFailureSummary(t *testing.T) {
+	report := &communication.Report{Summary: "テストが通らない", Issues: []string{"DB に接続できない", " "}}
+	if got := failureSummary(report); got != "テストが通らない / DB に接続できない" {
+		t.Errorf("unexpected summary: %q", got)
+	}
+	if got := failureSummary(&communication.Report{Issues: []string{"依存が壊れている"}}); got != "依存が壊れている" {
+		t.Errorf("unexpected summary: %q", got)
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	got := retryContext("JWT で実装", 1, "specialist_1", "テストが通らない", true)
+	if !strings.HasPrefix(got, "JWT で実装\n\n[再実行 1 回目]") || !strings.Contains(got, "別のアプローチ") {
+		t.Errorf("unexpected context: %q", got)
+	}
+	if got := retryContext("", 2, "specialist_1", "失敗", false); strings.Contains(got, "別のアプローチ") || !strings.HasPrefix(got, "[再実行 2 回目]") {
+		t.Errorf("unexpected context: %q", got)
+	}
+}
+
+// 担当に割り当て済みのタスクと failed のレポートを書き込む
+func writeFailedReport(t *testing.T, o *Orchestrator, taskID, specialist, summary string, at time.Time) string {
+	t.Helper()
+
+	err := o.tasks.Update(taskID, func(task *communication.Task) error {
+		task.SpecialistID = specialist
+		task.Status = communication.TaskStatusInProgress
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to assign task: %v", err)
+	}
+
+	report := &communication.Report{TaskID: taskID, SpecialistID: specialist, Status: communication.TaskStatusFailed, Summary: summary, Timestamp: at}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	return filepath.Join(o.reports.Dir(), specialist+"_report.yaml")
+}
+
+func TestHandleReportChange_RetriesFailedTask(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Fallback.MaxRetries = 1
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", Context: "JWT で実装", Status: communication.TaskStatusPending}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	// 同じレポートの書き込みイベントが複数届いても再実行は 1 回
+	start := time.Now().Add(-time.Hour)
+	path := writeFailedReport(t, o, "task_001", "specialist_1", "テストが通らない", start)
+	for i := 0; i < 2; i++ {
+		if err := o.handleReportChange(path); err != nil {
+			t.Fatalf("handleReportChange failed: %v", err)
+		}
+	}
+
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusPending || got.SpecialistID != "" || got.RetryCount != 1 {
+		t.Errorf("task should be requeued: %+v", got)
+	}
+	if !strings.Contains(got.Context, "テストが通らない") || strings.Join(got.ExcludedSpecialists, ",") != "specialist_1" {
+		t.Errorf("failure should be recorded for the retry: %+v", got)
+	}
+	messages, _ := o.inbox.Read(AgentMarshall)
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "1/1") {
+		t.Errorf("marshall should be notified once, got %+v", messages)
+	}
+
+	// 再実行でも失敗したら Envoy にエスカレーション
+	path = writeFailedReport(t, o, "task_001", "specialist_2", "別の方法でも通らない", start.Add(time.Minute))
+	if err := o.handleReportChange(path); err != nil {
+		t.Fatalf("handleReportChange failed: %v", err)
+	}
+
+	got, _ = o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusFailed || got.Failure != failureRetriesExhausted {
+		t.Errorf("task should fail after retries: %+v", got)
+	}
+	var events []string
+	for _, e := range got.History {
+		events = append(events, e.Event)
+	}
+	if strings.Join(events, ",") != "failed,retried,failed,escalated_to_human" {
+		t.Errorf("unexpected history: %v", events)
+	}
+	messages, _ = o.inbox.Read(AgentEnvoy)
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "別の方法でも通らない") {
+		t.Errorf("envoy should be notified once, got %+v", messages)
+	}
+}
+
+func TestHandleReportChange_CommandFallbackOverride(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	retries, escalate := 0, false
+	cmd := communication.Command{
+		ID:       "cmd_001",
+		Status:   communication.CommandStatusInProgress,
+		Fallback: &config.FallbackOverride{MaxRetries: &retries, EscalateToHuman: &escalate},
+	}
+	if err := o.commands.Write(cmd); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", CommandID: "cmd_001"}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	path := writeFailedReport(t, o, "task_001", "specialist_1", "失敗", time.Now())
+	if err := o.handleReportChange(path); err != nil {
+		t.Fatalf("handleReportChange failed: %v", err)
+	}
+
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusFailed || got.RetryCount != 0 {
+		t.Errorf("task should fail without retry: %+v", got)
+	}
+	if messages, _ := o.inbox.Read(AgentEnvoy); len(messages) != 0 {
+		t.Errorf("envoy should not be notified, got %+v", messages)
+	}
+	if messages, _ := o.inbox.Read(AgentMarshall); len(messages) != 1 {
+		t.Errorf("marshall should be notified, got %+v", messages)
+	}
+}
+
+func TestHandleTaskFailure_ArchivesBranch(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	o.config.Fallback.MaxRetries = 2
+
+	// 失敗した作業のコミットがあるブランチを担当の worktree で使用中
+	task := &communication.Task{TaskID: "task_001"}
+	completeTaskOnBranch(t, o, sp1, task, "app.go", "package app\n")
+	if _, err := o.CheckoutTask(sp1, "task_001"); err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+	failed, _ := o.tasks.ReadByID("task_001")
+
+	if err := o.handleTaskFailure(*failed, "テストが通らない", time.Now()); err != nil {
+		t.Fatalf("handleTaskFailure failed: %v", err)
+	}
+
+	// 再実行は分岐点から新しいブランチで始める
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusPending || got.Branch != "" || got.Worktree != "" {
+		t.Errorf("task should be requeued without its branch: %+v", got)
+	}
+	archived := "bastion/archive/task/task_001_failed_1"
+	if o.worktrees.BranchExists(parallel.TaskBranch("task_001")) || !o.worktrees.BranchExists(archived) {
+		t.Errorf("failed branch should be moved to %s", archived)
+	}
+	if branch, _ := o.worktrees.CurrentBranch(parallel.WorktreeName(1)); branch == parallel.TaskBranch("task_001") {
+		t.Error("worktree should be released from the failed branch")
+	}
+	if n := countMarshallMessages(t, o, archived); n != 1 {
+		t.Errorf("marshall should be told where the branch went, got %d", n)
+	}
+}
+
+func TestHandleTaskFailure_LeavesAttemptsToSelection(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Fallback.MaxRetries = 2
+
+	parent := communication.Task{TaskID: "task_001", Attempts: 2, Status: communication.TaskStatusPending}
+	if err := o.tasks.Write(&parent); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	attempts, err := o.fanOutAttempts(parent, time.Now())
+	if err != nil {
+		t.Fatalf("fanOutAttempts failed: %v", err)
+	}
+	attempts[1].Status = communication.TaskStatusFailed
+	if err := o.tasks.Write(&attempts[1]); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	// 試行は再実行せず failed にして勝者を選ぶ
+	attempts[0].SpecialistID = "specialist_1"
+	attempts[0].Status = communication.TaskStatusInProgress
+	if err := o.handleTaskFailure(attempts[0], "テストが通らない", time.Now()); err != nil {
+		t.Fatalf("handleTaskFailure failed: %v", err)
+	}
+	got, _ := o.tasks.ReadByID("task_001_attempt_1")
+	if got.Status != communication.TaskStatusFailed || got.RetryCount != 0 {
+		t.Errorf("attempt should fail without a retry: %+v", got)
+	}
+	if got, _ := o.tasks.ReadByID("task_001"); got.Status != communication.TaskStatusFailed || got.Failure != communication.TaskEventAttemptsFailed {
+		t.Errorf("parent should fail when every attempt failed: %+v", got)
+	}
+}
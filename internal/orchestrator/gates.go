@@ -0,0 +1,262 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+// 品質ゲートを実行するか
+func (o *Orchestrator) useGates() bool {
+	return o.config.Gates.Enabled && len(o.config.Gates.Commands) > 0
+}
+
+// 出力の末尾を limit バイトまでに切り詰める（行の途中から始まらないようにする）
+func truncateOutput(output string, limit int) string {
+	output = strings.TrimRight(output, "\n")
+	if len(output) <= limit {
+		return output
+	}
+
+	tail := output[len(output)-limit:]
+	if i := strings.IndexByte(tail, '\n'); i >= 0 && i < len(tail)-1 {
+		tail = tail[i+1:]
+	} else {
+		for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+			tail = tail[1:]
+		}
+	}
+	return "... (truncated)\n" + tail
+}
+
+// 品質ゲートのコマンドを 1 つ実行
+func (o *Orchestrator) runGate(dir string, gate config.GateCommand, env []string) communication.GateResult {
+	timeout := gate.Timeout
+	if timeout <= 0 {
+		timeout = o.config.Gates.Timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", gate.Run)
+	cmd.Dir = dir
+	cmd.Env = env
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// タイムアウト後に子プロセスが出力を握ったままでも待ち続けない
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	result := communication.GateResult{
+		Name:     gate.Name,
+		Command:  gate.Run,
+		Passed:   err == nil,
+		Duration: time.Since(start).Round(time.Millisecond),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		result.TimedOut = true
+		fmt.Fprintf(&output, "\ntimed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.ExitCode = -1
+		fmt.Fprintf(&output, "\n%v", err)
+	}
+	result.Output = o.redactSecrets(truncateOutput(output.String(), o.config.Gates.OutputLimit))
+	return result
+}
+
+// タスクの worktree（worktree を使わないタスクはプロジェクトルート）
+// 記録した worktree がなければエラー（プロジェクトルートの別の内容で代用しない）
+func (o *Orchestrator) taskWorkdir(task communication.Task) (string, error) {
+	if task.Worktree == "" {
+		return o.projectRoot, nil
+	}
+	if info, err := os.Stat(task.Worktree); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("worktree of %s not found: %s", task.TaskID, task.Worktree)
+	}
+	return task.Worktree, nil
+}
+
+// タスクの worktree で品質ゲートを順に実行（失敗しても残りのゲートを実行する）
+func (o *Orchestrator) RunGates(taskID string) ([]communication.GateResult, error) {
+	if len(o.config.Gates.Commands) == 0 {
+		return nil, fmt.Errorf("no gate commands configured")
+	}
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// worktree がなければ各ゲートを失敗として記録する
+	dir, err := o.taskWorkdir(*task)
+	if err != nil {
+		results := make([]communication.GateResult, 0, len(o.config.Gates.Commands))
+		for _, gate := range o.config.Gates.Commands {
+			results = append(results, communication.GateResult{Name: gate.Name, Command: gate.Run, ExitCode: -1, Output: err.Error()})
+		}
+		return results, nil
+	}
+	env := append(os.Environ(),
+		"BASTION_PROJECT_ROOT="+o.projectRoot,
+		"BASTION_WORKTREE="+dir,
+		"BASTION_TASK_ID="+task.TaskID,
+	)
+
+	results := make([]communication.GateResult, 0, len(o.config.Gates.Commands))
+	for _, gate := range o.config.Gates.Commands {
+		log.Printf("[gates] %s: %s を実行しています...", task.TaskID, gate.Name)
+		results = append(results, o.runGate(dir, gate, env))
+	}
+	return results, nil
+}
+
+// 失敗したゲート
+func failedGates(results []communication.GateResult) []communication.GateResult {
+	var failed []communication.GateResult
+	for _, r := range results {
+		if !r.Passed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// ゲートの結果の表示（例: "test: exit 1 (3.2s)"）
+func FormatGateResult(r communication.GateResult) string {
+	switch {
+	case r.Passed:
+		return fmt.Sprintf("%s: passed (%s)", r.Name, r.Duration)
+	case r.TimedOut:
+		return fmt.Sprintf("%s: timed out (%s)", r.Name, r.Duration)
+	default:
+		return fmt.Sprintf("%s: exit %d (%s)", r.Name, r.ExitCode, r.Duration)
+	}
+}
+
+// 完了報告の品質ゲートをバックグラウンドで実行し、終わったら以降の段階を再開する
+// ゲートの結果を待つ（実行を始めたか、同じタスクのゲートが実行中）なら true
+func (o *Orchestrator) startGates(report communication.Report) bool {
+	if !o.useGates() || report.Status != communication.TaskStatusCompleted || gatesRecorded(report) {
+		return false
+	}
+
+	o.mu.Lock()
+	if o.gating[report.TaskID] {
+		o.mu.Unlock()
+		return true
+	}
+	o.gating[report.TaskID] = true
+	o.mu.Unlock()
+
+	go func() {
+		err := o.gateReport(report)
+		o.mu.Lock()
+		delete(o.gating, report.TaskID)
+		o.mu.Unlock()
+		if err != nil {
+			log.Printf("[gates] %s の品質ゲートの実行に失敗: %v", report.TaskID, err)
+		}
+		o.resumeReport(report, "gates", "coverage")
+	}()
+	return true
+}
+
+// レポートのタイムスタンプに対して品質ゲートを実行済みか（再提出したレポートに残った前回の結果は数えない）
+func gatesRecorded(report communication.Report) bool {
+	return len(report.Gates) > 0 && report.GatedAt.Equal(report.Timestamp)
+}
+
+// 完了報告の品質ゲートを実行して結果（とゲートが書いたテスト結果）をレポートに記録し、失敗したゲートがあれば担当に差し戻す
+func (o *Orchestrator) gateReport(report communication.Report) error {
+	results, err := o.RunGates(report.TaskID)
+	if err != nil {
+		return err
+	}
+
+	// 実行中に新しいレポートが書かれていれば結果は捨てる（新しいレポートで再度実行する）
+	current, err := o.reports.ReadFile(o.reports.Path(report.SpecialistID))
+	if err != nil {
+		return err
+	}
+	if current.TaskID != report.TaskID || !current.Timestamp.Equal(report.Timestamp) {
+		log.Printf("[gates] %s のレポートが更新されたため結果を破棄します", report.TaskID)
+		return nil
+	}
+	// 前回のレポートから引き継いだ結果は新しいゲートの結果で置き換える
+	current.Gates = results
+	current.GatedAt = report.Timestamp
+	current.Tests = nil
+	current.Coverage = nil
+	// ゲートが書いたテスト結果も記録する
+	hasTests := o.attachTestResults(current)
+	if err := o.writeReport(current); err != nil {
+		return err
+	}
+	if hasTests {
+		o.applyTestResults(*current)
+	}
+
+	summaries := make([]string, 0, len(results))
+	for _, r := range results {
+		summaries = append(summaries, FormatGateResult(r))
+	}
+	detail := strings.Join(summaries, ", ")
+
+	failed := failedGates(results)
+	if len(failed) == 0 {
+		log.Printf("[gates] %s の品質ゲートはすべて成功しました: %s", report.TaskID, detail)
+		return o.recordTaskEvent(report.TaskID, time.Now(), communication.TaskEventGatesPassed, detail, nil)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s の品質ゲートが失敗しました。修正してから再度レポートを提出してください", report.TaskID)
+	for _, r := range failed {
+		fmt.Fprintf(&b, "\n\n## %s\n$ %s", FormatGateResult(r), r.Command)
+		if r.Output != "" {
+			b.WriteString("\n" + r.Output)
+		}
+	}
+
+	sentBack, err := o.sendBackTask(report.TaskID, report.SpecialistID, time.Now(), communication.TaskEventGateFailed, detail, o.redactSecrets(b.String()), nil)
+	if err != nil {
+		return err
+	}
+
+	var message string
+	if sentBack {
+		log.Printf("[gates] %s の品質ゲートが失敗したため %s に差し戻しました: %s", report.TaskID, report.SpecialistID, detail)
+		if err := o.inbox.Write(report.SpecialistID, b.String(), communication.MessageTypeGateFailed, "bastion"); err != nil {
+			log.Printf("[gates] %s への通知に失敗: %v", report.SpecialistID, err)
+		}
+		message = fmt.Sprintf("%s の品質ゲートが失敗したため %s に差し戻しました: %s", report.TaskID, report.SpecialistID, detail)
+	} else {
+		log.Printf("[gates] %s の品質ゲートが失敗しましたが %s に戻せないため pending に戻しました: %s", report.TaskID, report.SpecialistID, detail)
+		message = fmt.Sprintf("%s の品質ゲートが失敗しました。%s はほかのタスクを担当中か worktree を切り替えられないため、失敗の内容をコンテキストに追記して pending に戻しました: %s",
+			report.TaskID, report.SpecialistID, detail)
+		if !o.config.Scheduler.Enabled {
+			message += "。スケジューラが無効のため、空いている Specialist に割り当ててください"
+		}
+	}
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeGateFailed, "bastion"); err != nil {
+		log.Printf("[gates] marshall への通知に失敗: %v", err)
+	}
+	return nil
+}
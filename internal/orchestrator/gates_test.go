@@ -0,0 +1,251 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	if got := truncateOutput("ok\n", 10); got != "ok" {
+		t.Errorf("short output should be kept: %q", got)
+	}
+	if got := truncateOutput("line1\nline2\nline3\n", 10); got != "... (truncated)\nline3" {
+		t.Errorf("output should be cut at a line boundary: %q", got)
+	}
+	// 行の区切りがなければ文字の途中から始めない
+	if got := truncateOutput("テストが失敗", 7); got != "... (truncated)\n失敗" {
+		t.Errorf("output should not start in the middle of a rune: %q", got)
+	}
+}
+
+func TestRunGates(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Gates.Commands = []config.GateCommand{
+		{Name: "test", Run: "echo ok"},
+		{Name: "lint", Run: "echo \"$BASTION_TASK_ID: unused variable\" >&2; exit 3"},
+		{Name: "slow", Run: "sleep 5", Timeout: 100 * time.Millisecond},
+	}
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", Status: communication.TaskStatusInProgress}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	results, err := o.RunGates("task_001")
+	if err != nil {
+		t.Fatalf("RunGates failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	if !results[0].Passed || results[0].Output != "ok" {
+		t.Errorf("test should pass: %+v", results[0])
+	}
+	if results[1].Passed || results[1].ExitCode != 3 || results[1].Output != "task_001: unused variable" {
+		t.Errorf("lint should fail with exit 3: %+v", results[1])
+	}
+	if results[2].Passed || !results[2].TimedOut {
+		t.Errorf("slow should time out: %+v", results[2])
+	}
+
+	o.config.Gates.Commands = nil
+	if _, err := o.RunGates("task_001"); err == nil {
+		t.Error("expected error without gate commands")
+	}
+}
+
+func TestGateReport_SendsTaskBack(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Gates.Enabled = true
+	o.config.Gates.Commands = []config.GateCommand{
+		{Name: "test", Run: "echo 'FAIL: TestLogin' >&2; exit 1"},
+	}
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	report := &communication.Report{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted, Summary: "ログインを実装", Timestamp: time.Now()}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+
+	if err := o.gateReport(*report); err != nil {
+		t.Fatalf("gateReport failed: %v", err)
+	}
+
+	got, err := o.reports.ReadByTaskID("task_001")
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if len(got.Gates) != 1 || got.Gates[0].ExitCode != 1 || got.Gates[0].Output != "FAIL: TestLogin" {
+		t.Errorf("gate results should be attached to the report: %+v", got.Gates)
+	}
+
+	task, _ := o.tasks.ReadByID("task_001")
+	if task.Status != communication.TaskStatusInProgress || task.SpecialistID != "specialist_1" {
+		t.Errorf("task should be sent back to the specialist: %+v", task)
+	}
+	if last := task.History[len(task.History)-1]; last.Event != communication.TaskEventGateFailed {
+		t.Errorf("gate failure should be recorded: %+v", last)
+	}
+	if messages, _ := o.inbox.Read("specialist_1"); len(messages) != 1 || !strings.Contains(messages[0].Message, "FAIL: TestLogin") {
+		t.Errorf("specialist should receive the failing output: %+v", messages)
+	}
+
+	// 結果を記録したレポートでは再度実行しない
+	o.startGates(*got)
+	o.mu.Lock()
+	running := o.gating["task_001"]
+	o.mu.Unlock()
+	if running {
+		t.Error("gates should not run again for a gated report")
+	}
+}
+
+func TestRunGates_MissingWorktree(t *testing.T) {
+	dir := t.TempDir()
+	o := newTestOrchestrator(t, dir)
+	o.config.Gates.Enabled = true
+	o.config.Gates.Commands = []config.GateCommand{{Name: "test", Run: "true"}}
+
+	// 記録した worktree がなければプロジェクトルートで代用せず失敗にする
+	missing := filepath.Join(dir, ".worktrees", "sp1")
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: "specialist_1", Worktree: missing, Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	results, err := o.RunGates("task_001")
+	if err != nil {
+		t.Fatalf("RunGates failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Passed || !strings.Contains(results[0].Output, "worktree of task_001 not found") {
+		t.Errorf("gate should fail for a missing worktree: %+v", results)
+	}
+}
+
+func TestGateReport_DiscardsStaleResults(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Gates.Enabled = true
+	o.config.Gates.Commands = []config.GateCommand{{Name: "test", Run: "exit 1"}}
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	old := communication.Report{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted, Timestamp: time.Now().Add(-time.Minute)}
+	// ゲートの実行中に新しいレポートが書かれた
+	current := old
+	current.Timestamp = time.Now()
+	if err := o.reports.Write(&current); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+
+	if err := o.gateReport(old); err != nil {
+		t.Fatalf("gateReport failed: %v", err)
+	}
+	got, _ := o.reports.ReadByTaskID("task_001")
+	if len(got.Gates) != 0 {
+		t.Errorf("stale results should be discarded: %+v", got.Gates)
+	}
+	if task, _ := o.tasks.ReadByID("task_001"); task.Status != communication.TaskStatusCompleted {
+		t.Errorf("task should not be sent back by stale results: %+v", task)
+	}
+}
+
+func TestStartGates_RerunsForResubmittedReport(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Gates.Enabled = true
+	o.config.Gates.Commands = []config.GateCommand{{Name: "test", Run: "true"}}
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	first := time.Now().Add(-time.Minute)
+	// 差し戻し後に再提出したレポートに前回の失敗したゲートの結果が残っている
+	report := &communication.Report{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted,
+		Timestamp: first.Add(30 * time.Second), GatedAt: first,
+		Gates: []communication.GateResult{{Name: "test", Passed: false, ExitCode: 1}}}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if gatesRecorded(*report) {
+		t.Fatal("gates of the previous report should not count")
+	}
+
+	if err := o.gateReport(*report); err != nil {
+		t.Fatalf("gateReport failed: %v", err)
+	}
+	got, _ := o.reports.ReadByTaskID("task_001")
+	if len(got.Gates) != 1 || !got.Gates[0].Passed || !got.GatedAt.Equal(report.Timestamp) || !gatesRecorded(*got) {
+		t.Errorf("gates should rerun for the resubmitted report: %+v", got)
+	}
+	if o.startGates(*got) {
+		t.Error("gates should not run again for a gated report")
+	}
+}
+
+func TestGateReport_ChecksOutSentBackTask(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	o.config.Gates.Enabled = true
+	o.config.Gates.Commands = []config.GateCommand{{Name: "test", Run: "exit 1"}}
+
+	// 完了後に worktree を待機用ブランチに戻していても、差し戻すときにタスクのブランチへ切り替える
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001"}, "app.go", "package app\n")
+	report := &communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Timestamp: time.Now()}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if err := o.gateReport(*report); err != nil {
+		t.Fatalf("gateReport failed: %v", err)
+	}
+
+	task, _ := o.tasks.ReadByID("task_001")
+	if task.Status != communication.TaskStatusInProgress || task.SpecialistID != sp1 || task.Worktree == "" {
+		t.Errorf("task should be sent back to %s: %+v", sp1, task)
+	}
+	if branch, _ := o.worktrees.CurrentBranch(parallel.WorktreeName(1)); branch != parallel.TaskBranch("task_001") {
+		t.Errorf("worktree should be switched to the task branch, got %s", branch)
+	}
+}
+
+func TestGateReport_RequeuesWhenSpecialistIsBusy(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	o.config.Gates.Enabled = true
+	o.config.Gates.Commands = []config.GateCommand{{Name: "test", Run: "echo 'FAIL: TestLogin' >&2; exit 1"}}
+
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001"}, "app.go", "package app\n")
+	// 担当は次のタスクに着手済み
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_002", SpecialistID: sp1, Status: communication.TaskStatusInProgress}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.CheckoutTask(sp1, "task_002"); err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+
+	report := &communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Timestamp: time.Now()}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	if err := o.gateReport(*report); err != nil {
+		t.Fatalf("gateReport failed: %v", err)
+	}
+
+	// 担当の worktree は切り替えず、ブランチを引き継いでスケジューラの割り当てを待つ
+	task, _ := o.tasks.ReadByID("task_001")
+	if task.Status != communication.TaskStatusPending || task.SpecialistID != "" || task.Worktree != "" || task.Branch != parallel.TaskBranch("task_001") {
+		t.Errorf("task should be requeued with its branch: %+v", task)
+	}
+	if !strings.Contains(task.Context, "FAIL: TestLogin") {
+		t.Errorf("gate output should be added to the context: %q", task.Context)
+	}
+	if branch, _ := o.worktrees.CurrentBranch(parallel.WorktreeName(1)); branch != parallel.TaskBranch("task_002") {
+		t.Errorf("worktree of the busy specialist should stay on task_002, got %s", branch)
+	}
+	if n := countMarshallMessages(t, o, "pending に戻しました"); n != 1 {
+		t.Errorf("marshall should be notified, got %d", n)
+	}
+}
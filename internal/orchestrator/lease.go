@@ -0,0 +1,281 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"gopkg.in/yaml.v3"
+)
+
+// 実行中のタスクが変更予定のパスに持つリース
+type Lease struct {
+	TaskID     string    `yaml:"task_id"`
+	Specialist string    `yaml:"specialist"`
+	Paths      []string  `yaml:"paths"`
+	AcquiredAt time.Time `yaml:"acquired_at"`
+}
+
+// リースの重なり
+type LeaseConflict struct {
+	// 重なったリースを持つタスク
+	TaskID     string
+	Specialist string
+	// 重なったパス（"<自分のパス> <-> <相手のパス>"）
+	Paths []string
+}
+
+// 実行中のタスク同士のリースの重なり
+type LeaseOverlap struct {
+	TaskID string
+	With   string
+	// 重なったパス（"<TaskID のパス> <-> <With のパス>"）
+	Paths []string
+}
+
+// リース同士の重なりを抽出（同じ組み合わせは 1 回だけ）
+func OverlappingLeases(leases []Lease) []LeaseOverlap {
+	var overlaps []LeaseOverlap
+	for i, lease := range leases {
+		for _, c := range leaseConflicts(lease.Paths, lease.TaskID, leases[i+1:]) {
+			overlaps = append(overlaps, LeaseOverlap{TaskID: lease.TaskID, With: c.TaskID, Paths: c.Paths})
+		}
+	}
+	return overlaps
+}
+
+// リース登録ファイルの内容
+type leaseList struct {
+	Leases []Lease `yaml:"leases"`
+}
+
+// 実行中のタスクのリースを記録する
+// リースはタスクファイルから再構築する（Marshall が直接割り当てたタスクも対象にするため）
+// 保存先: agents/queue/leases.yaml
+type LeaseRegistry struct {
+	path string
+	mu   sync.Mutex
+}
+
+// 新しい LeaseRegistry を作成
+func NewLeaseRegistry(queueDir string) *LeaseRegistry {
+	return &LeaseRegistry{
+		path: filepath.Join(queueDir, "leases.yaml"),
+	}
+}
+
+// 実行中のタスクからリースを再構築して保存
+// 既存のリースは取得時刻を引き継ぎ、終了したタスクのリースは解放する
+func (r *LeaseRegistry) Sync(tasks []communication.Task, now time.Time) ([]Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	acquired := make(map[string]time.Time, len(current.Leases))
+	for _, lease := range current.Leases {
+		acquired[lease.TaskID] = lease.AcquiredAt
+	}
+
+	list := &leaseList{}
+	for _, task := range tasks {
+		if !holdsLease(task) {
+			continue
+		}
+		paths := taskPaths(task)
+		if len(paths) == 0 {
+			continue
+		}
+
+		at, ok := acquired[task.TaskID]
+		if !ok {
+			at = now
+		}
+		list.Leases = append(list.Leases, Lease{
+			TaskID:     task.TaskID,
+			Specialist: task.SpecialistID,
+			Paths:      paths,
+			AcquiredAt: at,
+		})
+	}
+
+	if err := r.write(list); err != nil {
+		return nil, err
+	}
+	return list.Leases, nil
+}
+
+// 保存されているリース一覧を取得
+func (r *LeaseRegistry) List() ([]Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return list.Leases, nil
+}
+
+// リース登録ファイルを読み込む（存在しない場合は空）
+func (r *LeaseRegistry) read() (*leaseList, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &leaseList{}, nil
+		}
+		return nil, fmt.Errorf("failed to read leases: %w", err)
+	}
+
+	var list leaseList
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal leases: %w", err)
+	}
+	return &list, nil
+}
+
+// リース登録ファイルに書き込む
+func (r *LeaseRegistry) write(list *leaseList) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal leases: %w", err)
+	}
+
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write leases: %w", err)
+	}
+	return nil
+}
+
+// リースを持つタスクか（担当が決まっていて終了していない）
+func holdsLease(task communication.Task) bool {
+	return task.SpecialistID != "" && !task.IsFinished()
+}
+
+// タスクの変更予定パス
+// paths がなければ成果物のうちパスの形式のものを使う
+func taskPaths(task communication.Task) []string {
+	if len(task.Paths) > 0 {
+		return task.Paths
+	}
+	return pathLike(task.Deliverables)
+}
+
+// パスの形式の文字列を抽出（空白を含まず、ディレクトリ区切りを含む）
+func pathLike(items []string) []string {
+	var paths []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.ContainsAny(item, " \t") || !strings.Contains(item, "/") {
+			continue
+		}
+		paths = append(paths, item)
+	}
+	return paths
+}
+
+// 変更予定パスと重なるリースを抽出（taskID 自身のリースは除く）
+func leaseConflicts(paths []string, taskID string, leases []Lease) []LeaseConflict {
+	var conflicts []LeaseConflict
+	for _, lease := range leases {
+		if lease.TaskID == taskID {
+			continue
+		}
+
+		var overlaps []string
+		for _, p := range paths {
+			for _, q := range lease.Paths {
+				if pathsOverlap(p, q) {
+					overlaps = append(overlaps, p+" <-> "+q)
+				}
+			}
+		}
+		if len(overlaps) > 0 {
+			conflicts = append(conflicts, LeaseConflict{TaskID: lease.TaskID, Specialist: lease.Specialist, Paths: overlaps})
+		}
+	}
+	return conflicts
+}
+
+// 2 つの変更予定パスが重なるか
+// ワイルドカードを含むパスは、ワイルドカードより前のディレクトリ全体として保守的に判定する
+func pathsOverlap(a, b string) bool {
+	a, b = leaseScope(a), leaseScope(b)
+	return isWithin(a, b) || isWithin(b, a)
+}
+
+// ファイルが変更予定パスに含まれるか（** は任意の深さのディレクトリに一致）
+func coversPath(pattern, file string) bool {
+	pattern, file = normalizePath(pattern), normalizePath(file)
+	if !hasGlob(pattern) {
+		return isWithin(file, pattern)
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+// リースの範囲（ワイルドカードを含む場合はその前のディレクトリ）
+func leaseScope(p string) string {
+	p = normalizePath(p)
+	i := strings.IndexAny(p, "*?[")
+	if i < 0 {
+		return p
+	}
+	j := strings.LastIndex(p[:i], "/")
+	if j < 0 {
+		return ""
+	}
+	return p[:j]
+}
+
+// パスを正規化（スラッシュ区切り、先頭の ./ と末尾の / を除く）
+func normalizePath(p string) string {
+	p = path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// ワイルドカードを含むか
+func hasGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// p が dir 以下か（dir が空ならすべてを含む）
+func isWithin(p, dir string) bool {
+	return dir == "" || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// パスの要素ごとに glob を照合
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+func TestPathsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"internal/auth", "internal/auth/login.go", true},
+		{"internal/auth/", "./internal/auth", true},
+		{"internal/auth", "internal/authz", false},
+		{"internal/auth/login.go", "internal/auth/logout.go", false},
+		{"internal/**/*_test.go", "internal/auth/login.go", true},
+		{"internal/auth/*.go", "internal/billing", false},
+		{"docs/*.md", "internal/*.go", false},
+		{"*.go", "README.md", true},
+	}
+
+	for _, tt := range tests {
+		if got := pathsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("pathsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCoversPath(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"internal/auth", "internal/auth/login.go", true},
+		{"internal/auth/login.go", "internal/auth/login.go", true},
+		{"internal/auth", "internal/authz/login.go", false},
+		{"internal/**/*_test.go", "internal/auth/login_test.go", true},
+		{"internal/**/*_test.go", "internal/login_test.go", true},
+		{"internal/**/*_test.go", "internal/auth/login.go", false},
+		{"internal/auth/*.go", "internal/auth/sub/x.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := coversPath(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("coversPath(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestTaskPaths(t *testing.T) {
+	declared := communication.Task{Paths: []string{"internal/auth"}, Deliverables: []string{"src/x.go"}}
+	if got := taskPaths(declared); strings.Join(got, ",") != "internal/auth" {
+		t.Errorf("declared paths should be used: %v", got)
+	}
+
+	// paths がなければパスの形式の成果物から推定する
+	inferred := communication.Task{Deliverables: []string{"POST /auth/login エンドポイント", "src/auth/login.go", "ユニットテスト"}}
+	if got := taskPaths(inferred); strings.Join(got, ",") != "src/auth/login.go" {
+		t.Errorf("paths should be inferred from deliverables: %v", got)
+	}
+}
+
+func TestLeaseRegistry_Sync(t *testing.T) {
+	r := NewLeaseRegistry(t.TempDir())
+	first := time.Date(2026, 2, 8, 10, 0, 0, 0, time.UTC)
+
+	tasks := []communication.Task{
+		{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusInProgress, Paths: []string{"internal/auth"}},
+		{TaskID: "task_002", Status: communication.TaskStatusPending, Paths: []string{"internal/auth"}},
+		{TaskID: "task_003", SpecialistID: "specialist_2", Status: communication.TaskStatusAssigned},
+	}
+	leases, err := r.Sync(tasks, first)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	// 未割当のタスクと変更予定パスのないタスクはリースを持たない
+	if len(leases) != 1 || leases[0].TaskID != "task_001" {
+		t.Fatalf("unexpected leases: %+v", leases)
+	}
+
+	// 既存のリースは取得時刻を引き継ぐ
+	tasks[1].SpecialistID = "specialist_2"
+	tasks[1].Status = communication.TaskStatusAssigned
+	leases, err = r.Sync(tasks, first.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(leases) != 2 || !leases[0].AcquiredAt.Equal(first) || !leases[1].AcquiredAt.Equal(first.Add(time.Hour)) {
+		t.Errorf("unexpected leases: %+v", leases)
+	}
+
+	// 終了したタスクのリースは解放する
+	tasks[0].Status = communication.TaskStatusCompleted
+	if _, err := r.Sync(tasks, first.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	saved, err := r.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(saved) != 1 || saved[0].TaskID != "task_002" {
+		t.Errorf("lease of finished task should be released: %+v", saved)
+	}
+}
+
+func TestLeaseConflicts(t *testing.T) {
+	leases := []Lease{
+		{TaskID: "task_001", Specialist: "specialist_1", Paths: []string{"internal/auth"}},
+		{TaskID: "task_002", Specialist: "specialist_2", Paths: []string{"docs"}},
+		{TaskID: "task_003", Specialist: "specialist_3", Paths: []string{"internal/auth/login.go"}},
+	}
+
+	conflicts := leaseConflicts([]string{"internal/auth/session.go"}, "task_004", leases)
+	if len(conflicts) != 1 || conflicts[0].TaskID != "task_001" || conflicts[0].Paths[0] != "internal/auth/session.go <-> internal/auth" {
+		t.Errorf("unexpected conflicts: %+v", conflicts)
+	}
+
+	// 自分自身のリースは除く
+	if conflicts := leaseConflicts([]string{"docs"}, "task_002", leases); len(conflicts) != 0 {
+		t.Errorf("own lease should be ignored: %+v", conflicts)
+	}
+
+	overlaps := OverlappingLeases(leases)
+	if len(overlaps) != 1 || overlaps[0].TaskID != "task_001" || overlaps[0].With != "task_003" {
+		t.Errorf("unexpected overlaps: %+v", overlaps)
+	}
+}
+
+func TestWarnLeaseConflicts(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	o.warnLeaseConflicts("task_002", "specialist_2", []LeaseConflict{
+		{TaskID: "task_001", Specialist: "specialist_1", Paths: []string{"internal/auth <-> internal/auth/login.go"}},
+	})
+
+	messages, err := o.inbox.Read(AgentMarshall)
+	if err != nil {
+		t.Fatalf("failed to read inbox: %v", err)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "task_001") || !strings.Contains(messages[0].Message, "internal/auth/login.go") {
+		t.Errorf("expected lease warning, got %+v", messages)
+	}
+}
@@ -0,0 +1,389 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 指令のマージ結果
+type MergeReport struct {
+	CommandID string
+	// 統合ブランチ
+	Branch string
+	// 統合ブランチの worktree
+	Worktree string
+	// 今回マージしたタスク
+	Merged []MergedTask
+	// 既にマージ済みだったタスク
+	AlreadyMerged []string
+	// マージしなかったタスクと理由
+	Skipped []SkippedTask
+	// コンフリクトしたタスク（なければ nil）
+	Conflict *MergeConflict
+}
+
+// マージしたタスク
+type MergedTask struct {
+	TaskID string
+	Branch string
+	Commit string
+}
+
+// マージしなかったタスク
+type SkippedTask struct {
+	TaskID string
+	Reason string
+}
+
+// コンフリクトの内容
+type MergeConflict struct {
+	TaskID string
+	Branch string
+	Files  []string
+	// 作成したコンフリクト解消タスク
+	ResolutionTask string
+	// 解消タスクの担当（未割当なら空）
+	AssignedTo string
+}
+
+// 依存関係の順（依存されるタスクが先）に並べる
+// 同じ順位のタスクはタイムスタンプ順を保つ
+func dependencyOrder(tasks []communication.Task) ([]communication.Task, error) {
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		index[task.TaskID] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(tasks))
+	var ordered []communication.Task
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch marks[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, tasks[i].TaskID), " -> "))
+		}
+
+		marks[i] = visiting
+		for _, dep := range tasks[i].Dependencies {
+			// 指令外のタスクへの依存は順序に影響しない
+			if j, ok := index[dep]; ok {
+				if err := visit(j, append(path, tasks[i].TaskID)); err != nil {
+					return err
+				}
+			}
+		}
+		marks[i] = visited
+		ordered = append(ordered, tasks[i])
+		return nil
+	}
+
+	for i := range tasks {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// 指令の完了タスクのブランチを依存関係の順に統合ブランチへマージ
+// 秘密情報を含むブランチはマージせず、承認が必要な変更を含むブランチは承認を依頼してマージしない
+// コンフリクトしたらマージを中止して統合ブランチをマージ前に戻し、コンフリクト解消タスクを作成する
+func (o *Orchestrator) MergeCommand(commandID string) (*MergeReport, error) {
+	if !o.worktrees.IsRepository() {
+		return nil, fmt.Errorf("project root is not a git repository: %s", o.projectRoot)
+	}
+
+	tasks, err := o.commandTasks(commandID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks found for command: %s", commandID)
+	}
+
+	ordered, err := dependencyOrder(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := o.worktrees.BaseCommit()
+	if err != nil {
+		return nil, err
+	}
+	name := parallel.IntegrationWorktreeName(commandID)
+	branch := parallel.IntegrationBranch(commandID)
+	path, err := o.worktrees.Ensure(name, branch, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare integration worktree: %w", err)
+	}
+
+	report := &MergeReport{CommandID: commandID, Branch: branch, Worktree: path}
+	// マージできなかったタスク（依存するタスクもマージしない）
+	blocked := make(map[string]bool)
+
+	for _, task := range ordered {
+		if reason := o.mergeBlocker(task, blocked); reason != "" {
+			report.Skipped = append(report.Skipped, SkippedTask{TaskID: task.TaskID, Reason: reason})
+			blocked[task.TaskID] = true
+			continue
+		}
+
+		taskBranch := task.Branch
+		if taskBranch == "" {
+			taskBranch = parallel.TaskBranch(task.TaskID)
+		}
+		if !o.worktrees.BranchExists(taskBranch) {
+			report.Skipped = append(report.Skipped, SkippedTask{TaskID: task.TaskID, Reason: "branch not found: " + taskBranch})
+			blocked[task.TaskID] = true
+			continue
+		}
+
+		// 秘密情報を含むブランチは取り除かれるまでマージしない
+		secrets, err := o.gateSecrets(task, taskBranch)
+		if err != nil {
+			return report, err
+		}
+		if len(secrets) > 0 {
+			report.Skipped = append(report.Skipped, SkippedTask{TaskID: task.TaskID, Reason: fmt.Sprintf("secrets detected: %d finding(s)", len(secrets))})
+			blocked[task.TaskID] = true
+			continue
+		}
+
+		// 承認が必要な変更は承認されるまでマージしない
+		approval, err := o.gateMerge(task, taskBranch)
+		if err != nil {
+			return report, err
+		}
+		if approval != nil {
+			report.Skipped = append(report.Skipped, SkippedTask{TaskID: task.TaskID, Reason: "awaiting approval: " + approval.ID})
+			blocked[task.TaskID] = true
+			continue
+		}
+
+		message := fmt.Sprintf("Merge %s (%s) into %s", task.TaskID, taskBranch, branch)
+		result, err := o.worktrees.Merge(name, taskBranch, message)
+		if errors.Is(err, parallel.ErrMergeConflict) {
+			conflict, err := o.createConflictTask(task, taskBranch, branch, result.Conflicts)
+			if err != nil {
+				return report, err
+			}
+			report.Conflict = conflict
+			// 以降のタスクはコンフリクト解消後に再度マージする
+			for _, rest := range ordered {
+				if rest.TaskID != task.TaskID && !isMerged(report, rest.TaskID) && !isSkipped(report, rest.TaskID) {
+					report.Skipped = append(report.Skipped, SkippedTask{TaskID: rest.TaskID, Reason: "merge stopped by conflict in " + task.TaskID})
+				}
+			}
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+
+		if result.AlreadyMerged {
+			report.AlreadyMerged = append(report.AlreadyMerged, task.TaskID)
+			continue
+		}
+
+		report.Merged = append(report.Merged, MergedTask{TaskID: task.TaskID, Branch: taskBranch, Commit: result.Commit})
+		o.recordMerge(commandID, task.TaskID, taskBranch, result.Commit)
+	}
+
+	return report, nil
+}
+
+// マージコミットと取り込んだコミットをタスクと指令に記録（ロールバックに使う）
+func (o *Orchestrator) recordMerge(commandID, taskID, branch, commit string) {
+	commits, err := o.worktrees.MergedCommits(commit)
+	if err != nil {
+		log.Printf("warning: failed to list commits merged from %s: %v", taskID, err)
+	}
+
+	err = o.tasks.Update(taskID, func(t *communication.Task) error {
+		t.MergedCommit = commit
+		t.Commits = commits
+		return nil
+	})
+	if err != nil {
+		log.Printf("warning: failed to record merge commit of %s: %v", taskID, err)
+	}
+
+	merge := communication.CommandMerge{Task: taskID, Branch: branch, Commit: commit, Commits: commits, MergedAt: time.Now()}
+	err = o.commands.Update(commandID, func(c *communication.Command) error {
+		c.Merges = append(c.Merges, merge)
+		return nil
+	})
+	// 指令ファイルのないタスクはタスクにだけ記録する
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("warning: failed to record merge of %s in %s: %v", taskID, commandID, err)
+	}
+}
+
+// タスクをマージできない理由（マージできる場合は空）
+// レビューを使う場合は、レビュー・コンフリクト解消のタスクを除き、レビューが承認されるまでマージしない
+func (o *Orchestrator) mergeBlocker(task communication.Task, blocked map[string]bool) string {
+	if task.Status != communication.TaskStatusCompleted {
+		return fmt.Sprintf("task is %s", task.Status)
+	}
+	if task.Review != nil && !task.Review.IsApproved() {
+		return fmt.Sprintf("review is %s: %s", task.Review.Verdict, task.Review.Task)
+	}
+	if task.Review == nil && o.config.Review.Enabled && task.ReviewOf == "" && task.ResolvesConflictOf == "" {
+		return "review not requested"
+	}
+	for _, dep := range task.Dependencies {
+		if blocked[dep] {
+			return "dependency not merged: " + dep
+		}
+	}
+	return ""
+}
+
+// 指令に属するタスクを取得（コンフリクト解消・レビュー・並列試行のタスクは除く）
+func (o *Orchestrator) commandTasks(commandID string) ([]communication.Task, error) {
+	all, err := o.tasks.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []communication.Task
+	for _, task := range all {
+		if task.CommandID == commandID && task.ResolvesConflictOf == "" && task.ReviewOf == "" && task.AttemptOf == "" {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Timestamp.Before(tasks[j].Timestamp)
+	})
+	return tasks, nil
+}
+
+// コンフリクト解消タスクの ID（例: task_001_conflict、2 回目以降は task_001_conflict_2）
+func conflictTaskID(taskID string, n int) string {
+	if n <= 1 {
+		return taskID + "_conflict"
+	}
+	return fmt.Sprintf("%s_conflict_%d", taskID, n)
+}
+
+// コンフリクト解消タスクを作成し、元のタスクの担当 Specialist に割り当てる
+// 担当がいなければ未割当のまま Marshall に通知する
+func (o *Orchestrator) createConflictTask(task communication.Task, taskBranch, integrationBranch string, files []string) (*MergeConflict, error) {
+	conflict := &MergeConflict{
+		TaskID: task.TaskID,
+		Branch: taskBranch,
+		Files:  files,
+	}
+
+	// 解消前に再実行した場合は同じタスクを使う。終了した解消タスクは残し、次の番号で作成する
+	for n := 1; ; n++ {
+		conflict.ResolutionTask = conflictTaskID(task.TaskID, n)
+		existing, err := o.tasks.ReadByID(conflict.ResolutionTask)
+		if err != nil {
+			break
+		}
+		if !existing.IsFinished() {
+			conflict.AssignedTo = existing.SpecialistID
+			return conflict, nil
+		}
+	}
+
+	// 元のタスクの担当に割り当て、worktree をタスクのブランチに切り替える
+	assignee := ""
+	worktree := ""
+	if info, ok, err := o.registry.Get(task.SpecialistID); err == nil && ok && info.Type == AgentSpecialist {
+		path, err := o.switchSpecialistBranch(info.Name, taskBranch)
+		if err != nil {
+			log.Printf("warning: %s の worktree を %s に切り替えられないため未割当にします: %v", info.Name, taskBranch, err)
+		} else {
+			assignee = info.Name
+			worktree = path
+		}
+	}
+
+	now := time.Now()
+	resolution := &communication.Task{
+		TaskID:       conflict.ResolutionTask,
+		SpecialistID: assignee,
+		CommandID:    task.CommandID,
+		Objective:    fmt.Sprintf("%s を %s にマージする際のコンフリクトを解消する", taskBranch, integrationBranch),
+		Deliverables: files,
+		Context: fmt.Sprintf("%s のブランチ %s が統合ブランチ %s とコンフリクトしました。"+
+			"%s で %s をマージしてコンフリクトを解消し、コミットしてから完了報告してください。"+
+			"git reset --hard や push --force は使用しないこと。", task.TaskID, taskBranch, integrationBranch,
+			taskBranch, integrationBranch),
+		Status:             communication.TaskStatusPending,
+		Timestamp:          now,
+		Branch:             taskBranch,
+		Worktree:           worktree,
+		ResolvesConflictOf: task.TaskID,
+	}
+	if assignee != "" {
+		resolution.Status = communication.TaskStatusAssigned
+		resolution.AssignedAt = now
+	}
+	if err := o.tasks.Write(resolution); err != nil {
+		return nil, fmt.Errorf("failed to create conflict resolution task: %w", err)
+	}
+	conflict.AssignedTo = assignee
+
+	if assignee != "" {
+		message := fmt.Sprintf("マージでコンフリクトが発生しました。コンフリクト解消タスク %s を割り当てました: %s を確認してください",
+			resolution.TaskID, resolution.Path())
+		if err := o.inbox.Write(assignee, message, communication.MessageTypeTaskAssigned, "bastion"); err != nil {
+			log.Printf("warning: failed to notify %s: %v", assignee, err)
+		}
+	}
+
+	message := fmt.Sprintf("%s のマージでコンフリクトが発生しました（%s）。マージを中止し、コンフリクト解消タスク %s を作成しました",
+		task.TaskID, strings.Join(files, ", "), resolution.TaskID)
+	if assignee == "" {
+		message += "。担当が未割当のため割り当ててください"
+	}
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeTaskAssigned, "bastion"); err != nil {
+		log.Printf("warning: failed to notify marshall: %v", err)
+	}
+
+	return conflict, nil
+}
+
+// 今回マージしたタスクか
+func isMerged(report *MergeReport, taskID string) bool {
+	for _, m := range report.Merged {
+		if m.TaskID == taskID {
+			return true
+		}
+	}
+	for _, id := range report.AlreadyMerged {
+		if id == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// マージしなかったタスクか
+func isSkipped(report *MergeReport, taskID string) bool {
+	for _, s := range report.Skipped {
+		if s.TaskID == taskID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+func TestDependencyOrder(t *testing.T) {
+	tasks := []communication.Task{
+		{TaskID: "task_003", Dependencies: []string{"task_002"}},
+		{TaskID: "task_001"},
+		{TaskID: "task_002", Dependencies: []string{"task_001", "task_999"}},
+		{TaskID: "task_004"},
+	}
+
+	ordered, err := dependencyOrder(tasks)
+	if err != nil {
+		t.Fatalf("dependencyOrder failed: %v", err)
+	}
+
+	var ids []string
+	for _, task := range ordered {
+		ids = append(ids, task.TaskID)
+	}
+	if got := strings.Join(ids, ","); got != "task_001,task_002,task_003,task_004" {
+		t.Errorf("unexpected order: %s", got)
+	}
+
+	cyclic := []communication.Task{
+		{TaskID: "task_001", Dependencies: []string{"task_002"}},
+		{TaskID: "task_002", Dependencies: []string{"task_001"}},
+	}
+	if _, err := dependencyOrder(cyclic); err == nil {
+		t.Error("expected error for dependency cycle")
+	}
+}
+
+// Specialist の worktree でタスクのブランチにファイルをコミットし、タスクを completed にする
+func completeTaskOnBranch(t *testing.T, o *Orchestrator, specialist string, task *communication.Task, file, content string) {
+	t.Helper()
+
+	task.SpecialistID = specialist
+	task.Status = communication.TaskStatusCompleted
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.CheckoutTask(specialist, task.TaskID); err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+
+	info, _, _ := o.registry.Get(specialist)
+	if err := os.WriteFile(filepath.Join(info.Worktree, file), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"add", file},
+		{"commit", "-q", "-m", task.TaskID},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", info.Worktree}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	if err := o.releaseTaskWorktree(specialist); err != nil {
+		t.Fatalf("releaseTaskWorktree failed: %v", err)
+	}
+}
+
+// worktree を使う Specialist を登録
+func registerWorktreeSpecialist(t *testing.T, o *Orchestrator, index int) string {
+	t.Helper()
+
+	path, err := o.prepareSpecialistWorktree(index, nil)
+	if err != nil {
+		t.Fatalf("prepareSpecialistWorktree failed: %v", err)
+	}
+	info := AgentInfo{Name: agentName(AgentSpecialist, index), Type: AgentSpecialist, Index: index, Worktree: path}
+	if err := o.registry.Register(info); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	return info.Name
+}
+
+func TestMergeCommand(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	now := time.Now()
+
+	// task_002 は task_001 に依存するが先に作成されている
+	completeTaskOnBranch(t, o, sp1, &communication.Task{
+		TaskID: "task_002", CommandID: "cmd_001", Timestamp: now, Dependencies: []string{"task_001"},
+	}, "b.txt", "b\n")
+	completeTaskOnBranch(t, o, sp1, &communication.Task{
+		TaskID: "task_001", CommandID: "cmd_001", Timestamp: now.Add(time.Second),
+	}, "a.txt", "a\n")
+	if err := o.tasks.Write(&communication.Task{
+		TaskID: "task_003", CommandID: "cmd_001", Timestamp: now.Add(2 * time.Second), Status: communication.TaskStatusInProgress,
+	}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	report, err := o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if len(report.Merged) != 2 || report.Merged[0].TaskID != "task_001" || report.Merged[1].TaskID != "task_002" {
+		t.Errorf("tasks should be merged in dependency order: %+v", report.Merged)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].TaskID != "task_003" {
+		t.Errorf("unfinished task should be skipped: %+v", report.Skipped)
+	}
+	if report.Conflict != nil {
+		t.Errorf("unexpected conflict: %+v", report.Conflict)
+	}
+
+	// マージコミットがタスクに記録される
+	task, _ := o.tasks.ReadByID("task_001")
+	if task.MergedCommit != report.Merged[0].Commit {
+		t.Errorf("merged commit should be recorded: %+v", task)
+	}
+	if _, err := os.Stat(filepath.Join(report.Worktree, "b.txt")); err != nil {
+		t.Errorf("integration worktree should contain merged files: %v", err)
+	}
+
+	// 再実行してもマージ済みのタスクは再マージしない
+	report, err = o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if len(report.Merged) != 0 || len(report.AlreadyMerged) != 2 {
+		t.Errorf("expected tasks already merged, got %+v", report)
+	}
+}
+
+func TestMergeCommand_RequiresReview(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	o.config.Review.Enabled = true
+
+	// レビューを依頼していないタスクもマージしない
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001", CommandID: "cmd_001"}, "a.txt", "a\n")
+	report, err := o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if len(report.Merged) != 0 || len(report.Skipped) != 1 || report.Skipped[0].Reason != "review not requested" {
+		t.Fatalf("unreviewed task should not be merged: %+v", report)
+	}
+
+	// 人間が承認すればマージする
+	if _, err := o.ApproveReview("task_001", "alice"); err != nil {
+		t.Fatalf("ApproveReview failed: %v", err)
+	}
+	report, err = o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if len(report.Merged) != 1 || report.Merged[0].TaskID != "task_001" {
+		t.Errorf("approved task should be merged: %+v", report)
+	}
+}
+
+func TestMergeCommand_Conflict(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	sp2 := registerWorktreeSpecialist(t, o, 2)
+	now := time.Now()
+
+	completeTaskOnBranch(t, o, sp1, &communication.Task{
+		TaskID: "task_001", CommandID: "cmd_001", Timestamp: now,
+	}, "shared.txt", "from task_001\n")
+	completeTaskOnBranch(t, o, sp2, &communication.Task{
+		TaskID: "task_002", CommandID: "cmd_001", Timestamp: now.Add(time.Second),
+	}, "shared.txt", "from task_002\n")
+	completeTaskOnBranch(t, o, sp1, &communication.Task{
+		TaskID: "task_003", CommandID: "cmd_001", Timestamp: now.Add(2 * time.Second), Dependencies: []string{"task_002"},
+	}, "c.txt", "c\n")
+
+	report, err := o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if len(report.Merged) != 1 || report.Merged[0].TaskID != "task_001" {
+		t.Errorf("only task_001 should be merged: %+v", report.Merged)
+	}
+	if report.Conflict == nil || report.Conflict.TaskID != "task_002" {
+		t.Fatalf("expected conflict in task_002, got %+v", report.Conflict)
+	}
+	if report.Conflict.AssignedTo != sp2 || strings.Join(report.Conflict.Files, ",") != "shared.txt" {
+		t.Errorf("unexpected conflict: %+v", report.Conflict)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].TaskID != "task_003" {
+		t.Errorf("remaining tasks should be skipped: %+v", report.Skipped)
+	}
+
+	// 統合ブランチはマージ前の状態に戻る
+	dirty, err := o.worktrees.IsDirty("integration-cmd_001")
+	if err != nil || dirty {
+		t.Errorf("integration worktree should be clean: dirty=%v err=%v", dirty, err)
+	}
+
+	// コンフリクト解消タスクが元の担当に割り当てられ、worktree がタスクのブランチに切り替わる
+	resolution, err := o.tasks.ReadByID("task_002_conflict")
+	if err != nil {
+		t.Fatalf("conflict resolution task should be created: %v", err)
+	}
+	if resolution.SpecialistID != sp2 || resolution.Status != communication.TaskStatusAssigned ||
+		resolution.Branch != "bastion/task/task_002" || resolution.ResolvesConflictOf != "task_002" {
+		t.Errorf("unexpected resolution task: %+v", resolution)
+	}
+	if current, _ := o.worktrees.CurrentBranch("sp2"); current != "bastion/task/task_002" {
+		t.Errorf("sp2 should be switched to the task branch, got %s", current)
+	}
+
+	for _, agent := range []string{sp2, AgentMarshall} {
+		messages, err := o.inbox.Read(agent)
+		if err != nil {
+			t.Fatalf("failed to read inbox: %v", err)
+		}
+		if len(messages) != 1 || !strings.Contains(messages[0].Message, "task_002_conflict") {
+			t.Errorf("%s should be notified of the conflict, got %+v", agent, messages)
+		}
+	}
+
+	// 再実行しても同じ解消タスクを使う
+	report, err = o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if report.Conflict == nil || report.Conflict.ResolutionTask != "task_002_conflict" || report.Conflict.AssignedTo != sp2 {
+		t.Errorf("existing resolution task should be reused: %+v", report.Conflict)
+	}
+
+	// 解消タスクが終わってもコンフリクトが残れば、終わったタスクは上書きせず次の解消タスクを作る
+	resolution.Status = communication.TaskStatusCompleted
+	resolution.AddEvent(time.Now(), communication.TaskEventStarted, "")
+	if err := o.tasks.Write(resolution); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	report, err = o.MergeCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("MergeCommand failed: %v", err)
+	}
+	if report.Conflict == nil || report.Conflict.ResolutionTask != "task_002_conflict_2" || report.Conflict.AssignedTo != sp2 {
+		t.Errorf("new resolution task should be created: %+v", report.Conflict)
+	}
+	if done, _ := o.tasks.ReadByID("task_002_conflict"); done.Status != communication.TaskStatusCompleted || len(done.History) != 1 {
+		t.Errorf("finished resolution task should be kept: %+v", done)
+	}
+}
+
+func TestMergeCommand_NoTasks(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+
+	if _, err := o.MergeCommand("cmd_999"); err == nil {
+		t.Error("expected error for command without tasks")
+	}
+}
@@ -6,8 +6,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/evaluation"
 	"github.com/t-ishitsuka/bastion-core/internal/parallel"
 )
 
@@ -26,17 +32,76 @@ type Orchestrator struct {
 	queueDir        string
 	specialistCount int
 	watcher         *communication.Watcher
+	inbox           *communication.InboxManager
+	tasks           *communication.TaskManager
+	commands        *communication.CommandQueueManager
+	registry        *Registry
+	permissions     *communication.PermissionManager
+	worktrees       *parallel.WorktreeManager
+	reports         *communication.ReportManager
+	leases          *LeaseRegistry
+	approvals       *communication.ApprovalManager
+	evaluations     *evaluation.Manager
+	config          *config.Config
+
+	// エージェントごとのエスカレーション状態
+	escalations map[string]*escalationState
+	// idle になるまで保留中の nudge
+	deferred map[string]bool
+	// エージェントごとの再起動状態
+	restarts map[string]*restartState
+	// タスクごとの停滞検知状態
+	stalls map[string]*stallState
+	// 品質ゲートを実行中のタスク
+	gating map[string]bool
+	// カバレッジを比較中のタスク
+	covering map[string]bool
+	// 並列試行の勝者を選んでいるタスク
+	selecting map[string]bool
+	// レポートファイルごとの処理済み（bastion が書き戻した）内容の指紋
+	reportDigests map[string]string
+	mu            sync.Mutex
+	done          chan struct{}
+	stopOnce      sync.Once
 }
 
 // 新しい Orchestrator を作成
-func NewOrchestrator(projectRoot string, specialistCount int) *Orchestrator {
+// 設定ファイルが不正な場合は作成しない（承認・ゲートなどを無効にしたデフォルト設定で動かさない）
+func NewOrchestrator(projectRoot string, specialistCount int) (*Orchestrator, error) {
+	queueDir := filepath.Join(projectRoot, "agents", "queue")
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", config.FileName, err)
+	}
+
 	return &Orchestrator{
 		sm:              parallel.NewSessionManager(),
 		projectRoot:     projectRoot,
 		agentsDir:       filepath.Join(projectRoot, "agents"),
-		queueDir:        filepath.Join(projectRoot, "agents", "queue"),
+		queueDir:        queueDir,
 		specialistCount: specialistCount,
-	}
+		inbox:           communication.NewInboxManager(queueDir),
+		tasks:           communication.NewTaskManager(queueDir),
+		commands:        communication.NewCommandQueueManager(queueDir),
+		registry:        NewRegistry(queueDir),
+		permissions:     communication.NewPermissionManager(queueDir),
+		worktrees:       parallel.NewWorktreeManager(projectRoot),
+		reports:         communication.NewReportManager(queueDir),
+		leases:          NewLeaseRegistry(queueDir),
+		approvals:       communication.NewApprovalManager(queueDir),
+		evaluations:     evaluation.NewManager(projectRoot),
+		config:          cfg,
+		escalations:     make(map[string]*escalationState),
+		deferred:        make(map[string]bool),
+		restarts:        make(map[string]*restartState),
+		stalls:          make(map[string]*stallState),
+		gating:          make(map[string]bool),
+		covering:        make(map[string]bool),
+		selecting:       make(map[string]bool),
+		reportDigests:   make(map[string]string),
+		done:            make(chan struct{}),
+	}, nil
 }
 
 // すべてのエージェントを起動
@@ -63,6 +128,11 @@ func (o *Orchestrator) StartAll() error {
 		log.Printf("warning: failed to setup key bindings: %v", err)
 	}
 
+	// 前回セッションのエージェント登録を破棄
+	if err := o.registry.Reset(); err != nil {
+		log.Printf("warning: failed to reset agent registry: %v", err)
+	}
+
 	// Envoy を起動（メインウィンドウの左ペイン）
 	if err := o.StartAgent(AgentEnvoy, "main.0", 0); err != nil {
 		return fmt.Errorf("failed to start envoy: %w", err)
@@ -87,11 +157,21 @@ func (o *Orchestrator) StartAll() error {
 		}
 	}
 
+	// .claude/agents に定義された外部 Specialist を追加で起動
+	if err := o.startExternalSpecialists(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // 個別のエージェントを起動
 func (o *Orchestrator) StartAgent(agentType, target string, index int) error {
+	return o.startAgent(agentType, target, index, nil)
+}
+
+// エージェントを起動（spec を指定すると外部 Specialist として起動）
+func (o *Orchestrator) startAgent(agentType, target string, index int, spec *SpecialistConfig) error {
 	// エージェントディレクトリのパスを構築
 	agentDir := filepath.Join(o.agentsDir, agentType)
 
@@ -107,29 +187,136 @@ func (o *Orchestrator) StartAgent(agentType, target string, index int) error {
 	default:
 		label = agentType
 	}
+	if spec != nil {
+		label = fmt.Sprintf("%s (%s)", spec.Persona, spec.Name)
+	}
 
 	if err := o.sm.SetPaneTitle(target, label); err != nil {
 		log.Printf("warning: failed to set pane label: %v", err)
 	}
 
-	// claude コマンドを構築
-	// エージェントディレクトリに移動してから claude を起動
-	// claude は現在のディレクトリの CLAUDE.md を自動的に読み込む
-	// --add-dir でプロジェクトルートへのアクセスを許可
-	// ファイルアクセス権限は .claude/settings.local.json で管理
-	cmd := fmt.Sprintf("cd %s && claude --add-dir %s",
-		agentDir,
-		o.projectRoot,
-	)
+	name := agentName(agentType, index)
+	if spec != nil {
+		name = spec.Name
+	}
+
+	// Specialist は専用の worktree で作業させる
+	worktree := ""
+	if agentType == AgentSpecialist && o.useWorktrees() {
+		path, err := o.prepareSpecialistWorktree(index, spec)
+		if err != nil {
+			log.Printf("warning: failed to prepare worktree, using project root: %v", err)
+		} else {
+			worktree = path
+		}
+	}
+
+	// Specialist 同士が競合しないようポート範囲と作業用ディレクトリを割り当てる
+	var resources *Resources
+	if agentType == AgentSpecialist {
+		r, err := o.allocateResources(name)
+		if err != nil {
+			log.Printf("warning: failed to allocate resources for %s: %v", name, err)
+		} else {
+			resources = r
+		}
+	}
+
+	cmd := o.buildAgentCommand(agentDir, worktree, spec, resources)
 
 	// tmux send-keys でコマンドを送信
 	if err := o.sm.SendKeys(target, cmd, true); err != nil {
 		return fmt.Errorf("failed to send command: %w", err)
 	}
 
+	// ペインの追加・削除でインデックスがずれないよう、ペイン ID で登録
+	paneID, err := o.sm.PaneID(target)
+	if err != nil {
+		log.Printf("warning: failed to resolve pane id: %v", err)
+		paneID = target
+	}
+
+	// 再起動時に同じコマンドを使えるよう登録
+	info := AgentInfo{
+		Name:      name,
+		Type:      agentType,
+		Index:     index,
+		Target:    paneID,
+		Dir:       agentDir,
+		Command:   cmd,
+		StartedAt: time.Now(),
+		Worktree:  worktree,
+		Spec:      spec,
+		Resources: resources,
+	}
+	if err := o.registry.Register(info); err != nil {
+		log.Printf("warning: failed to register agent: %v", err)
+	}
+
 	return nil
 }
 
+// claude の起動コマンドを構築
+func (o *Orchestrator) buildAgentCommand(agentDir, worktree string, spec *SpecialistConfig, resources *Resources) string {
+	var prompts []string
+	if spec != nil {
+		// 外部 Specialist はペルソナをシステムプロンプトに追加
+		prompts = append(prompts, spec.SystemPrompt())
+	}
+
+	// 割り当てたリソースは環境変数で渡す（claude から起動するコマンドにも引き継がれる）
+	var env []string
+	if resources != nil {
+		env = resources.Env()
+		prompts = append(prompts, resourcesPrompt(resources))
+	}
+
+	var cmd string
+	if worktree == "" {
+		// エージェントディレクトリに移動してから claude を起動
+		// claude は現在のディレクトリの CLAUDE.md を自動的に読み込む
+		// --add-dir でプロジェクトルートへのアクセスを許可
+		// ファイルアクセス権限は .claude/settings.local.json で管理
+		cmd = fmt.Sprintf("cd %s && %sclaude --add-dir %s",
+			agentDir,
+			envPrefix(env),
+			o.projectRoot,
+		)
+	} else {
+		// worktree に移動してから claude を起動
+		// エージェントディレクトリの CLAUDE.md は追加ディレクトリから読み込ませる
+		env = append([]string{"CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD=1"}, env...)
+		cmd = fmt.Sprintf("cd %s && %sclaude --add-dir %s --add-dir %s",
+			worktree,
+			envPrefix(env),
+			agentDir,
+			o.projectRoot,
+		)
+		prompts = append(prompts, worktreePrompt(worktree, o.queueDir))
+	}
+
+	if len(prompts) > 0 {
+		cmd += " --append-system-prompt " + shellQuote(strings.Join(prompts, " "))
+	}
+	return cmd
+}
+
+// コマンドの前に付ける環境変数の指定（例: "A=1 B=2 "）
+func envPrefix(env []string) string {
+	if len(env) == 0 {
+		return ""
+	}
+	return strings.Join(env, " ") + " "
+}
+
+// エージェント名を決定（例: envoy, specialist_2）
+func agentName(agentType string, index int) string {
+	if agentType == AgentSpecialist {
+		return fmt.Sprintf("%s_%d", AgentSpecialist, index)
+	}
+	return agentType
+}
+
 // すべてのエージェントに wakeup を送信
 func (o *Orchestrator) WakeupAll() error {
 	// Envoy を wakeup（メインウィンドウの左ペイン）
@@ -172,10 +359,20 @@ func (o *Orchestrator) WakeupWithEscalation(agentType, target string, phase int)
 		}
 		return o.Wakeup(agentType, target)
 	case 3:
-		// Phase 3: /clear でセッションを強制リセット
+		// Phase 3: 引き継ぎノートを保存してから /clear でセッションを強制リセット
+		// ノートを保存できない場合は文脈が失われるため /clear を送信しない
+		notePath, err := o.writeHandoffNote(agentType)
+		if err != nil {
+			return fmt.Errorf("refusing to send /clear without handoff note: %w", err)
+		}
 		if err := o.sm.SendKeys(target, "/clear", true); err != nil {
 			return fmt.Errorf("failed to send /clear: %w", err)
 		}
+		// リセット後のセッションに引き継ぎノートの場所を伝える
+		resume := fmt.Sprintf("%s を読んでから inbox を確認", notePath)
+		if err := o.sm.SendKeys(target, resume, true); err != nil {
+			return fmt.Errorf("failed to send resume prompt: %w", err)
+		}
 		return nil
 	default:
 		return o.Wakeup(agentType, target)
@@ -192,9 +389,44 @@ func (o *Orchestrator) StartWatcher() error {
 
 	o.watcher = watcher
 
+	// 完了報告を監視し、変更予定パス外の変更を検出する
+	if err := os.MkdirAll(o.reports.Dir(), 0755); err != nil {
+		log.Printf("warning: failed to create reports directory: %v", err)
+	} else if err := watcher.Watch(o.reports.Dir()); err != nil {
+		log.Printf("warning: failed to watch reports: %v", err)
+	}
+
+	// Marshall の評価を監視し、テスト結果を反映する
+	if err := os.MkdirAll(o.evaluations.Dir(), 0755); err != nil {
+		log.Printf("warning: failed to create evaluations directory: %v", err)
+	} else if err := watcher.Watch(o.evaluations.Dir()); err != nil {
+		log.Printf("warning: failed to watch evaluations: %v", err)
+	}
+
 	// バックグラウンドでイベントを処理
 	go o.processWatcherEvents()
 
+	// エージェントの活動状態の監視を開始
+	go o.runActivityMonitor()
+
+	// 未処理メッセージの経過時間に応じたエスカレーションを開始
+	if o.config.Escalation.Enabled {
+		go o.runEscalation()
+	}
+
+	// in_progress タスクのタイムアウト・停滞検知を開始
+	if o.config.Timeouts.Enabled {
+		go o.runStallMonitor()
+	}
+
+	// 並列試行の勝者の選択を開始（スケジューラの有無によらない）
+	go o.runAttemptMonitor()
+
+	// 着手できるタスクの自動割り当てを開始
+	if o.config.Scheduler.Enabled {
+		go o.runScheduler()
+	}
+
 	return nil
 }
 
@@ -211,6 +443,22 @@ func (o *Orchestrator) processWatcherEvents() {
 
 			log.Printf("[watcher] イベント受信: %s (%s)", event.Path, event.Operation)
 
+			// 完了報告は変更予定パスと照合する
+			if o.isReportFile(event.Path) {
+				if err := o.handleReportChange(event.Path); err != nil {
+					log.Printf("[watcher] レポート処理エラー: %v", err)
+				}
+				continue
+			}
+
+			// 評価はテスト結果を反映する
+			if o.evaluations.IsEvaluationFile(event.Path) {
+				if err := o.handleEvaluationChange(event.Path); err != nil {
+					log.Printf("[watcher] 評価の処理エラー: %v", err)
+				}
+				continue
+			}
+
 			// inbox ファイルが変更された場合、該当エージェントに通知
 			if err := o.handleInboxChange(event.Path); err != nil {
 				log.Printf("[watcher] inbox 変更処理エラー: %v", err)
@@ -231,27 +479,68 @@ func (o *Orchestrator) handleInboxChange(path string) error {
 	// ファイル名から対象エージェントを特定
 	// 例: agents/queue/inbox/marshall.yaml -> marshall
 	base := filepath.Base(path)
-	target := base[:len(base)-len(filepath.Ext(base))]
+	agent := base[:len(base)-len(filepath.Ext(base))]
 
-	log.Printf("[watcher] inbox 変更検知: %s -> エージェント: %s", path, target)
+	log.Printf("[watcher] inbox 変更検知: %s -> エージェント: %s", path, agent)
 
-	// エージェントタイプに応じて wakeup
-	switch target {
+	// エージェントが読む前にメッセージの秘密情報を伏せる
+	if err := o.redactInbox(agent); err != nil {
+		log.Printf("[secrets] %s の inbox の秘密情報を伏せられませんでした: %v", agent, err)
+	}
+
+	// エージェント名から tmux ペインを特定して wakeup
+	target, ok := o.agentTarget(agent)
+	if !ok {
+		log.Printf("[watcher] %s はスキップ（ペイン不明）", agent)
+		return nil
+	}
+
+	// 作業中・権限確認中のエージェントへの nudge は idle になるまで保留
+	state, err := o.detectTargetState(target)
+	if err != nil {
+		log.Printf("[watcher] %s の状態取得に失敗: %v", agent, err)
+	}
+	if !canNudge(state) {
+		log.Printf("[watcher] %s は %s のため wakeup を保留", agent, state)
+		o.deferNudge(agent)
+		return nil
+	}
+
+	log.Printf("[watcher] %s に wakeup を送信", agent)
+	return o.Wakeup(agent, target)
+}
+
+// エージェント名から tmux ターゲットを解決
+// 例: envoy -> main.0, marshall -> main.2, specialist_2 -> specialists.1
+func (o *Orchestrator) agentTarget(agent string) (string, bool) {
+	// 登録済みであれば登録情報を優先
+	if info, ok, err := o.registry.Get(agent); err == nil && ok {
+		return info.Target, true
+	}
+
+	switch agent {
 	case AgentEnvoy:
-		log.Printf("[watcher] Envoy に wakeup を送信")
-		return o.Wakeup(AgentEnvoy, "main.0")
+		return "main.0", true
 	case AgentMarshall:
-		log.Printf("[watcher] Marshall に wakeup を送信")
-		return o.Wakeup(AgentMarshall, "main.2")
-	default:
-		// Specialist の場合はスキップ（将来実装）
-		log.Printf("[watcher] %s はスキップ（未実装）", target)
-		return nil
+		return "main.2", true
 	}
+
+	suffix, ok := strings.CutPrefix(agent, AgentSpecialist+"_")
+	if !ok {
+		return "", false
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil || index < 1 {
+		return "", false
+	}
+	return fmt.Sprintf("%s.%d", parallel.WindowSpecialists, index-1), true
 }
 
 // watcher を停止
 func (o *Orchestrator) StopWatcher() error {
+	// バックグラウンドのループを停止
+	o.stopOnce.Do(func() { close(o.done) })
+
 	if o.watcher == nil {
 		return nil
 	}
@@ -0,0 +1,188 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+// 権限確認プロンプトへの応答
+type PermissionAnswer string
+
+const (
+	// 今回のみ許可
+	PermissionAnswerYes PermissionAnswer = "yes"
+	// 許可し、セッション中は同種の確認を省略
+	PermissionAnswerAlways PermissionAnswer = "always"
+	// 拒否
+	PermissionAnswerDeny PermissionAnswer = "deny"
+)
+
+// 権限確認プロンプトの見出し（操作の種類）
+var permissionTitles = []string{
+	"Edit file",
+	"Create file",
+	"Write file",
+	"Bash command",
+	"Read file",
+	"Fetch",
+}
+
+// ペイン内容から権限確認で要求されている操作を抽出
+// 例: "Edit file: Do you want to make this edit to cmd_001.yaml?"
+func extractPermissionAction(output string) string {
+	tail := strings.Split(lastLines(output, classifyTailLines*2), "\n")
+
+	question := ""
+	questionIndex := -1
+	for i := len(tail) - 1; i >= 0; i-- {
+		lower := strings.ToLower(tail[i])
+		for _, marker := range permissionMarkers {
+			if strings.Contains(lower, marker) {
+				question = cleanPaneLine(tail[i])
+				questionIndex = i
+				break
+			}
+		}
+		if questionIndex >= 0 {
+			break
+		}
+	}
+	if questionIndex < 0 {
+		return ""
+	}
+
+	// 質問より上にある見出しを探す
+	for i := questionIndex - 1; i >= 0; i-- {
+		line := cleanPaneLine(tail[i])
+		for _, title := range permissionTitles {
+			if strings.HasPrefix(line, title) {
+				return line + ": " + question
+			}
+		}
+	}
+	return question
+}
+
+// ペインの枠線や選択カーソルを取り除く
+func cleanPaneLine(line string) string {
+	return strings.TrimSpace(strings.Trim(line, " │╭╮╰╯─❯>"))
+}
+
+// すべてのエージェントの権限確認プロンプトを検出して記録・通知
+func (o *Orchestrator) checkPermissionPrompts() {
+	changed := false
+
+	for _, agent := range o.agents() {
+		state, output, err := o.inspectTarget(agent.Target)
+		if err != nil {
+			continue
+		}
+
+		if state != AgentStateAwaitingPermission {
+			// プロンプトが消えていればペイン上で応答済み
+			resolved, err := o.permissions.Resolve(agent.Name, communication.PermissionStatusResolved, "pane")
+			if err != nil {
+				log.Printf("[permission] %s の記録更新に失敗: %v", agent.Name, err)
+			}
+			changed = changed || resolved
+			continue
+		}
+
+		action := extractPermissionAction(output)
+		req, created, err := o.permissions.Record(agent.Name, action)
+		if err != nil {
+			log.Printf("[permission] %s の記録に失敗: %v", agent.Name, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		changed = true
+
+		log.Printf("[permission] %s が権限確認で停止しています: %s", agent.Name, req.Action)
+		if err := o.notifyPermissionRequest(req); err != nil {
+			log.Printf("[permission] Envoy への通知に失敗: %v", err)
+		}
+	}
+
+	if changed {
+		o.updatePermissionStatusLine()
+	}
+}
+
+// 権限確認を Envoy の inbox に通知
+func (o *Orchestrator) notifyPermissionRequest(req *communication.PermissionRequest) error {
+	message := fmt.Sprintf("%s が権限確認で停止しています: %s（ユーザーに確認し、`bastion approve %s` で応答できます）",
+		req.Agent, req.Action, req.Agent)
+	return o.inbox.Write(AgentEnvoy, message, communication.MessageTypePermissionRequested, "bastion")
+}
+
+// 応答待ちの権限確認をステータスラインに表示
+func (o *Orchestrator) updatePermissionStatusLine() {
+	pending, err := o.permissions.Pending()
+	if err != nil {
+		log.Printf("[permission] 応答待ち一覧の取得に失敗: %v", err)
+		return
+	}
+
+	if len(pending) == 0 {
+		if err := o.sm.ClearStatusMessage(); err != nil {
+			log.Printf("[permission] ステータスラインの更新に失敗: %v", err)
+		}
+		return
+	}
+
+	var parts []string
+	for _, req := range pending {
+		parts = append(parts, fmt.Sprintf("%s (%s)", req.Agent, req.Action))
+	}
+	message := fmt.Sprintf("⚠ 権限確認待ち: %s → bastion approve <agent>", strings.Join(parts, ", "))
+	if err := o.sm.SetStatusMessage(message); err != nil {
+		log.Printf("[permission] ステータスラインの更新に失敗: %v", err)
+	}
+}
+
+// tmux の外からエージェントの権限確認プロンプトに応答
+func (o *Orchestrator) AnswerPermission(agent string, answer PermissionAnswer) error {
+	target, ok := o.agentTarget(agent)
+	if !ok {
+		return fmt.Errorf("unknown agent: %s", agent)
+	}
+
+	// プロンプトが出ていないペインに数字を入力しない
+	state, err := o.detectTargetState(target)
+	if err != nil {
+		return fmt.Errorf("failed to detect state of %s: %w", agent, err)
+	}
+	if state != AgentStateAwaitingPermission {
+		return fmt.Errorf("%s is not waiting for permission (state: %s)", agent, state)
+	}
+
+	// Claude Code の選択肢: 1. Yes / 2. Yes, and don't ask again / Esc で拒否
+	var key string
+	var status communication.PermissionStatus
+	switch answer {
+	case PermissionAnswerYes:
+		key, status = "1", communication.PermissionStatusApproved
+	case PermissionAnswerAlways:
+		key, status = "2", communication.PermissionStatusApproved
+	case PermissionAnswerDeny:
+		key, status = "Escape", communication.PermissionStatusDenied
+	default:
+		return fmt.Errorf("unknown permission answer: %s", answer)
+	}
+
+	if err := o.sm.SendKeys(target, key, false); err != nil {
+		return fmt.Errorf("failed to answer permission prompt: %w", err)
+	}
+
+	if _, err := o.permissions.Resolve(agent, status, "bastion approve"); err != nil {
+		log.Printf("warning: failed to record permission answer: %v", err)
+	}
+	o.updatePermissionStatusLine()
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package orchestrator
+
+import "testing"
+
+func TestExtractPermissionAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name: "edit with title",
+			output: "╭──────────────────────────────────────────╮\n" +
+				"│ Edit file                                │\n" +
+				"│ ╭──────────────────────────────────────╮ │\n" +
+				"│ │ status: in_progress                  │ │\n" +
+				"│ ╰──────────────────────────────────────╯ │\n" +
+				"│ Do you want to make this edit to cmd_shiritori_001.yaml? │\n" +
+				"│ ❯ 1. Yes                                 │\n" +
+				"│   2. No                                  │\n",
+			want: "Edit file: Do you want to make this edit to cmd_shiritori_001.yaml?",
+		},
+		{
+			name:   "question without title",
+			output: "Do you want to create dashboard.md?\n❯ 1. Yes\n",
+			want:   "Do you want to create dashboard.md?",
+		},
+		{
+			name:   "no prompt",
+			output: "│ >      │\n",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractPermissionAction(tt.output); got != tt.want {
+				t.Errorf("extractPermissionAction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnswerPermission_UnknownAgent(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.AnswerPermission("unknown", PermissionAnswerYes); err == nil {
+		t.Error("expected error for unknown agent")
+	}
+}
@@ -0,0 +1,184 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 起動済みエージェントの情報
+type AgentInfo struct {
+	Name      string    `yaml:"name"`
+	Type      string    `yaml:"type"`
+	Index     int       `yaml:"index,omitempty"`
+	Target    string    `yaml:"target"`
+	Dir       string    `yaml:"dir"`
+	Command   string    `yaml:"command"`
+	StartedAt time.Time `yaml:"started_at"`
+	// Specialist の作業用 worktree（使用しない場合は空）
+	Worktree string `yaml:"worktree,omitempty"`
+	// 外部 Specialist の定義（汎用 Specialist は nil）
+	Spec *SpecialistConfig `yaml:"spec,omitempty"`
+	// Specialist に割り当てたポート範囲と作業用ディレクトリ
+	Resources *Resources `yaml:"resources,omitempty"`
+}
+
+// エージェント登録ファイルの内容
+type agentList struct {
+	Agents []AgentInfo `yaml:"agents"`
+}
+
+// 起動済みエージェントを記録する
+// bastion start と bastion watch は別プロセスのため、ファイルで共有する
+// 保存先: agents/queue/agents.yaml
+type Registry struct {
+	path string
+	mu   sync.Mutex
+}
+
+// 新しい Registry を作成
+func NewRegistry(queueDir string) *Registry {
+	return &Registry{
+		path: filepath.Join(queueDir, "agents.yaml"),
+	}
+}
+
+// エージェントを登録（同名のエージェントは上書き）
+func (r *Registry) Register(info AgentInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.read()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range list.Agents {
+		if list.Agents[i].Name == info.Name {
+			list.Agents[i] = info
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		list.Agents = append(list.Agents, info)
+	}
+
+	return r.write(list)
+}
+
+// エージェントの登録を解除
+func (r *Registry) Unregister(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.read()
+	if err != nil {
+		return err
+	}
+
+	kept := list.Agents[:0]
+	for _, info := range list.Agents {
+		if info.Name != name {
+			kept = append(kept, info)
+		}
+	}
+	list.Agents = kept
+
+	return r.write(list)
+}
+
+// 登録済みのエージェントを取得
+func (r *Registry) Get(name string) (*AgentInfo, bool, error) {
+	agents, err := r.List()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, info := range agents {
+		if info.Name == name {
+			found := info
+			return &found, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// 登録済みのエージェント一覧を取得（Envoy, Marshall, Specialist の順）
+func (r *Registry) List() ([]AgentInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	agents := list.Agents
+	sort.SliceStable(agents, func(i, j int) bool {
+		ri, rj := agentTypeRank(agents[i].Type), agentTypeRank(agents[j].Type)
+		if ri != rj {
+			return ri < rj
+		}
+		return agents[i].Index < agents[j].Index
+	})
+	return agents, nil
+}
+
+// すべての登録を削除（セッション起動時に前回の情報を破棄する）
+func (r *Registry) Reset() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.write(&agentList{})
+}
+
+// エージェント種別の表示順
+func agentTypeRank(agentType string) int {
+	switch agentType {
+	case AgentEnvoy:
+		return 0
+	case AgentMarshall:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// 登録ファイルを読み込む（存在しない場合は空）
+func (r *Registry) read() (*agentList, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &agentList{}, nil
+		}
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	var list agentList
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal registry: %w", err)
+	}
+	return &list, nil
+}
+
+// 登録ファイルに書き込む
+func (r *Registry) write(list *agentList) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry: %w", err)
+	}
+
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	return nil
+}
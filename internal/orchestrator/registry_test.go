@@ -0,0 +1,118 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistry_RegisterAndGet(t *testing.T) {
+	registry := NewRegistry(t.TempDir())
+
+	info := AgentInfo{
+		Name:      "specialist_1",
+		Type:      AgentSpecialist,
+		Index:     1,
+		Target:    "specialists.0",
+		Dir:       "/project/agents/specialist",
+		Command:   "cd /project/agents/specialist && claude --add-dir /project",
+		StartedAt: time.Now(),
+	}
+	if err := registry.Register(info); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	got, ok, err := registry.Get("specialist_1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected agent to be registered")
+	}
+	if got.Command != info.Command || got.Target != info.Target {
+		t.Errorf("unexpected agent info: %+v", got)
+	}
+
+	// 同名の登録は上書き
+	info.Target = "specialists.3"
+	if err := registry.Register(info); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	agents, err := registry.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(agents) != 1 || agents[0].Target != "specialists.3" {
+		t.Errorf("expected single updated agent, got %+v", agents)
+	}
+}
+
+func TestRegistry_ListOrder(t *testing.T) {
+	registry := NewRegistry(t.TempDir())
+
+	for _, info := range []AgentInfo{
+		{Name: "specialist_2", Type: AgentSpecialist, Index: 2},
+		{Name: "marshall", Type: AgentMarshall},
+		{Name: "specialist_1", Type: AgentSpecialist, Index: 1},
+		{Name: "envoy", Type: AgentEnvoy},
+	} {
+		if err := registry.Register(info); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	agents, err := registry.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	want := []string{"envoy", "marshall", "specialist_1", "specialist_2"}
+	for i, name := range want {
+		if agents[i].Name != name {
+			t.Errorf("agents[%d] = %s, want %s", i, agents[i].Name, name)
+		}
+	}
+}
+
+func TestRegistry_UnregisterAndReset(t *testing.T) {
+	registry := NewRegistry(t.TempDir())
+
+	for _, name := range []string{"envoy", "marshall"} {
+		if err := registry.Register(AgentInfo{Name: name, Type: name}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	if err := registry.Unregister("envoy"); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	if _, ok, _ := registry.Get("envoy"); ok {
+		t.Error("envoy should be unregistered")
+	}
+	if _, ok, _ := registry.Get("marshall"); !ok {
+		t.Error("marshall should remain registered")
+	}
+
+	if err := registry.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	agents, err := registry.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(agents) != 0 {
+		t.Errorf("expected empty registry after reset, got %+v", agents)
+	}
+}
+
+func TestAgentTarget_PrefersRegistry(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.registry.Register(AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1, Target: "specialists.5"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	target, ok := o.agentTarget("specialist_1")
+	if !ok || target != "specialists.5" {
+		t.Errorf("expected registered target specialists.5, got (%q, %v)", target, ok)
+	}
+}
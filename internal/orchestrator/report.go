@@ -0,0 +1,179 @@
+package orchestrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"gopkg.in/yaml.v3"
+)
+
+// 完了報告の処理段階
+type reportHandler struct {
+	name string
+	// false を返すと以降の段階を実行しない
+	// バックグラウンドで実行する段階は完了後に次の段階から再開する
+	run func(path string, report *communication.Report) (bool, error)
+}
+
+// 完了報告の処理段階（この順に実行する）
+// 本文の秘密情報は伏せてから扱う
+// failed のレポートは対応方針に従って再実行・エスカレーションする
+// それ以外はブランチの依存関係の変更をレポートに記録して品質ゲートを実行し（ゲートがなければテスト結果を記録し）、
+// カバレッジを比較してからレビューを依頼し、並列試行の結果を記録して、変更予定パス外の変更があれば Marshall に通知
+func (o *Orchestrator) reportHandlers() []reportHandler {
+	return []reportHandler{
+		{"secrets", func(path string, report *communication.Report) (bool, error) {
+			if _, err := o.redactReport(report); err != nil {
+				log.Printf("[secrets] %s のレポートの秘密情報を伏せられませんでした: %v", path, err)
+			}
+			return report.TaskID != "", nil
+		}},
+		{"failed", func(path string, report *communication.Report) (bool, error) {
+			if report.Status != communication.TaskStatusFailed {
+				return true, nil
+			}
+			return false, o.handleFailedReport(path, report)
+		}},
+		// レビュータスクの完了報告は判定だけを処理する
+		{"review_verdict", func(path string, report *communication.Report) (bool, error) {
+			handled, err := o.handleReviewReport(report)
+			return !handled, err
+		}},
+		{"deps", func(path string, report *communication.Report) (bool, error) {
+			if err := o.recordDependencyChanges(report); err != nil {
+				log.Printf("[deps] %s の依存関係の解析に失敗: %v", report.TaskID, err)
+			}
+			return true, nil
+		}},
+		{"gates", func(path string, report *communication.Report) (bool, error) {
+			if o.useGates() {
+				return !o.startGates(*report), nil
+			}
+			if err := o.recordTestResults(report); err != nil {
+				log.Printf("[tests] %s のテスト結果の記録に失敗: %v", report.TaskID, err)
+			}
+			return true, nil
+		}},
+		{"coverage", func(path string, report *communication.Report) (bool, error) {
+			return !o.startCoverage(*report), nil
+		}},
+		{"review_request", func(path string, report *communication.Report) (bool, error) {
+			if err := o.requestReview(*report); err != nil {
+				log.Printf("[review] %s のレビューの依頼に失敗: %v", report.TaskID, err)
+			}
+			return true, nil
+		}},
+		{"attempts", func(path string, report *communication.Report) (bool, error) {
+			if err := o.recordAttempt(*report); err != nil {
+				log.Printf("[attempts] %s の試行の結果の記録に失敗: %v", report.TaskID, err)
+			}
+			return true, nil
+		}},
+		{"scope", func(path string, report *communication.Report) (bool, error) {
+			return true, o.checkReportScope(*report)
+		}},
+	}
+}
+
+// レポートの変更を処理
+// 書き込みイベントは 1 回の保存で複数届き、bastion が書き戻したレポートでも届くため、処理済みの内容は無視する
+func (o *Orchestrator) handleReportChange(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	digest := reportDigest(data)
+
+	o.mu.Lock()
+	handled := o.reportDigests[path] == digest
+	o.reportDigests[path] = digest
+	o.mu.Unlock()
+	if handled {
+		return nil
+	}
+
+	report, err := communication.ParseReport(data)
+	if err != nil {
+		return err
+	}
+	return o.runReportHandlers(path, report, "")
+}
+
+// from の段階から完了報告を処理する（空ならすべての段階）
+func (o *Orchestrator) runReportHandlers(path string, report *communication.Report, from string) error {
+	handlers := o.reportHandlers()
+	start := 0
+	if from != "" {
+		start = len(handlers)
+		for i, h := range handlers {
+			if h.name == from {
+				start = i
+				break
+			}
+		}
+		if start == len(handlers) {
+			return fmt.Errorf("unknown report handler: %s", from)
+		}
+	}
+
+	for _, h := range handlers[start:] {
+		next, err := h.run(path, report)
+		if err != nil || !next {
+			return err
+		}
+	}
+	return nil
+}
+
+// バックグラウンドの段階が終わったあと、現在のレポートで処理を再開する
+// 同じレポートなら next の段階から、同じタスクの新しいレポートが書かれていれば again の段階からやり直す
+func (o *Orchestrator) resumeReport(report communication.Report, again, next string) {
+	path := o.reports.Path(report.SpecialistID)
+	current, err := o.reports.ReadFile(path)
+	if err != nil {
+		log.Printf("[report] %s のレポートの読み込みに失敗: %v", report.TaskID, err)
+		return
+	}
+	// 別のタスクのレポートは自身の書き込みイベントで処理される
+	if current.TaskID != report.TaskID {
+		return
+	}
+
+	from := next
+	if !current.Timestamp.Equal(report.Timestamp) {
+		from = again
+	}
+	if err := o.runReportHandlers(path, current, from); err != nil {
+		log.Printf("[report] %s のレポートの処理に失敗: %v", report.TaskID, err)
+	}
+}
+
+// bastion が書き戻すレポート（書き戻しによる書き込みイベントでは処理し直さない）
+func (o *Orchestrator) writeReport(report *communication.Report) error {
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	o.mu.Lock()
+	o.reportDigests[o.reports.Path(report.SpecialistID)] = reportDigest(data)
+	o.mu.Unlock()
+
+	return o.reports.Write(report)
+}
+
+// レポートの内容の指紋
+func reportDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// レポートディレクトリのファイルか
+func (o *Orchestrator) isReportFile(path string) bool {
+	return filepath.Dir(path) == o.reports.Dir()
+}
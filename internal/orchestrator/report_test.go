@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+func TestHandleReportChange_ResumesAfterGates(t *testing.T) {
+	dir := t.TempDir()
+	o := newTestOrchestrator(t, dir)
+	o.config.Gates.Enabled = true
+	counter := filepath.Join(dir, "gate_runs")
+	o.config.Gates.Commands = []config.GateCommand{{Name: "test", Run: "echo run >> " + counter}}
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: "specialist_1", Paths: []string{"internal/auth"}, Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	report := &communication.Report{
+		TaskID:       "task_001",
+		SpecialistID: "specialist_1",
+		Status:       communication.TaskStatusCompleted,
+		Deliverables: []string{"internal/billing/invoice.go"},
+		Timestamp:    time.Now().Add(-time.Minute),
+	}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	path := o.reports.Path("specialist_1")
+
+	if err := o.handleReportChange(path); err != nil {
+		t.Fatalf("handleReportChange failed: %v", err)
+	}
+	// ゲートの結果を待ってから以降の段階を再開する（書き戻しの書き込みイベントには頼らない）
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if task, _ := o.tasks.ReadByID("task_001"); task.Scope != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("scope should be checked after the gates")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// bastion が書き戻したレポートの書き込みイベントは処理し直さない
+	for i := 0; i < 2; i++ {
+		if err := o.handleReportChange(path); err != nil {
+			t.Fatalf("handleReportChange failed: %v", err)
+		}
+	}
+	if got, _ := o.reports.ReadFile(path); len(got.Gates) != 1 {
+		t.Errorf("gate results should be written back: %+v", got.Gates)
+	}
+	if runs, _ := os.ReadFile(counter); strings.Count(string(runs), "run") != 1 {
+		t.Errorf("gates should run once, got %q", runs)
+	}
+	if n := countMarshallMessages(t, o, "internal/billing/invoice.go"); n != 1 {
+		t.Errorf("marshall should be notified once, got %d", n)
+	}
+}
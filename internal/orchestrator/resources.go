@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Specialist に割り当てたリソース
+type Resources struct {
+	// 割り当て枠の番号（Specialist の削除で空いた枠は再利用する）
+	Slot int `yaml:"slot"`
+	// 使用できるポート範囲（PortBase から PortCount 個）
+	PortBase  int `yaml:"port_base"`
+	PortCount int `yaml:"port_count"`
+	// 作業用ディレクトリ
+	ScratchDir string `yaml:"scratch_dir"`
+}
+
+// claude に渡す環境変数（KEY=value）
+func (r *Resources) Env() []string {
+	return []string{
+		fmt.Sprintf("BASTION_PORT_BASE=%d", r.PortBase),
+		fmt.Sprintf("BASTION_PORT_COUNT=%d", r.PortCount),
+		"BASTION_SCRATCH_DIR=" + shellQuote(r.ScratchDir),
+	}
+}
+
+// 割り当てたリソースを伝えるシステムプロンプト
+func resourcesPrompt(r *Resources) string {
+	return fmt.Sprintf("開発サーバーやテスト用 DB などが使うポートは %d〜%d（$BASTION_PORT_BASE から $BASTION_PORT_COUNT 個）の範囲で選び、"+
+		"一時ファイルは %s（$BASTION_SCRATCH_DIR）に置いてください。他の Specialist と競合しないよう割り当てられた範囲です。",
+		r.PortBase, r.PortBase+r.PortCount-1, r.ScratchDir)
+}
+
+// Specialist にポート範囲と作業用ディレクトリを割り当てる
+// 登録済みの他の Specialist が使っていない最小の枠を使う
+func (o *Orchestrator) allocateResources(name string) (*Resources, error) {
+	agents, err := o.registry.List()
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[int]bool)
+	for _, info := range agents {
+		if info.Resources != nil && info.Name != name {
+			used[info.Resources.Slot] = true
+		}
+	}
+
+	cfg := o.config.Resources
+	slot := 0
+	for used[slot] {
+		slot++
+	}
+	portBase := cfg.PortBase + slot*cfg.PortsPerSpecialist
+	if portBase+cfg.PortsPerSpecialist-1 > 65535 {
+		return nil, fmt.Errorf("no free port range for %s", name)
+	}
+
+	dir := filepath.Join(o.scratchRoot(), name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+
+	return &Resources{
+		Slot:       slot,
+		PortBase:   portBase,
+		PortCount:  cfg.PortsPerSpecialist,
+		ScratchDir: dir,
+	}, nil
+}
+
+// Specialist のリソースを解放（作業用ディレクトリを削除）
+// ポート範囲は登録解除で空き枠になる
+func (o *Orchestrator) releaseResources(r *Resources) error {
+	if r == nil || r.ScratchDir == "" {
+		return nil
+	}
+
+	// 作業用ディレクトリの配置先以外は削除しない
+	root := o.scratchRoot()
+	rel, err := filepath.Rel(root, r.ScratchDir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("scratch directory is outside %s: %s", root, r.ScratchDir)
+	}
+
+	if err := os.RemoveAll(r.ScratchDir); err != nil {
+		return fmt.Errorf("failed to remove scratch directory: %w", err)
+	}
+	return nil
+}
+
+// 作業用ディレクトリの配置先
+func (o *Orchestrator) scratchRoot() string {
+	return filepath.Join(o.projectRoot, o.config.Resources.ScratchDir)
+}
@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAllocateResources(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	first, err := o.allocateResources("specialist_1")
+	if err != nil {
+		t.Fatalf("allocateResources failed: %v", err)
+	}
+	if first.Slot != 0 || first.PortBase != 20000 || first.PortCount != 100 {
+		t.Errorf("unexpected resources: %+v", first)
+	}
+	if info, err := os.Stat(first.ScratchDir); err != nil || !info.IsDir() {
+		t.Errorf("scratch directory should be created: %v", err)
+	}
+	if err := o.registry.Register(AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1, Resources: first}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	// 登録済みの Specialist と重ならない範囲を割り当てる
+	second, err := o.allocateResources("security-auditor")
+	if err != nil {
+		t.Fatalf("allocateResources failed: %v", err)
+	}
+	if second.Slot != 1 || second.PortBase != 20100 || second.ScratchDir == first.ScratchDir {
+		t.Errorf("resources should not overlap: %+v", second)
+	}
+	if err := o.registry.Register(AgentInfo{Name: "security-auditor", Type: AgentSpecialist, Index: 2, Resources: second}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	// 解放した枠は再利用する
+	if err := o.registry.Unregister("specialist_1"); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	third, err := o.allocateResources("specialist_3")
+	if err != nil {
+		t.Fatalf("allocateResources failed: %v", err)
+	}
+	if third.Slot != 0 || third.PortBase != 20000 {
+		t.Errorf("released slot should be reused: %+v", third)
+	}
+}
+
+func TestAllocateResources_Exhausted(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Resources.PortBase = 65000
+	o.config.Resources.PortsPerSpecialist = 500
+
+	if _, err := o.allocateResources("specialist_1"); err != nil {
+		t.Fatalf("allocateResources failed: %v", err)
+	}
+	if err := o.registry.Register(AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1, Resources: &Resources{Slot: 0}}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := o.allocateResources("specialist_2"); err == nil {
+		t.Error("expected error when port ranges are exhausted")
+	}
+}
+
+func TestReleaseResources(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	r, err := o.allocateResources("specialist_1")
+	if err != nil {
+		t.Fatalf("allocateResources failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.ScratchDir, "db.sqlite"), []byte("data"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := o.releaseResources(r); err != nil {
+		t.Fatalf("releaseResources failed: %v", err)
+	}
+	if _, err := os.Stat(r.ScratchDir); !os.IsNotExist(err) {
+		t.Errorf("scratch directory should be removed: %v", err)
+	}
+
+	// 配置先の外は削除しない
+	outside := t.TempDir()
+	if err := o.releaseResources(&Resources{ScratchDir: outside}); err == nil {
+		t.Error("expected error for directory outside scratch root")
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("directory outside scratch root should be kept: %v", err)
+	}
+}
+
+func TestBuildAgentCommand_Resources(t *testing.T) {
+	o := newTestOrchestrator(t, "/project")
+	r := &Resources{Slot: 1, PortBase: 20100, PortCount: 100, ScratchDir: "/project/agents/queue/scratch/specialist_2"}
+
+	cmd := o.buildAgentCommand("/project/agents/specialist", "", nil, r)
+	for _, want := range []string{
+		"cd /project/agents/specialist && BASTION_PORT_BASE=20100 BASTION_PORT_COUNT=100 BASTION_SCRATCH_DIR=",
+		" claude --add-dir /project",
+		"20100〜20199",
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command should contain %q: %s", want, cmd)
+		}
+	}
+
+	cmd = o.buildAgentCommand("/project/agents/specialist", "/project/.worktrees/sp2", nil, r)
+	if !strings.Contains(cmd, "CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD=1 BASTION_PORT_BASE=20100 ") {
+		t.Errorf("resource variables should be exported with worktree: %s", cmd)
+	}
+}
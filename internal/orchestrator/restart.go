@@ -0,0 +1,154 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+// エージェントごとの再起動状態
+type restartState struct {
+	// 直近の再起動回数
+	count int
+	// 最後に再起動した時刻
+	lastRestart time.Time
+	// 次に再起動してよい時刻
+	nextAllowed time.Time
+	// 再起動後、idle になったら再開プロンプトを送る
+	awaitingResume bool
+	// 上限に達して再起動を諦めた
+	gaveUp bool
+}
+
+// 再起動回数に応じた待ち時間（backoff × 2^(count-1)、上限 max_backoff）
+func restartBackoff(count int, cfg config.RestartConfig) time.Duration {
+	backoff := cfg.Backoff
+	for i := 1; i < count; i++ {
+		backoff *= 2
+		if backoff >= cfg.MaxBackoff {
+			return cfg.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// 終了したエージェントを検出して再起動
+func (o *Orchestrator) checkCrashedAgents(now time.Time) {
+	if !o.config.Restart.Enabled {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, agent := range o.agents() {
+		// 登録がなければ起動コマンドが不明なため対象外
+		if agent.StartedAt.IsZero() {
+			continue
+		}
+
+		state, err := o.detectTargetState(agent.Target)
+		if err != nil {
+			continue
+		}
+
+		rs := o.restarts[agent.Name]
+		if rs == nil {
+			rs = &restartState{}
+			o.restarts[agent.Name] = rs
+		}
+
+		if state != AgentStateCrashed {
+			o.handleRunningAgent(agent, state, rs, now)
+			continue
+		}
+
+		if rs.gaveUp || now.Before(rs.nextAllowed) {
+			continue
+		}
+
+		// 起動直後は claude が立ち上がるまでシェルが表示されるため待つ
+		if now.Sub(agent.StartedAt) < o.config.Restart.Backoff {
+			continue
+		}
+
+		if rs.count >= o.config.Restart.MaxRestarts {
+			rs.gaveUp = true
+			log.Printf("[restart] %s は再起動回数の上限（%d 回）に達しました", agent.Name, rs.count)
+			message := fmt.Sprintf("%s が %d 回再起動しても終了を繰り返すため、自動再起動を停止しました。ペインを確認してください",
+				agent.Name, rs.count)
+			if err := o.inbox.Write(AgentEnvoy, message, communication.MessageTypeWakeUp, "bastion"); err != nil {
+				log.Printf("[restart] Envoy への通知に失敗: %v", err)
+			}
+			continue
+		}
+
+		if err := o.restartAgent(agent); err != nil {
+			log.Printf("[restart] %s の再起動に失敗: %v", agent.Name, err)
+		}
+
+		// 失敗した場合も試行回数に含め、次回まで待つ
+		rs.count++
+		rs.lastRestart = now
+		rs.nextAllowed = now.Add(restartBackoff(rs.count, o.config.Restart))
+		rs.awaitingResume = true
+	}
+}
+
+// 動作中のエージェントの再起動状態を更新
+func (o *Orchestrator) handleRunningAgent(agent agentRef, state AgentState, rs *restartState, now time.Time) {
+	// 再起動後に idle になったら未処理メッセージからの再開を促す
+	if rs.awaitingResume && state == AgentStateIdle {
+		if err := o.sm.SendKeys(agent.Target, o.resumePrompt(agent.Name), true); err != nil {
+			log.Printf("[restart] %s への再開プロンプト送信に失敗: %v", agent.Name, err)
+			return
+		}
+		log.Printf("[restart] %s に再開プロンプトを送信しました", agent.Name)
+		rs.awaitingResume = false
+		// 再開プロンプトで inbox を確認するため保留中の nudge は不要
+		delete(o.deferred, agent.Name)
+	}
+
+	// 一定時間安定して動作したら再起動回数をリセット
+	if rs.count > 0 && !rs.awaitingResume && now.Sub(rs.lastRestart) >= o.config.Restart.ResetAfter {
+		*rs = restartState{}
+	}
+}
+
+// エージェントを登録時と同じコマンドで再起動
+func (o *Orchestrator) restartAgent(agent agentRef) error {
+	info, ok, err := o.registry.Get(agent.Name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("agent is not registered: %s", agent.Name)
+	}
+
+	// remain-on-exit でペインごと終了している場合はシェルを再生成
+	paneInfo, err := o.sm.GetPaneInfo(info.Target)
+	if err != nil {
+		return err
+	}
+	if paneInfo.Dead {
+		if err := o.sm.RespawnPane(info.Target); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[restart] %s を再起動します: %s", agent.Name, info.Command)
+	if err := o.sm.SendKeys(info.Target, info.Command, true); err != nil {
+		return fmt.Errorf("failed to send command: %w", err)
+	}
+	return nil
+}
+
+// 再起動後に送る再開プロンプト
+func (o *Orchestrator) resumePrompt(agent string) string {
+	inboxPath := filepath.Join(o.queueDir, "inbox", agent+".yaml")
+	return fmt.Sprintf("claude が終了したため再起動しました。%s の未処理メッセージを確認し、作業を再開してください", inboxPath)
+}
@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+func TestRestartBackoff(t *testing.T) {
+	cfg := config.RestartConfig{
+		Backoff:    30 * time.Second,
+		MaxBackoff: 3 * time.Minute,
+	}
+
+	tests := []struct {
+		count int
+		want  time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 3 * time.Minute},
+		{10, 3 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := restartBackoff(tt.count, cfg); got != tt.want {
+			t.Errorf("restartBackoff(%d) = %s, want %s", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestResumePrompt(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	prompt := o.resumePrompt("specialist_2")
+	if !strings.Contains(prompt, "inbox/specialist_2.yaml") {
+		t.Errorf("resume prompt should point to the agent's inbox, got %q", prompt)
+	}
+}
+
+func TestRestartAgent_NotRegistered(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.restartAgent(agentRef{Name: "specialist_9", Target: "specialists.8"}); err == nil {
+		t.Error("expected error for unregistered agent")
+	}
+}
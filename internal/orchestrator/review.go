@@ -0,0 +1,387 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// レビュータスクの ID（2 回目以降は回数を付ける）
+func reviewTaskID(taskID string, round int) string {
+	if round <= 1 {
+		return taskID + "_review"
+	}
+	return fmt.Sprintf("%s_review_%d", taskID, round)
+}
+
+// レビュー用の差分を書き出すディレクトリ
+func (o *Orchestrator) reviewDir() string {
+	return filepath.Join(o.queueDir, "reviews")
+}
+
+// レビューの判定の表示（例: "request_changes (2 comments)"）
+func FormatReview(r communication.Review) string {
+	if len(r.Comments) == 0 {
+		return string(r.Verdict)
+	}
+	return fmt.Sprintf("%s (%d comments)", r.Verdict, len(r.Comments))
+}
+
+// コメントを 1 行ずつ表示用に整形
+func formatReviewComments(comments []communication.ReviewComment) string {
+	var b strings.Builder
+	for _, c := range comments {
+		b.WriteString("\n- ")
+		switch {
+		case c.File != "" && c.Line > 0:
+			fmt.Fprintf(&b, "%s:%d: ", c.File, c.Line)
+		case c.File != "":
+			b.WriteString(c.File + ": ")
+		}
+		b.WriteString(c.Comment)
+	}
+	return b.String()
+}
+
+// 完了報告のタスクを別の Specialist へのレビュータスクとして依頼する
+// 品質ゲートを使う場合はゲートがすべて成功したレポートだけを依頼する
+func (o *Orchestrator) requestReview(report communication.Report) error {
+	if !o.config.Review.Enabled || report.Status != communication.TaskStatusCompleted {
+		return nil
+	}
+	if o.useGates() && (!gatesRecorded(report) || len(failedGates(report.Gates)) > 0) {
+		return nil
+	}
+
+	task, err := o.tasks.ReadByID(report.TaskID)
+	if err != nil {
+		return err
+	}
+	// レビュー・コンフリクト解消・並列試行のタスクはレビューしない
+	if task.ReviewOf != "" || task.ResolvesConflictOf != "" || task.AttemptOf != "" {
+		return nil
+	}
+	// 書き込みイベントは 1 回の保存で複数届くため、依頼済みのレポートは無視する
+	if task.Review != nil && (report.Timestamp.IsZero() || !task.Review.RequestedAt.Before(report.Timestamp)) {
+		return nil
+	}
+	return o.startReview(task, report.SpecialistID, report.Summary)
+}
+
+// タスクのレビュータスクを作成する（author は変更した Specialist、summary は完了報告の要約）
+// 修正依頼が上限に達したか、author 以外にレビューできる Specialist がいなければ作成せず Marshall に通知する
+func (o *Orchestrator) startReview(task *communication.Task, author, summary string) error {
+	now := time.Now()
+	round := 1
+	if task.Review != nil {
+		round = task.Review.Round + 1
+	}
+	if round > o.config.Review.MaxRounds {
+		log.Printf("[review] %s は修正依頼が上限（%d 回）に達したためレビューを依頼しません", task.TaskID, o.config.Review.MaxRounds)
+		err := o.tasks.Update(task.TaskID, func(t *communication.Task) error {
+			t.Review.RequestedAt = now
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		message := fmt.Sprintf("%s はレビューで %d 回修正を依頼されたため、これ以上レビューを依頼しません。"+
+			"bastion task review %s --approve で承認するか、タスクを見直してください", task.TaskID, task.Review.Round, task.TaskID)
+		return o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion")
+	}
+
+	// 元の担当以外にレビューできる Specialist がいなければレビューしない
+	specialists, err := o.Specialists()
+	if err != nil {
+		return err
+	}
+	hasReviewer := false
+	for _, info := range specialists {
+		if info.Name != author {
+			hasReviewer = true
+			break
+		}
+	}
+	if !hasReviewer {
+		log.Printf("[review] %s をレビューできる Specialist がいません", task.TaskID)
+		message := fmt.Sprintf("%s をレビューできる Specialist（%s 以外）がいないため、レビューを依頼できません。"+
+			"確認してから bastion task review %s --approve で承認してください（承認されるまでマージしません）", task.TaskID, author, task.TaskID)
+		return o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion")
+	}
+
+	review := &communication.Task{
+		TaskID:              reviewTaskID(task.TaskID, round),
+		CommandID:           task.CommandID,
+		Objective:           fmt.Sprintf("%s（担当: %s）の変更をレビューする: %s", task.TaskID, author, task.Objective),
+		Deliverables:        []string{"レポートの review（verdict と comments）"},
+		Status:              communication.TaskStatusPending,
+		Timestamp:           now,
+		Priority:            task.Priority,
+		ExcludedSpecialists: []string{author},
+		ReviewOf:            task.TaskID,
+	}
+
+	diffPath, err := o.writeReviewDiff(*task, review.TaskID)
+	if err != nil {
+		log.Printf("[review] %s の差分を書き出せませんでした: %v", task.TaskID, err)
+	}
+	review.Context = o.reviewContext(*task, summary, diffPath)
+
+	if err := o.tasks.Write(review); err != nil {
+		return fmt.Errorf("failed to create review task: %w", err)
+	}
+
+	detail := fmt.Sprintf("%s (round %d)", review.TaskID, round)
+	err = o.recordTaskEvent(task.TaskID, now, communication.TaskEventReviewRequested, detail, func(t *communication.Task) {
+		t.Review = &communication.Review{
+			Task:        review.TaskID,
+			Round:       round,
+			Verdict:     communication.ReviewVerdictPending,
+			RequestedAt: now,
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[review] %s のレビュータスク %s を作成しました", task.TaskID, review.TaskID)
+	assignment := fmt.Sprintf("%s 以外の Specialist にスケジューラが割り当てます", author)
+	// スケジューラが無効ならここで割り当てる
+	if !o.config.Scheduler.Enabled {
+		specialist, err := o.assignReview(review, now)
+		if err != nil {
+			log.Printf("[review] %s の割り当てに失敗: %v", review.TaskID, err)
+		}
+		if specialist != "" {
+			assignment = specialist + " に割り当てました"
+		} else {
+			assignment = fmt.Sprintf("%s 以外に空いている Specialist がいないため pending のままです。スケジューラが無効のため、空いている Specialist に割り当ててください",
+				author)
+		}
+	}
+	message := fmt.Sprintf("%s のレビュータスク %s を作成しました（%s）。承認されるまでマージしません",
+		task.TaskID, review.TaskID, assignment)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion"); err != nil {
+		log.Printf("[review] marshall への通知に失敗: %v", err)
+	}
+	return nil
+}
+
+// レビュータスクを元の担当以外の空いている Specialist に割り当てる（スケジューラが無効な場合）
+// 空いている Specialist がいなければ pending のまま空を返す
+func (o *Orchestrator) assignReview(review *communication.Task, now time.Time) (string, error) {
+	candidates, err := o.routeCandidates()
+	if err != nil {
+		return "", err
+	}
+	return o.assignReviewTo(review, candidates, now)
+}
+
+// 候補からレビュータスクの担当を選んで割り当てる
+func (o *Orchestrator) assignReviewTo(review *communication.Task, candidates []routeCandidate, now time.Time) (string, error) {
+	assignment, err := o.dispatchTask(review, candidates, "", now)
+	if errors.Is(err, ErrNoIdleSpecialist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return assignment.Specialist, nil
+}
+
+// タスクのブランチの差分をレビュー用に書き出す（秘密情報は伏せる）
+// ブランチがなければ空を返す
+func (o *Orchestrator) writeReviewDiff(task communication.Task, reviewID string) (string, error) {
+	branch := task.Branch
+	if branch == "" {
+		branch = parallel.TaskBranch(task.TaskID)
+	}
+	if o.worktrees == nil || !o.worktrees.IsRepository() || !o.worktrees.BranchExists(branch) {
+		return "", nil
+	}
+	diff, err := o.worktrees.Diff(branch)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(o.reviewDir(), 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(o.reviewDir(), reviewID+".diff")
+	if err := os.WriteFile(path, []byte(o.redactSecrets(diff)), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// レビュータスクの context（差分の場所・完了条件・判定の書き方）
+func (o *Orchestrator) reviewContext(task communication.Task, summary, diffPath string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s の完了報告: %s\n", task.TaskID, summary)
+	if len(task.Deliverables) > 0 {
+		fmt.Fprintf(&b, "成果物: %s\n", strings.Join(task.Deliverables, ", "))
+	}
+	if diffPath != "" {
+		fmt.Fprintf(&b, "差分: %s\n", diffPath)
+	} else {
+		b.WriteString("差分: ブランチがないため成果物を直接確認してください\n")
+	}
+
+	if task.CommandID != "" {
+		if cmd, err := o.commands.ReadByID(task.CommandID); err == nil && len(cmd.AcceptanceCriteria) > 0 {
+			b.WriteString("指令の完了条件:\n")
+			for i, c := range cmd.AcceptanceCriteria {
+				fmt.Fprintf(&b, "%d. %s\n", i+1, c.Description)
+			}
+		}
+	}
+
+	b.WriteString("差分と完了条件を確認し、レポートの review に判定を記入して完了報告してください。" +
+		"verdict は approve（承認）または request_changes（修正依頼）、comments には file / line / comment を記入します。" +
+		"ファイルは変更しないこと。")
+	return b.String()
+}
+
+// レビュータスクの完了報告を処理する（レビュータスクでなければ false）
+// 判定をレビュー対象のタスクに記録し、修正依頼なら元の担当に差し戻す
+func (o *Orchestrator) handleReviewReport(report *communication.Report) (bool, error) {
+	reviewTask, err := o.tasks.ReadByID(report.TaskID)
+	if err != nil || reviewTask.ReviewOf == "" {
+		return false, nil
+	}
+	if report.Status != communication.TaskStatusCompleted {
+		return true, nil
+	}
+
+	task, err := o.tasks.ReadByID(reviewTask.ReviewOf)
+	if err != nil {
+		return true, err
+	}
+	// 古いレビュータスクや処理済みの判定は無視する
+	if task.Review == nil || task.Review.Task != reviewTask.TaskID || task.Review.Verdict != communication.ReviewVerdictPending {
+		return true, nil
+	}
+
+	result := report.Review
+	if result == nil || !result.Verdict.IsValid() {
+		return true, o.requestVerdict(*reviewTask, *report)
+	}
+
+	now := time.Now()
+	review := *task.Review
+	review.Reviewer = report.SpecialistID
+	review.Verdict = result.Verdict
+	review.Comments = result.Comments
+	review.ReviewedAt = now
+	detail := fmt.Sprintf("%s by %s: %s", reviewTask.TaskID, report.SpecialistID, FormatReview(review))
+
+	if result.Verdict == communication.ReviewVerdictApprove {
+		log.Printf("[review] %s は %s に承認されました", task.TaskID, report.SpecialistID)
+		err := o.recordTaskEvent(task.TaskID, now, communication.TaskEventReviewApproved, detail, func(t *communication.Task) {
+			t.Review = &review
+		})
+		if err != nil {
+			return true, err
+		}
+		message := fmt.Sprintf("%s のレビューで %s が承認しました%s", task.TaskID, report.SpecialistID, formatReviewComments(review.Comments))
+		if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion"); err != nil {
+			log.Printf("[review] marshall への通知に失敗: %v", err)
+		}
+		return true, nil
+	}
+
+	comments := fmt.Sprintf("%s のレビューで %s から修正を依頼されました。対応してから再度レポートを提出してください%s",
+		task.TaskID, report.SpecialistID, formatReviewComments(review.Comments))
+	sentBack, err := o.sendBackTask(task.TaskID, task.SpecialistID, now, communication.TaskEventChangesRequested, detail, o.redactSecrets(comments), func(t *communication.Task) {
+		t.Review = &review
+	})
+	if err != nil {
+		return true, err
+	}
+
+	var message string
+	if sentBack {
+		log.Printf("[review] %s は %s に修正を依頼されたため %s に差し戻しました", task.TaskID, report.SpecialistID, task.SpecialistID)
+		if err := o.inbox.Write(task.SpecialistID, comments, communication.MessageTypeChangesRequested, "bastion"); err != nil {
+			log.Printf("[review] %s への通知に失敗: %v", task.SpecialistID, err)
+		}
+		message = fmt.Sprintf("%s のレビューで %s が修正を依頼したため %s に差し戻しました（%d 件のコメント）",
+			task.TaskID, report.SpecialistID, task.SpecialistID, len(review.Comments))
+	} else {
+		log.Printf("[review] %s は %s に修正を依頼されましたが %s に戻せないため pending に戻しました", task.TaskID, report.SpecialistID, task.SpecialistID)
+		message = fmt.Sprintf("%s のレビューで %s が修正を依頼しました。%s はほかのタスクを担当中か worktree を切り替えられないため、コメントをコンテキストに追記して pending に戻しました（%d 件のコメント）",
+			task.TaskID, report.SpecialistID, task.SpecialistID, len(review.Comments))
+		if !o.config.Scheduler.Enabled {
+			message += "。スケジューラが無効のため、空いている Specialist に割り当ててください"
+		}
+	}
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeChangesRequested, "bastion"); err != nil {
+		log.Printf("[review] marshall への通知に失敗: %v", err)
+	}
+	return true, nil
+}
+
+// 判定のないレビューの完了報告について、レビュー担当に記入を依頼する（同じレポートには 1 回だけ）
+func (o *Orchestrator) requestVerdict(reviewTask communication.Task, report communication.Report) error {
+	stamp := report.Timestamp.Format(time.RFC3339Nano)
+	for _, e := range reviewTask.History {
+		if e.Event == communication.TaskEventVerdictMissing && e.Detail == stamp {
+			return nil
+		}
+	}
+	if err := o.recordTaskEvent(reviewTask.TaskID, time.Now(), communication.TaskEventVerdictMissing, stamp, nil); err != nil {
+		return err
+	}
+
+	log.Printf("[review] %s のレポートに判定がありません", reviewTask.TaskID)
+	message := fmt.Sprintf("%s のレポートに review.verdict（approve または request_changes）がありません。判定を記入して再度レポートを提出してください", reviewTask.TaskID)
+	return o.inbox.Write(report.SpecialistID, message, communication.MessageTypeChangesRequested, "bastion")
+}
+
+// 人間または Marshall がタスクのレビューを承認する（レビュータスクの判定を待たない）
+// レビューを依頼していないタスク（レビューできる Specialist がいなかった場合など）も承認できる
+func (o *Orchestrator) ApproveReview(taskID, by string) (*communication.Task, error) {
+	if by == "" {
+		return nil, fmt.Errorf("approver must be set")
+	}
+	now := time.Now()
+	var approved *communication.Task
+	err := o.tasks.Update(taskID, func(t *communication.Task) error {
+		if t.Review == nil {
+			t.Review = &communication.Review{RequestedAt: now}
+		}
+		if t.Review.IsApproved() {
+			return fmt.Errorf("review already approved: %s", taskID)
+		}
+		t.Review.Reviewer = by
+		t.Review.Verdict = communication.ReviewVerdictApprove
+		t.Review.ReviewedAt = now
+		t.AddEvent(now, communication.TaskEventReviewApproved, "approved by "+by)
+		copied := *t
+		approved = &copied
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return approved, nil
+}
+
+// タスクのレビューの状態（レビューを依頼していなければエラー）
+func (o *Orchestrator) TaskReview(taskID string) (*communication.Review, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Review == nil {
+		return nil, fmt.Errorf("review not requested: %s", taskID)
+	}
+	return task.Review, nil
+}
@@ -0,0 +1,259 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestReview_RequestChangesAndApprove(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	sp2 := registerWorktreeSpecialist(t, o, 2)
+	o.config.Review.Enabled = true
+
+	if err := o.commands.Write(communication.Command{
+		ID:                 "cmd_001",
+		AcceptanceCriteria: []communication.AcceptanceCriterion{{Description: "ログインできる"}},
+	}); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001", CommandID: "cmd_001", Objective: "ログイン機能"}, "app.go", "package app\n")
+	report := communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Summary: "実装しました", Timestamp: time.Now()}
+
+	// 同じレポートで何度呼ばれてもレビュータスクは 1 つ
+	for i := 0; i < 2; i++ {
+		if err := o.requestReview(report); err != nil {
+			t.Fatalf("requestReview failed: %v", err)
+		}
+	}
+	review, err := o.tasks.ReadByID("task_001_review")
+	if err != nil {
+		t.Fatalf("review task should be created: %v", err)
+	}
+	if review.ReviewOf != "task_001" || review.Status != communication.TaskStatusPending || len(review.ExcludedSpecialists) != 1 || review.ExcludedSpecialists[0] != sp1 {
+		t.Errorf("review task should exclude the original specialist: %+v", review)
+	}
+	if !strings.Contains(review.Context, "1. ログインできる") || !strings.Contains(review.Context, "task_001_review.diff") {
+		t.Errorf("context should include criteria and diff: %s", review.Context)
+	}
+	if diff, err := os.ReadFile(filepath.Join(o.reviewDir(), "task_001_review.diff")); err != nil || !strings.Contains(string(diff), "+package app") {
+		t.Errorf("diff should be written: %s (%v)", diff, err)
+	}
+	if n := countMarshallMessages(t, o, "レビュータスク task_001_review を作成しました"); n != 1 {
+		t.Errorf("marshall should be notified once, got %d", n)
+	}
+
+	task, _ := o.tasks.ReadByID("task_001")
+	if reason := o.mergeBlocker(*task, nil); reason != "review is pending: task_001_review" {
+		t.Errorf("pending review should block merge: %q", reason)
+	}
+	if tasks, _ := o.commandTasks("cmd_001"); len(tasks) != 1 {
+		t.Errorf("review task should not be a command task: %+v", tasks)
+	}
+
+	// 修正依頼は元の担当に差し戻す
+	reviewReport := &communication.Report{
+		TaskID: "task_001_review", SpecialistID: sp2, Status: communication.TaskStatusCompleted, Timestamp: time.Now(),
+		Review: &communication.ReviewResult{
+			Verdict:  communication.ReviewVerdictRequestChanges,
+			Comments: []communication.ReviewComment{{File: "app.go", Line: 1, Comment: "テストがありません"}},
+		},
+	}
+	if handled, err := o.handleReviewReport(reviewReport); !handled || err != nil {
+		t.Fatalf("handleReviewReport failed: %v %v", handled, err)
+	}
+	task, _ = o.tasks.ReadByID("task_001")
+	if task.Status != communication.TaskStatusInProgress || task.SpecialistID != sp1 || task.Review.Verdict != communication.ReviewVerdictRequestChanges || task.Review.Reviewer != sp2 {
+		t.Errorf("task should be sent back: %+v %+v", task, task.Review)
+	}
+	if branch, _ := o.worktrees.CurrentBranch(parallel.WorktreeName(1)); branch != parallel.TaskBranch("task_001") {
+		t.Errorf("worktree should be switched to the task branch, got %s", branch)
+	}
+	messages, _ := o.inbox.Read(sp1)
+	if len(messages) != 1 || messages[0].Type != communication.MessageTypeChangesRequested || !strings.Contains(messages[0].Message, "app.go:1: テストがありません") {
+		t.Errorf("specialist should receive the comments: %+v", messages)
+	}
+
+	// 再提出したレポートは 2 回目のレビューを依頼する
+	task.Status = communication.TaskStatusCompleted
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	report.Timestamp = time.Now()
+	if err := o.requestReview(report); err != nil {
+		t.Fatalf("requestReview failed: %v", err)
+	}
+	task, _ = o.tasks.ReadByID("task_001")
+	if task.Review.Task != "task_001_review_2" || task.Review.Round != 2 || task.Review.Verdict != communication.ReviewVerdictPending {
+		t.Fatalf("second review should be requested: %+v", task.Review)
+	}
+
+	// 古いレビュータスクの判定は無視する
+	if _, err := o.handleReviewReport(reviewReport); err != nil {
+		t.Fatalf("handleReviewReport failed: %v", err)
+	}
+	reviewReport.TaskID = "task_001_review_2"
+	reviewReport.Review = &communication.ReviewResult{Verdict: communication.ReviewVerdictApprove}
+	if _, err := o.handleReviewReport(reviewReport); err != nil {
+		t.Fatalf("handleReviewReport failed: %v", err)
+	}
+	task, _ = o.tasks.ReadByID("task_001")
+	if !task.Review.IsApproved() || task.Status != communication.TaskStatusCompleted || o.mergeBlocker(*task, nil) != "" {
+		t.Errorf("approved task should be mergeable: %+v %+v", task, task.Review)
+	}
+	if last := task.History[len(task.History)-1]; last.Event != communication.TaskEventReviewApproved {
+		t.Errorf("approval should be recorded: %+v", last)
+	}
+}
+
+func TestHandleReviewReport_RequeuesWhenAuthorIsBusy(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	sp2 := registerWorktreeSpecialist(t, o, 2)
+	o.config.Review.Enabled = true
+
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001"}, "app.go", "package app\n")
+	report := communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Timestamp: time.Now()}
+	if err := o.requestReview(report); err != nil {
+		t.Fatalf("requestReview failed: %v", err)
+	}
+	// レビュー中に元の担当は次のタスクに着手済み
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_002", SpecialistID: sp1, Status: communication.TaskStatusInProgress}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.CheckoutTask(sp1, "task_002"); err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+
+	reviewReport := &communication.Report{
+		TaskID: "task_001_review", SpecialistID: sp2, Status: communication.TaskStatusCompleted, Timestamp: time.Now(),
+		Review: &communication.ReviewResult{
+			Verdict:  communication.ReviewVerdictRequestChanges,
+			Comments: []communication.ReviewComment{{File: "app.go", Comment: "テストがありません"}},
+		},
+	}
+	if handled, err := o.handleReviewReport(reviewReport); !handled || err != nil {
+		t.Fatalf("handleReviewReport failed: %v %v", handled, err)
+	}
+
+	// 元の担当の worktree は切り替えず、ブランチを引き継いでスケジューラの割り当てを待つ
+	task, _ := o.tasks.ReadByID("task_001")
+	if task.Status != communication.TaskStatusPending || task.SpecialistID != "" || task.Branch != parallel.TaskBranch("task_001") {
+		t.Errorf("task should be requeued with its branch: %+v", task)
+	}
+	if task.Review == nil || task.Review.Verdict != communication.ReviewVerdictRequestChanges || !strings.Contains(task.Context, "app.go: テストがありません") {
+		t.Errorf("review and comments should be recorded: %+v %q", task.Review, task.Context)
+	}
+	if branch, _ := o.worktrees.CurrentBranch(parallel.WorktreeName(1)); branch != parallel.TaskBranch("task_002") {
+		t.Errorf("worktree of the busy author should stay on task_002, got %s", branch)
+	}
+	if n := countMarshallMessages(t, o, "pending に戻しました"); n != 1 {
+		t.Errorf("marshall should be notified, got %d", n)
+	}
+}
+
+func TestHandleReviewReport_MissingVerdict(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	for _, task := range []*communication.Task{
+		{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted,
+			Review: &communication.Review{Task: "task_001_review", Round: 1, Verdict: communication.ReviewVerdictPending}},
+		{TaskID: "task_001_review", SpecialistID: "specialist_2", Status: communication.TaskStatusCompleted, ReviewOf: "task_001"},
+	} {
+		if err := o.tasks.Write(task); err != nil {
+			t.Fatalf("failed to write task: %v", err)
+		}
+	}
+
+	report := &communication.Report{TaskID: "task_001_review", SpecialistID: "specialist_2", Status: communication.TaskStatusCompleted, Timestamp: time.Now()}
+	for i := 0; i < 2; i++ {
+		if handled, err := o.handleReviewReport(report); !handled || err != nil {
+			t.Fatalf("handleReviewReport failed: %v %v", handled, err)
+		}
+	}
+	if messages, _ := o.inbox.Read("specialist_2"); len(messages) != 1 || !strings.Contains(messages[0].Message, "review.verdict") {
+		t.Errorf("reviewer should be asked once for a verdict: %+v", messages)
+	}
+	if task, _ := o.tasks.ReadByID("task_001"); task.Review.Verdict != communication.ReviewVerdictPending {
+		t.Errorf("verdict should stay pending: %+v", task.Review)
+	}
+
+	// 承認は人間も行える
+	if _, err := o.ApproveReview("task_001", "alice"); err != nil {
+		t.Fatalf("ApproveReview failed: %v", err)
+	}
+	if review, _ := o.TaskReview("task_001"); !review.IsApproved() || review.Reviewer != "alice" {
+		t.Errorf("review should be approved: %+v", review)
+	}
+	if _, err := o.ApproveReview("task_001", "alice"); err == nil {
+		t.Error("approving twice should fail")
+	}
+}
+
+func TestRequestReview_NoOtherSpecialist(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	o.config.Review.Enabled = true
+
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001"}, "app.go", "package app\n")
+	report := communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Timestamp: time.Now()}
+	if err := o.requestReview(report); err != nil {
+		t.Fatalf("requestReview failed: %v", err)
+	}
+	if _, err := o.tasks.ReadByID("task_001_review"); err == nil {
+		t.Error("review task should not be created without another specialist")
+	}
+	if n := countMarshallMessages(t, o, "レビューできる Specialist"); n != 1 {
+		t.Errorf("marshall should be notified, got %d", n)
+	}
+}
+
+func TestAssignReview_WithoutScheduler(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	sp2 := registerWorktreeSpecialist(t, o, 2)
+	o.config.Review.Enabled = true
+	now := time.Now()
+
+	// 存在しないペインにして、どの Specialist も idle と判定されないようにする
+	specialists, _ := o.Specialists()
+	for _, info := range specialists {
+		info.Target = "%999999"
+		if err := o.registry.Register(info); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001"}, "app.go", "package app\n")
+	report := communication.Report{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusCompleted, Timestamp: now}
+	if err := o.requestReview(report); err != nil {
+		t.Fatalf("requestReview failed: %v", err)
+	}
+	review, err := o.tasks.ReadByID("task_001_review")
+	if err != nil || review.Status != communication.TaskStatusPending {
+		t.Fatalf("review should stay pending without an idle specialist: %+v (%v)", review, err)
+	}
+	if n := countMarshallMessages(t, o, "スケジューラが無効のため"); n != 1 {
+		t.Errorf("marshall should be asked to assign the review, got %d", n)
+	}
+
+	// 元の担当しか空いていなければ割り当てない
+	candidates := []routeCandidate{{Info: specialists[0]}, {Info: specialists[1], Skipped: string(AgentStateBusy)}}
+	if specialist, err := o.assignReviewTo(review, candidates, now); err != nil || specialist != "" {
+		t.Fatalf("review should not be assigned to the author: %q (%v)", specialist, err)
+	}
+
+	candidates = []routeCandidate{{Info: specialists[0]}, {Info: specialists[1]}}
+	specialist, err := o.assignReviewTo(review, candidates, now)
+	if err != nil || specialist != sp2 {
+		t.Fatalf("review should be assigned to %s: %q (%v)", sp2, specialist, err)
+	}
+	if got, _ := o.tasks.ReadByID("task_001_review"); got.Status != communication.TaskStatusAssigned || got.SpecialistID != sp2 {
+		t.Errorf("review should be assigned: %+v", got)
+	}
+}
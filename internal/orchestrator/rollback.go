@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 指令のロールバック結果
+type RollbackReport struct {
+	CommandID string
+	// ロールバック用ブランチとその worktree
+	Branch   string
+	Worktree string
+	// 分岐元（マージを取り込んだブランチ）
+	Base string
+	// 今回打ち消したマージコミット（新しい順）
+	Reverted []communication.RevertedCommit
+	// 既に打ち消していたマージコミット
+	AlreadyReverted []string
+	// コンフリクトした revert（なければ nil）
+	Conflict *RevertConflict
+}
+
+// revert のコンフリクトの内容
+type RevertConflict struct {
+	TaskID string
+	Commit string
+	Files  []string
+}
+
+// 指令のマージの記録（記録がなければタスクのマージコミットを依存関係の順に並べる）
+func (o *Orchestrator) commandMerges(cmd *communication.Command) ([]communication.CommandMerge, error) {
+	if len(cmd.Merges) > 0 {
+		return cmd.Merges, nil
+	}
+
+	tasks, err := o.commandTasks(cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	ordered, err := dependencyOrder(tasks)
+	if err != nil {
+		return nil, err
+	}
+	var merges []communication.CommandMerge
+	for _, task := range ordered {
+		if task.MergedCommit != "" {
+			merges = append(merges, communication.CommandMerge{Task: task.TaskID, Branch: task.Branch, Commit: task.MergedCommit, Commits: task.Commits})
+		}
+	}
+	return merges, nil
+}
+
+// マージコミットをすべて取り込んでいる分岐元（プロジェクトルートの HEAD、なければ統合ブランチ）
+func (o *Orchestrator) rollbackBase(commandID string, merges []communication.CommandMerge) (string, error) {
+	candidates := []string{"HEAD"}
+	if integration := parallel.IntegrationBranch(commandID); o.worktrees.BranchExists(integration) {
+		candidates = append(candidates, integration)
+	}
+
+	for _, base := range candidates {
+		contained := true
+		for _, m := range merges {
+			if !o.worktrees.IsAncestor(m.Commit, base) {
+				contained = false
+				break
+			}
+		}
+		if contained {
+			return base, nil
+		}
+	}
+	return "", fmt.Errorf("merged commits of %s are not in %v", commandID, candidates)
+}
+
+// 指令のマージを打ち消す revert コミットをロールバック用ブランチに作成する（新しいマージから順に打ち消す）
+// 履歴は書き換えない（D002）。ブランチはレビューしてから人間がマージする
+// コンフリクトしたら revert を進行中のまま残して記録する
+// ロールバック用 worktree で解消して git revert --continue でコミットしてから再実行すると続きから打ち消す
+func (o *Orchestrator) RollbackCommand(commandID string) (*RollbackReport, error) {
+	if !o.worktrees.IsRepository() {
+		return nil, fmt.Errorf("project root is not a git repository: %s", o.projectRoot)
+	}
+
+	cmd, err := o.commands.ReadByID(commandID)
+	if err != nil {
+		return nil, err
+	}
+	merges, err := o.commandMerges(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(merges) == 0 {
+		return nil, fmt.Errorf("no merged commits recorded for command: %s", commandID)
+	}
+
+	name := parallel.RollbackWorktreeName(commandID)
+	branch := parallel.RollbackBranch(commandID)
+	report := &RollbackReport{CommandID: commandID, Branch: branch}
+
+	// 再実行時は既存のロールバック用ブランチに続けて打ち消す
+	rollback := cmd.Rollback
+	if rollback == nil || !o.worktrees.BranchExists(branch) {
+		base, err := o.rollbackBase(commandID, merges)
+		if err != nil {
+			return nil, err
+		}
+		baseCommit, err := o.worktrees.Commit(base)
+		if err != nil {
+			return nil, err
+		}
+		rollback = &communication.Rollback{Branch: branch, Base: base, BaseCommit: baseCommit, CreatedAt: time.Now()}
+	}
+	report.Base = rollback.Base
+
+	path, err := o.worktrees.Ensure(name, branch, rollback.BaseCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare rollback worktree: %w", err)
+	}
+	report.Worktree = path
+
+	// 解消されていない revert があれば、記録したコンフリクトを返す
+	if o.worktrees.RevertInProgress(name) {
+		files, err := o.worktrees.UnmergedFiles(name)
+		if err != nil {
+			return report, err
+		}
+		report.Conflict = &RevertConflict{Commit: rollback.Conflict, Files: files}
+		for _, m := range merges {
+			if m.Commit == rollback.Conflict {
+				report.Conflict.TaskID = m.Task
+			}
+		}
+		return report, nil
+	}
+
+	rollback.Conflict = ""
+	rollback.ConflictFiles = nil
+	for i := len(merges) - 1; i >= 0; i-- {
+		m := merges[i]
+		result, err := o.worktrees.Revert(name, m.Commit)
+		if errors.Is(err, parallel.ErrRevertConflict) {
+			report.Conflict = &RevertConflict{TaskID: m.Task, Commit: m.Commit, Files: result.Conflicts}
+			rollback.Conflict = m.Commit
+			rollback.ConflictFiles = result.Conflicts
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		if result.AlreadyReverted {
+			report.AlreadyReverted = append(report.AlreadyReverted, m.Commit)
+			continue
+		}
+
+		reverted := communication.RevertedCommit{Task: m.Task, Commit: m.Commit, Revert: result.Commit}
+		report.Reverted = append(report.Reverted, reverted)
+		rollback.Reverted = append(rollback.Reverted, reverted)
+	}
+
+	rollback.UpdatedAt = time.Now()
+	err = o.commands.Update(commandID, func(c *communication.Command) error {
+		c.Rollback = rollback
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to record rollback: %w", err)
+	}
+	return report, nil
+}
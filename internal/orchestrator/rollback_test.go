@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestRollbackCommand(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	now := time.Now()
+
+	if err := o.commands.Write(communication.Command{ID: "cmd_001", Status: communication.CommandStatusCompleted}); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001", CommandID: "cmd_001", Timestamp: now}, "a.txt", "a\n")
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_002", CommandID: "cmd_001", Timestamp: now.Add(time.Second)}, "b.txt", "b\n")
+	merge, err := o.MergeCommand("cmd_001")
+	if err != nil || len(merge.Merged) != 2 {
+		t.Fatalf("MergeCommand failed: %+v (%v)", merge, err)
+	}
+
+	// マージしたコミットが指令とタスクに記録される
+	cmd, _ := o.commands.ReadByID("cmd_001")
+	if len(cmd.Merges) != 2 || cmd.Merges[0].Task != "task_001" || cmd.Merges[0].Commit != merge.Merged[0].Commit || len(cmd.Merges[0].Commits) != 1 {
+		t.Fatalf("merges should be recorded on the command: %+v", cmd.Merges)
+	}
+	if task, _ := o.tasks.ReadByID("task_002"); len(task.Commits) != 1 || task.Commits[0] != cmd.Merges[1].Commits[0] {
+		t.Errorf("merged commits should be recorded on the task: %+v", task)
+	}
+
+	report, err := o.RollbackCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("RollbackCommand failed: %v", err)
+	}
+	// 統合ブランチはプロジェクトルートの HEAD に取り込まれていないため統合ブランチから分岐する
+	if report.Branch != parallel.RollbackBranch("cmd_001") || report.Base != parallel.IntegrationBranch("cmd_001") || report.Conflict != nil {
+		t.Fatalf("unexpected rollback: %+v", report)
+	}
+	if len(report.Reverted) != 2 || report.Reverted[0].Task != "task_002" || report.Reverted[1].Task != "task_001" {
+		t.Errorf("merges should be reverted newest first: %+v", report.Reverted)
+	}
+	for _, file := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(report.Worktree, file)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed on the rollback branch: %v", file, err)
+		}
+		if _, err := os.Stat(filepath.Join(merge.Worktree, file)); err != nil {
+			t.Errorf("%s should stay on the integration branch: %v", file, err)
+		}
+	}
+	// 履歴は書き換えない
+	for _, m := range merge.Merged {
+		if !o.worktrees.IsAncestor(m.Commit, report.Branch) {
+			t.Errorf("merge commit %s should stay in history", m.Commit)
+		}
+	}
+
+	cmd, _ = o.commands.ReadByID("cmd_001")
+	if cmd.Rollback == nil || cmd.Rollback.Branch != report.Branch || len(cmd.Rollback.Reverted) != 2 || cmd.Rollback.BaseCommit == "" {
+		t.Errorf("rollback should be recorded on the command: %+v", cmd.Rollback)
+	}
+
+	// 再実行しても打ち消し済みのマージは再度打ち消さない
+	report, err = o.RollbackCommand("cmd_001")
+	if err != nil {
+		t.Fatalf("RollbackCommand failed: %v", err)
+	}
+	if len(report.Reverted) != 0 || len(report.AlreadyReverted) != 2 {
+		t.Errorf("merges should already be reverted: %+v", report)
+	}
+}
+
+func TestRollbackCommand_NothingMerged(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+
+	if err := o.commands.Write(communication.Command{ID: "cmd_001"}); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+	if _, err := o.RollbackCommand("cmd_001"); err == nil {
+		t.Error("rollback should fail without merged commits")
+	}
+	if o.worktrees.BranchExists(parallel.RollbackBranch("cmd_001")) {
+		t.Error("rollback branch should not be created")
+	}
+}
+
+func TestRollbackCommand_ConflictInProgress(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+
+	if err := o.commands.Write(communication.Command{ID: "cmd_001", Status: communication.CommandStatusCompleted}); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+	completeTaskOnBranch(t, o, sp1, &communication.Task{TaskID: "task_001", CommandID: "cmd_001", Timestamp: time.Now()}, "a.txt", "a\n")
+	merge, err := o.MergeCommand("cmd_001")
+	if err != nil || len(merge.Merged) != 1 {
+		t.Fatalf("MergeCommand failed: %+v (%v)", merge, err)
+	}
+
+	// ロールバック用ブランチで同じファイルを変更しておくと revert がコンフリクトする
+	path, err := o.worktrees.Ensure(parallel.RollbackWorktreeName("cmd_001"), parallel.RollbackBranch("cmd_001"), parallel.IntegrationBranch("cmd_001"))
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "a.txt"), []byte("edited\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, args := range [][]string{{"add", "a.txt"}, {"commit", "-q", "-m", "edit a"}} {
+		cmd := exec.Command("git", append([]string{"-C", path}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		report, err := o.RollbackCommand("cmd_001")
+		if err != nil {
+			t.Fatalf("RollbackCommand failed: %v", err)
+		}
+		// 解消前の再実行でも同じコンフリクトを返す
+		if c := report.Conflict; c == nil || c.TaskID != "task_001" || c.Commit != merge.Merged[0].Commit || len(c.Files) != 1 || c.Files[0] != "a.txt" {
+			t.Fatalf("run %d: unexpected conflict: %+v", i+1, report.Conflict)
+		}
+		if !o.worktrees.RevertInProgress(parallel.RollbackWorktreeName("cmd_001")) {
+			t.Fatalf("run %d: revert should stay in progress", i+1)
+		}
+	}
+	if cmd, _ := o.commands.ReadByID("cmd_001"); cmd.Rollback == nil || cmd.Rollback.Conflict != merge.Merged[0].Commit {
+		t.Errorf("conflict should be recorded on the command: %+v", cmd.Rollback)
+	}
+}
@@ -0,0 +1,240 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+// スコアの重み
+const (
+	// trigger_patterns がタスク本文に含まれる
+	triggerWeight = 3
+	// capability がそのままタスク本文に含まれる
+	capabilityWeight = 2
+	// capability を構成する語がタスク本文に含まれる
+	capabilityTokenWeight = 1
+	// 語として扱う最小の文字数（"a" や "の" などを除外）
+	minTokenLength = 3
+)
+
+// 割り当て可能な Specialist がいない
+var ErrNoIdleSpecialist = errors.New("no idle specialist available")
+
+// ルーティングの候補
+type routeCandidate struct {
+	Info AgentInfo
+	// 割り当てられない理由（空なら割り当て可能）
+	Skipped string
+}
+
+// タスクと Specialist 定義の一致度を計算
+// 返り値: スコアと一致した trigger / capability
+func scoreSpecialist(task *communication.Task, spec *SpecialistConfig) (int, []string) {
+	if spec == nil {
+		return 0, nil
+	}
+
+	text := strings.ToLower(task.Objective + "\n" + strings.Join(task.Deliverables, "\n"))
+
+	score := 0
+	var matched []string
+	for _, trigger := range spec.Triggers {
+		trigger = strings.ToLower(strings.TrimSpace(trigger))
+		if trigger != "" && strings.Contains(text, trigger) {
+			score += triggerWeight
+			matched = append(matched, "trigger:"+trigger)
+		}
+	}
+
+	for _, capability := range spec.Capabilities {
+		capability = strings.ToLower(strings.TrimSpace(capability))
+		if capability == "" {
+			continue
+		}
+		if strings.Contains(text, capability) {
+			score += capabilityWeight
+			matched = append(matched, "capability:"+capability)
+			continue
+		}
+		for _, token := range capabilityTokens(capability) {
+			if strings.Contains(text, token) {
+				score += capabilityTokenWeight
+				matched = append(matched, "capability:"+token)
+			}
+		}
+	}
+
+	return score, matched
+}
+
+// capability を語に分割（英数字以外で区切り、短い語は除外）
+func capabilityTokens(capability string) []string {
+	fields := strings.FieldsFunc(capability, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) || r == '・' || r == '、'
+	})
+
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if utf8.RuneCountInString(field) < minTokenLength || seen[field] {
+			continue
+		}
+		seen[field] = true
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// 候補からタスクの担当を選ぶ
+// スコアが最も高い割り当て可能な Specialist を選び、どれも一致しなければ汎用 Specialist を優先する
+func selectSpecialist(task *communication.Task, candidates []routeCandidate) (*communication.RoutingDecision, error) {
+	type scored struct {
+		candidate routeCandidate
+		score     int
+		matched   []string
+	}
+
+	excluded := excludedSpecialists(task, candidates)
+
+	var all []scored
+	for _, c := range candidates {
+		if c.Skipped == "" && excluded[c.Info.Name] {
+			c.Skipped = "excluded: previous attempt failed"
+		}
+		score, matched := scoreSpecialist(task, c.Info.Spec)
+		all = append(all, scored{candidate: c, score: score, matched: matched})
+	}
+
+	// スコアの高い順、同点なら登録順（Index の小さい順）
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].score > all[j].score
+	})
+
+	decision := &communication.RoutingDecision{DecidedAt: time.Now()}
+	for _, s := range all {
+		decision.Candidates = append(decision.Candidates, communication.RoutingScore{
+			Specialist: s.candidate.Info.Name,
+			Score:      s.score,
+			Skipped:    s.candidate.Skipped,
+		})
+	}
+
+	var best *scored
+	for i := range all {
+		if all[i].candidate.Skipped == "" {
+			best = &all[i]
+			break
+		}
+	}
+
+	// 一致がなければ専門外の外部 Specialist より汎用 Specialist に回す
+	if best != nil && best.score == 0 && best.candidate.Info.Spec != nil {
+		for i := range all {
+			if all[i].candidate.Skipped == "" && all[i].candidate.Info.Spec == nil {
+				best = &all[i]
+				break
+			}
+		}
+	}
+	if best == nil {
+		return decision, ErrNoIdleSpecialist
+	}
+
+	decision.Specialist = best.candidate.Info.Name
+	decision.Score = best.score
+	decision.Matched = best.matched
+	if best.score > 0 {
+		decision.Reason = fmt.Sprintf("%s の trigger_patterns / capabilities に一致", best.candidate.Info.Name)
+	} else {
+		decision.Reason = "一致する専門 Specialist がないため空いている Specialist を選択"
+	}
+	return decision, nil
+}
+
+// 再実行で割り当てない Specialist
+// 失敗した担当以外に Specialist が登録されていなければ除外しない（レビュータスクは常に元の担当を除外する）
+func excludedSpecialists(task *communication.Task, candidates []routeCandidate) map[string]bool {
+	if len(task.ExcludedSpecialists) == 0 {
+		return nil
+	}
+
+	excluded := make(map[string]bool, len(task.ExcludedSpecialists))
+	for _, name := range task.ExcludedSpecialists {
+		excluded[name] = true
+	}
+	if task.ReviewOf != "" {
+		return excluded
+	}
+	for _, c := range candidates {
+		if !excluded[c.Info.Name] {
+			return excluded
+		}
+	}
+	return nil
+}
+
+// タスクの担当候補を判定して記録する
+// 割り当ては行わず、判定結果（候補とスコア）をタスクの routing に保存する
+func (o *Orchestrator) RouteTask(taskID string) (*communication.RoutingDecision, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.IsFinished() {
+		return nil, fmt.Errorf("task is already %s: %s", task.Status, taskID)
+	}
+
+	candidates, err := o.routeCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	decision, err := selectSpecialist(task, candidates)
+	if err != nil {
+		return decision, err
+	}
+
+	err = o.tasks.Update(taskID, func(t *communication.Task) error {
+		t.Routing = decision
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record routing decision: %w", err)
+	}
+	return decision, nil
+}
+
+// 登録済みの Specialist と、それぞれ割り当て可能かを取得
+func (o *Orchestrator) routeCandidates() ([]routeCandidate, error) {
+	specialists, err := o.Specialists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list specialists: %w", err)
+	}
+
+	var candidates []routeCandidate
+	for _, info := range specialists {
+		candidate := routeCandidate{Info: info}
+
+		assigned, err := o.AssignedTasks(info.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(assigned) > 0 {
+			candidate.Skipped = "assigned: " + strings.Join(assigned, ", ")
+		} else if state, err := o.detectTargetState(info.Target); err != nil {
+			candidate.Skipped = "state unknown"
+		} else if state != AgentStateIdle {
+			candidate.Skipped = string(state)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
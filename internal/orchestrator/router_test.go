@@ -0,0 +1,183 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+var securitySpec = &SpecialistConfig{
+	Name:         "security-auditor",
+	Persona:      "Senior Security Engineer",
+	Capabilities: []string{"OWASP Top 10 チェック", "依存関係脆弱性スキャン", "認証・認可レビュー"},
+	Triggers:     []string{"セキュリティ", "脆弱性", "監査"},
+}
+
+var docsSpec = &SpecialistConfig{
+	Name:         "doc-writer",
+	Persona:      "Technical Writer",
+	Capabilities: []string{"API documentation"},
+	Triggers:     []string{"ドキュメント", "README"},
+}
+
+func TestScoreSpecialist(t *testing.T) {
+	task := &communication.Task{
+		Objective:    "ログイン処理の脆弱性を監査する（OWASP の観点で）",
+		Deliverables: []string{"docs/security-report.md"},
+	}
+
+	score, matched := scoreSpecialist(task, securitySpec)
+	// trigger: 脆弱性, 監査 (3+3) + capability token: owasp (1)
+	if score != 7 {
+		t.Errorf("expected score 7, got %d (matched: %v)", score, matched)
+	}
+
+	score, _ = scoreSpecialist(task, docsSpec)
+	if score != 0 {
+		t.Errorf("expected score 0 for doc-writer, got %d", score)
+	}
+
+	// 汎用 Specialist は常に 0
+	if score, _ := scoreSpecialist(task, nil); score != 0 {
+		t.Errorf("expected score 0 for generic specialist, got %d", score)
+	}
+}
+
+func TestScoreSpecialist_CaseInsensitive(t *testing.T) {
+	task := &communication.Task{Objective: "Update the readme and api documentation"}
+
+	score, matched := scoreSpecialist(task, docsSpec)
+	// trigger: readme (3) + capability: api documentation (2)
+	if score != 5 {
+		t.Errorf("expected score 5, got %d (matched: %v)", score, matched)
+	}
+}
+
+func TestCapabilityTokens(t *testing.T) {
+	tokens := capabilityTokens("owasp top 10 チェック")
+	want := []string{"owasp", "top", "チェック"}
+	if len(tokens) != len(want) {
+		t.Fatalf("expected %v, got %v", want, tokens)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, tokens)
+		}
+	}
+}
+
+func TestSelectSpecialist(t *testing.T) {
+	generic := routeCandidate{Info: AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1}}
+	security := routeCandidate{Info: AgentInfo{Name: "security-auditor", Type: AgentSpecialist, Index: 2, Spec: securitySpec}}
+	docs := routeCandidate{Info: AgentInfo{Name: "doc-writer", Type: AgentSpecialist, Index: 3, Spec: docsSpec}}
+
+	t.Run("trigger に一致する Specialist を選ぶ", func(t *testing.T) {
+		task := &communication.Task{Objective: "依存パッケージの脆弱性を調査"}
+
+		decision, err := selectSpecialist(task, []routeCandidate{generic, security, docs})
+		if err != nil {
+			t.Fatalf("selectSpecialist failed: %v", err)
+		}
+		if decision.Specialist != "security-auditor" {
+			t.Errorf("expected security-auditor, got %s", decision.Specialist)
+		}
+		if len(decision.Candidates) != 3 || decision.Candidates[0].Specialist != "security-auditor" {
+			t.Errorf("candidates should be sorted by score: %+v", decision.Candidates)
+		}
+	})
+
+	t.Run("一致がなければ汎用 Specialist を選ぶ", func(t *testing.T) {
+		task := &communication.Task{Objective: "ページネーションを実装"}
+
+		decision, err := selectSpecialist(task, []routeCandidate{security, generic, docs})
+		if err != nil {
+			t.Fatalf("selectSpecialist failed: %v", err)
+		}
+		if decision.Specialist != "specialist_1" || decision.Score != 0 {
+			t.Errorf("expected specialist_1 with score 0, got %s (%d)", decision.Specialist, decision.Score)
+		}
+	})
+
+	t.Run("空いていない Specialist は選ばない", func(t *testing.T) {
+		busy := security
+		busy.Skipped = string(AgentStateBusy)
+		task := &communication.Task{Objective: "脆弱性の監査"}
+
+		decision, err := selectSpecialist(task, []routeCandidate{busy, generic})
+		if err != nil {
+			t.Fatalf("selectSpecialist failed: %v", err)
+		}
+		if decision.Specialist != "specialist_1" {
+			t.Errorf("expected specialist_1, got %s", decision.Specialist)
+		}
+		// スキップした候補も記録される
+		if decision.Candidates[0].Skipped != string(AgentStateBusy) {
+			t.Errorf("skipped candidate should be recorded: %+v", decision.Candidates)
+		}
+	})
+
+	t.Run("再実行では失敗した Specialist を選ばない", func(t *testing.T) {
+		task := &communication.Task{Objective: "脆弱性の監査", ExcludedSpecialists: []string{"security-auditor"}}
+
+		decision, err := selectSpecialist(task, []routeCandidate{security, generic})
+		if err != nil {
+			t.Fatalf("selectSpecialist failed: %v", err)
+		}
+		if decision.Specialist != "specialist_1" {
+			t.Errorf("expected specialist_1, got %s", decision.Specialist)
+		}
+
+		// ほかに Specialist がいなければ除外しない
+		decision, err = selectSpecialist(task, []routeCandidate{security})
+		if err != nil || decision.Specialist != "security-auditor" {
+			t.Errorf("expected security-auditor, got %+v (%v)", decision, err)
+		}
+	})
+
+	t.Run("空きがなければエラー", func(t *testing.T) {
+		busy := generic
+		busy.Skipped = string(AgentStateBusy)
+
+		_, err := selectSpecialist(&communication.Task{Objective: "x"}, []routeCandidate{busy})
+		if !errors.Is(err, ErrNoIdleSpecialist) {
+			t.Errorf("expected ErrNoIdleSpecialist, got %v", err)
+		}
+	})
+}
+
+func TestRouteTask_FinishedTask(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	if _, err := o.RouteTask("task_001"); err == nil {
+		t.Error("expected error for completed task")
+	}
+}
+
+func TestRouteTask_SkipsAssignedSpecialist(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.registry.Register(AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1, Target: "%99999"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	for _, task := range []*communication.Task{
+		{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusInProgress},
+		{TaskID: "task_002", Objective: "API を実装", Status: communication.TaskStatusPending},
+	} {
+		if err := o.tasks.Write(task); err != nil {
+			t.Fatalf("failed to write task: %v", err)
+		}
+	}
+
+	decision, err := o.RouteTask("task_002")
+	if !errors.Is(err, ErrNoIdleSpecialist) {
+		t.Fatalf("expected ErrNoIdleSpecialist, got %v", err)
+	}
+	if len(decision.Candidates) != 1 || decision.Candidates[0].Skipped != "assigned: task_001" {
+		t.Errorf("expected specialist_1 to be skipped as assigned, got %+v", decision.Candidates)
+	}
+}
@@ -0,0 +1,298 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+// スケジューラによる割り当て結果
+type Assignment struct {
+	TaskID     string
+	Specialist string
+	// 付け替え元の Specialist（新規割り当ての場合は空）
+	StolenFrom string
+}
+
+// 着手できるタスクを抽出（未割当の pending で、依存タスクがすべて completed）
+func readyTasks(tasks []communication.Task) []communication.Task {
+	status := make(map[string]communication.TaskStatus, len(tasks))
+	for _, task := range tasks {
+		status[task.TaskID] = task.Status
+	}
+
+	var ready []communication.Task
+	for _, task := range tasks {
+		if task.Status != communication.TaskStatusPending || task.SpecialistID != "" {
+			continue
+		}
+
+		// 存在しない依存タスクは未完了として扱う
+		resolved := true
+		for _, dep := range task.Dependencies {
+			if status[dep] != communication.TaskStatusCompleted {
+				resolved = false
+				break
+			}
+		}
+		if resolved {
+			ready = append(ready, task)
+		}
+	}
+	return ready
+}
+
+// 割り当て後 stealAfter を過ぎても着手されていないタスクを抽出
+func staleAssignments(tasks []communication.Task, now time.Time, stealAfter time.Duration) []communication.Task {
+	var stale []communication.Task
+	for _, task := range tasks {
+		if task.Status != communication.TaskStatusAssigned || task.AssignedAt.IsZero() {
+			continue
+		}
+		if now.Sub(task.AssignedAt) >= stealAfter {
+			stale = append(stale, task)
+		}
+	}
+	return stale
+}
+
+// 着手できるタスクを空いている Specialist に割り当てる
+// 未着手のまま放置されたタスクは、担当が idle でなければ空いている Specialist に付け替える
+func (o *Orchestrator) Dispatch(now time.Time) ([]Assignment, error) {
+	tasks, err := o.tasks.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	candidates, err := o.routeCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	// 実行中のタスクのリース（割り当てたタスクの分を追加していく）
+	var leases []Lease
+	if o.useLeases() {
+		leases, err = o.leases.Sync(tasks, now)
+		if err != nil {
+			return nil, err
+		}
+		defer o.syncLeases(now)
+	}
+
+	var assignments []Assignment
+	ready := readyTasks(tasks)
+	for i := 0; i < len(ready); i++ {
+		task := ready[i]
+
+		// 承認が必要なタスクは承認されるまで割り当てない（試行は元のタスクで判定済み）
+		if task.AttemptOf == "" {
+			held, err := o.gateTask(task, now)
+			if err != nil {
+				return assignments, err
+			}
+			if held {
+				log.Printf("[scheduler] %s は承認待ちのため割り当てを保留します", task.TaskID)
+				continue
+			}
+		}
+
+		// attempts を指定したタスクは試行に分け、試行を続けて割り当てる
+		if task.Attempts > 1 && task.AttemptOf == "" && task.Selection == nil {
+			attempts, err := o.fanOutAttempts(task, now)
+			if err != nil {
+				return assignments, err
+			}
+			ready = append(ready, attempts...)
+			continue
+		}
+
+		var conflicts []LeaseConflict
+		if o.useLeases() {
+			for _, c := range leaseConflicts(taskPaths(task), task.TaskID, leases) {
+				if !isSiblingAttempt(c.TaskID, task.AttemptOf) {
+					conflicts = append(conflicts, c)
+				}
+			}
+			if len(conflicts) > 0 && o.config.Leases.Mode == config.LeaseModeBlock {
+				log.Printf("[scheduler] %s は %s と変更予定パスが重なるため割り当てを保留します", task.TaskID, conflicts[0].TaskID)
+				continue
+			}
+		}
+
+		assignment, err := o.dispatchTask(&task, candidates, "", now)
+		if errors.Is(err, ErrNoIdleSpecialist) {
+			return assignments, nil
+		}
+		if err != nil {
+			return assignments, err
+		}
+		assignments = append(assignments, *assignment)
+
+		if paths := taskPaths(task); o.useLeases() && len(paths) > 0 {
+			leases = append(leases, Lease{TaskID: task.TaskID, Specialist: assignment.Specialist, Paths: paths, AcquiredAt: now})
+		}
+		if len(conflicts) > 0 {
+			o.warnLeaseConflicts(task.TaskID, assignment.Specialist, conflicts)
+		}
+	}
+
+	for _, task := range staleAssignments(tasks, now, o.config.Scheduler.StealAfter) {
+		// 担当が idle なら inbox の確認待ち（エスカレーションに任せる）
+		if state, err := o.DetectState(task.SpecialistID); err == nil && state == AgentStateIdle {
+			continue
+		}
+
+		// 付け替え元は候補から外す
+		from := task.SpecialistID
+		for i := range candidates {
+			if candidates[i].Info.Name == from {
+				candidates[i].Skipped = "stale: " + task.TaskID
+			}
+		}
+
+		// 付け替え先がいなければ付け替え元の worktree はそのままにする
+		if _, err := selectSpecialist(&task, candidates); errors.Is(err, ErrNoIdleSpecialist) {
+			return assignments, nil
+		} else if err != nil {
+			return assignments, err
+		}
+
+		// 付け替え元の worktree からタスクのブランチを外す（変更があれば付け替えない）
+		if err := o.releaseTaskWorktree(from); err != nil {
+			log.Printf("[scheduler] %s を付け替えられません: %v", task.TaskID, err)
+			continue
+		}
+
+		assignment, err := o.dispatchTask(&task, candidates, from, now)
+		if err != nil {
+			// 付け替えられなかったタスクは付け替え元の worktree に戻す
+			if _, _, restoreErr := o.prepareTaskWorktree(from, &task); restoreErr != nil {
+				log.Printf("[scheduler] %s の worktree を %s に戻せませんでした: %v", from, task.TaskID, restoreErr)
+			}
+			if errors.Is(err, ErrNoIdleSpecialist) {
+				return assignments, nil
+			}
+			return assignments, err
+		}
+		assignments = append(assignments, *assignment)
+	}
+
+	return assignments, nil
+}
+
+// 割り当て後のタスクからリースを保存し直す
+func (o *Orchestrator) syncLeases(now time.Time) {
+	tasks, err := o.tasks.Read()
+	if err == nil {
+		_, err = o.leases.Sync(tasks, now)
+	}
+	if err != nil {
+		log.Printf("[scheduler] リースの更新に失敗: %v", err)
+	}
+}
+
+// 変更予定パスが重なるタスクを割り当てたことを Marshall に警告
+func (o *Orchestrator) warnLeaseConflicts(taskID, specialist string, conflicts []LeaseConflict) {
+	var details []string
+	for _, c := range conflicts {
+		details = append(details, fmt.Sprintf("%s（%s）: %s", c.TaskID, c.Specialist, strings.Join(c.Paths, ", ")))
+	}
+	message := fmt.Sprintf("%s を %s に割り当てましたが、実行中のタスクと変更予定パスが重なっています: %s。マージ時のコンフリクトに注意してください",
+		taskID, specialist, strings.Join(details, " / "))
+	log.Printf("[scheduler] %s", message)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeTaskAssigned, "bastion"); err != nil {
+		log.Printf("[scheduler] marshall への通知に失敗: %v", err)
+	}
+}
+
+// タスクを 1 件割り当て、タスクファイルと inbox に書き込む
+// candidates は割り当てた Specialist を対象外にして更新する
+func (o *Orchestrator) dispatchTask(task *communication.Task, candidates []routeCandidate, from string, now time.Time) (*Assignment, error) {
+	var decision *communication.RoutingDecision
+	var branch, worktree string
+	for {
+		var err error
+		decision, err = selectSpecialist(task, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		// worktree をタスク用ブランチに切り替えられない Specialist は候補から外して選び直す
+		branch, worktree, err = o.prepareTaskWorktree(decision.Specialist, task)
+		if err == nil {
+			break
+		}
+		log.Printf("[scheduler] %s の worktree を準備できません: %v", decision.Specialist, err)
+		for i := range candidates {
+			if candidates[i].Info.Name == decision.Specialist {
+				candidates[i].Skipped = "worktree: " + err.Error()
+			}
+		}
+	}
+	decision.DecidedAt = now
+
+	err := o.tasks.Update(task.TaskID, func(t *communication.Task) error {
+		t.SpecialistID = decision.Specialist
+		t.Status = communication.TaskStatusAssigned
+		t.AssignedAt = now
+		t.Routing = decision
+		t.Branch = branch
+		t.Worktree = worktree
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign task %s: %w", task.TaskID, err)
+	}
+
+	for i := range candidates {
+		if candidates[i].Info.Name == decision.Specialist {
+			candidates[i].Skipped = "assigned: " + task.TaskID
+		}
+	}
+
+	assigned, err := o.tasks.ReadByID(task.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("タスク %s を割り当てました: %s を読み、status を in_progress にしてから着手してください",
+		task.TaskID, assigned.Path())
+	if err := o.inbox.Write(decision.Specialist, message, communication.MessageTypeTaskAssigned, "bastion"); err != nil {
+		return nil, fmt.Errorf("failed to notify %s: %w", decision.Specialist, err)
+	}
+
+	if from != "" {
+		log.Printf("[scheduler] %s を %s から %s に付け替えました", task.TaskID, from, decision.Specialist)
+		message := fmt.Sprintf("タスク %s は着手されなかったため %s に付け替えました。作業を始めていた場合は中断してください",
+			task.TaskID, decision.Specialist)
+		if err := o.inbox.Write(from, message, communication.MessageTypeTaskAssigned, "bastion"); err != nil {
+			log.Printf("[scheduler] %s への通知に失敗: %v", from, err)
+		}
+	} else {
+		log.Printf("[scheduler] %s を %s に割り当てました（スコア: %d）", task.TaskID, decision.Specialist, decision.Score)
+	}
+
+	return &Assignment{TaskID: task.TaskID, Specialist: decision.Specialist, StolenFrom: from}, nil
+}
+
+// スケジューラループを実行
+func (o *Orchestrator) runScheduler() {
+	ticker := time.NewTicker(o.config.Scheduler.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case now := <-ticker.C:
+			if _, err := o.Dispatch(now); err != nil {
+				log.Printf("[scheduler] 割り当てに失敗: %v", err)
+			}
+		}
+	}
+}
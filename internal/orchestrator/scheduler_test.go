@@ -0,0 +1,171 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestReadyTasks(t *testing.T) {
+	tasks := []communication.Task{
+		{TaskID: "task_001", Status: communication.TaskStatusCompleted},
+		{TaskID: "task_002", Status: communication.TaskStatusPending, Dependencies: []string{"task_001"}},
+		{TaskID: "task_003", Status: communication.TaskStatusPending, Dependencies: []string{"task_002"}},
+		{TaskID: "task_004", Status: communication.TaskStatusPending, Dependencies: []string{"task_missing"}},
+		{TaskID: "task_005", Status: communication.TaskStatusPending, SpecialistID: "specialist_1"},
+		{TaskID: "task_006", Status: communication.TaskStatusPending},
+		{TaskID: "task_007", Status: communication.TaskStatusInProgress},
+	}
+
+	var ids []string
+	for _, task := range readyTasks(tasks) {
+		ids = append(ids, task.TaskID)
+	}
+
+	// 依存が completed のもの・依存なしのもののみ（割当済み・存在しない依存は除外）
+	if strings.Join(ids, ",") != "task_002,task_006" {
+		t.Errorf("expected task_002 and task_006, got %v", ids)
+	}
+}
+
+func TestStaleAssignments(t *testing.T) {
+	now := time.Now()
+	tasks := []communication.Task{
+		{TaskID: "task_001", Status: communication.TaskStatusAssigned, AssignedAt: now.Add(-10 * time.Minute)},
+		{TaskID: "task_002", Status: communication.TaskStatusAssigned, AssignedAt: now.Add(-1 * time.Minute)},
+		{TaskID: "task_003", Status: communication.TaskStatusInProgress, AssignedAt: now.Add(-10 * time.Minute)},
+		// Marshall が手動で割り当てたタスク（割当時刻なし）は対象外
+		{TaskID: "task_004", Status: communication.TaskStatusAssigned},
+	}
+
+	stale := staleAssignments(tasks, now, 5*time.Minute)
+	if len(stale) != 1 || stale[0].TaskID != "task_001" {
+		t.Errorf("expected only task_001 to be stale, got %+v", stale)
+	}
+}
+
+func TestDispatchTask(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	now := time.Now()
+
+	task := &communication.Task{TaskID: "task_001", Objective: "脆弱性の監査", Status: communication.TaskStatusPending}
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	candidates := []routeCandidate{
+		{Info: AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1}},
+		{Info: AgentInfo{Name: "security-auditor", Type: AgentSpecialist, Index: 2, Spec: securitySpec}},
+	}
+
+	assignment, err := o.dispatchTask(task, candidates, "", now)
+	if err != nil {
+		t.Fatalf("dispatchTask failed: %v", err)
+	}
+	if assignment.Specialist != "security-auditor" {
+		t.Errorf("expected security-auditor, got %s", assignment.Specialist)
+	}
+
+	// タスクファイルに割り当てと判定理由が記録される
+	got, err := o.tasks.ReadByID("task_001")
+	if err != nil {
+		t.Fatalf("failed to read task: %v", err)
+	}
+	if got.SpecialistID != "security-auditor" || got.Status != communication.TaskStatusAssigned {
+		t.Errorf("task was not assigned: %+v", got)
+	}
+	if !got.AssignedAt.Equal(now) || got.Routing == nil || got.Routing.Score == 0 {
+		t.Errorf("assignment details were not recorded: %+v", got)
+	}
+
+	// 担当の inbox に通知される
+	messages, err := o.inbox.Read("security-auditor")
+	if err != nil {
+		t.Fatalf("failed to read inbox: %v", err)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "task_001") {
+		t.Errorf("expected task assignment message, got %+v", messages)
+	}
+
+	// 割り当てた Specialist は以降の候補から外れる
+	if candidates[1].Skipped == "" {
+		t.Error("assigned specialist should be skipped for later tasks")
+	}
+}
+
+func TestDispatchTask_Steal(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	now := time.Now()
+
+	task := &communication.Task{
+		TaskID:       "task_001",
+		SpecialistID: "specialist_1",
+		Status:       communication.TaskStatusAssigned,
+		AssignedAt:   now.Add(-10 * time.Minute),
+	}
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	candidates := []routeCandidate{
+		{Info: AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1}, Skipped: "stale: task_001"},
+		{Info: AgentInfo{Name: "specialist_2", Type: AgentSpecialist, Index: 2}},
+	}
+
+	assignment, err := o.dispatchTask(task, candidates, "specialist_1", now)
+	if err != nil {
+		t.Fatalf("dispatchTask failed: %v", err)
+	}
+	if assignment.Specialist != "specialist_2" || assignment.StolenFrom != "specialist_1" {
+		t.Errorf("unexpected assignment: %+v", assignment)
+	}
+
+	// 付け替え元にも通知される
+	messages, err := o.inbox.Read("specialist_1")
+	if err != nil {
+		t.Fatalf("failed to read inbox: %v", err)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "specialist_2") {
+		t.Errorf("expected reassignment message, got %+v", messages)
+	}
+}
+
+func TestDispatch_StaleWithoutIdleSpecialist(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	registerWorktreeSpecialist(t, o, 2)
+	now := time.Now()
+
+	// 存在しないペインにして、どの Specialist も idle と判定されないようにする
+	specialists, _ := o.Specialists()
+	for _, info := range specialists {
+		info.Target = "%999999"
+		if err := o.registry.Register(info); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	task := &communication.Task{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusAssigned, AssignedAt: now.Add(-time.Hour)}
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.CheckoutTask(sp1, "task_001"); err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+
+	assignments, err := o.Dispatch(now)
+	if err != nil || len(assignments) != 0 {
+		t.Fatalf("nothing should be reassigned: %+v (%v)", assignments, err)
+	}
+
+	// 付け替え先がいなければ元の担当の worktree はタスクのブランチのまま
+	if branch, err := o.worktrees.CurrentBranch(parallel.WorktreeName(1)); err != nil || branch != parallel.TaskBranch("task_001") {
+		t.Errorf("worktree should stay on the task branch: %s (%v)", branch, err)
+	}
+	if got, _ := o.tasks.ReadByID("task_001"); got.SpecialistID != sp1 || got.Status != communication.TaskStatusAssigned {
+		t.Errorf("task should stay with %s: %+v", sp1, got)
+	}
+}
@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 実行中のタスクのリースを再構築して取得
+func (o *Orchestrator) Leases() ([]Lease, error) {
+	tasks, err := o.tasks.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+	return o.leases.Sync(tasks, time.Now())
+}
+
+// リースを使用するか
+func (o *Orchestrator) useLeases() bool {
+	return o.config.Leases.Mode != config.LeaseModeOff
+}
+
+// タスクで実際に変更したファイルを変更予定パスと照合し、結果をタスクに記録
+// タスクのブランチがあればその差分を、なければレポートの成果物を変更ファイルとして扱う
+func (o *Orchestrator) CheckScope(taskID string) (*communication.ScopeCheck, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := taskPaths(*task)
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("task %s declares no paths", taskID)
+	}
+
+	check := &communication.ScopeCheck{CheckedAt: time.Now()}
+
+	branch := task.Branch
+	if branch == "" {
+		branch = parallel.TaskBranch(task.TaskID)
+	}
+	if o.worktrees.IsRepository() && o.worktrees.BranchExists(branch) {
+		changed, err := o.worktrees.ChangedFiles(branch)
+		if err != nil {
+			return nil, err
+		}
+		check.Changed = changed
+		check.Source = "diff"
+	} else {
+		report, err := o.reports.ReadByTaskID(taskID)
+		if err != nil {
+			return nil, fmt.Errorf("no branch or report to check for %s: %w", taskID, err)
+		}
+		check.Changed = pathLike(report.Deliverables)
+		check.Source = "report"
+	}
+
+	for _, file := range check.Changed {
+		if !coveredBy(file, patterns) {
+			check.Undeclared = append(check.Undeclared, file)
+		}
+	}
+
+	err = o.tasks.Update(taskID, func(t *communication.Task) error {
+		t.Scope = check
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// ファイルがいずれかの変更予定パスに含まれるか
+func coveredBy(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if coversPath(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// タスクで変更したファイルを変更予定パスと照合し、変更予定パス外の変更があれば Marshall に通知
+func (o *Orchestrator) checkReportScope(report communication.Report) error {
+	task, err := o.tasks.ReadByID(report.TaskID)
+	if err != nil {
+		return err
+	}
+	if len(taskPaths(*task)) == 0 {
+		return nil
+	}
+
+	// 照合済みのレポートは無視する（watcher の再起動時）
+	if task.Scope != nil && (report.Timestamp.IsZero() || !task.Scope.CheckedAt.Before(report.Timestamp)) {
+		return nil
+	}
+
+	check, err := o.CheckScope(report.TaskID)
+	if err != nil {
+		return err
+	}
+	if len(check.Undeclared) == 0 {
+		log.Printf("[scope] %s の変更はすべて変更予定パスに含まれます", report.TaskID)
+		return nil
+	}
+
+	message := fmt.Sprintf("%s で変更予定パス（paths）外のファイルが変更されています: %s。他のタスクとのコンフリクトに注意してください",
+		report.TaskID, strings.Join(check.Undeclared, ", "))
+	return o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion")
+}
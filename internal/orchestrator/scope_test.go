@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+func TestCheckScope_Diff(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+
+	task := &communication.Task{TaskID: "task_001", Paths: []string{"auth"}}
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	completeTaskOnBranch(t, o, sp1, task, "README.md", "changed\n")
+
+	check, err := o.CheckScope("task_001")
+	if err != nil {
+		t.Fatalf("CheckScope failed: %v", err)
+	}
+	if check.Source != "diff" || strings.Join(check.Changed, ",") != "README.md" || strings.Join(check.Undeclared, ",") != "README.md" {
+		t.Errorf("unexpected scope check: %+v", check)
+	}
+
+	// 結果はタスクに記録される
+	got, _ := o.tasks.ReadByID("task_001")
+	if got.Scope == nil || len(got.Scope.Undeclared) != 1 {
+		t.Errorf("scope check should be recorded: %+v", got.Scope)
+	}
+}
+
+func TestCheckScope_Report(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_002", Status: communication.TaskStatusCompleted}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.CheckScope("task_002"); err == nil {
+		t.Error("expected error for task without paths")
+	}
+
+	if err := o.tasks.Update("task_002", func(t *communication.Task) error {
+		t.Paths = []string{"internal/auth", "docs/*.md"}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	report := &communication.Report{
+		TaskID:       "task_002",
+		SpecialistID: "specialist_1",
+		Status:       communication.TaskStatusCompleted,
+		Deliverables: []string{"internal/auth/login.go", "docs/auth.md", "internal/billing/invoice.go", "ユニットテスト"},
+		Timestamp:    time.Now(),
+	}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+
+	// ブランチがなければレポートの成果物と照合する
+	check, err := o.CheckScope("task_002")
+	if err != nil {
+		t.Fatalf("CheckScope failed: %v", err)
+	}
+	if check.Source != "report" || strings.Join(check.Undeclared, ",") != "internal/billing/invoice.go" {
+		t.Errorf("unexpected scope check: %+v", check)
+	}
+}
+
+func TestHandleReportChange(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", Paths: []string{"internal/auth"}}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	report := &communication.Report{
+		TaskID:       "task_001",
+		SpecialistID: "specialist_1",
+		Deliverables: []string{"internal/billing/invoice.go"},
+		Timestamp:    time.Now().Add(-time.Minute),
+	}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+
+	path := filepath.Join(o.reports.Dir(), "specialist_1_report.yaml")
+	if !o.isReportFile(path) {
+		t.Fatalf("%s should be a report file", path)
+	}
+
+	// 同じレポートの書き込みイベントが複数届いても通知は 1 回
+	for i := 0; i < 2; i++ {
+		if err := o.handleReportChange(path); err != nil {
+			t.Fatalf("handleReportChange failed: %v", err)
+		}
+	}
+
+	messages, err := o.inbox.Read(AgentMarshall)
+	if err != nil {
+		t.Fatalf("failed to read inbox: %v", err)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "internal/billing/invoice.go") {
+		t.Errorf("expected one undeclared change notification, got %+v", messages)
+	}
+}
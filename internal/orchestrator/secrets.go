@@ -0,0 +1,178 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 秘密情報の検出器（検出が無効なら nil）
+func (o *Orchestrator) SecretScanner() (*analysis.SecretScanner, error) {
+	if !o.config.Secrets.Enabled {
+		return nil, nil
+	}
+	return analysis.NewSecretScanner(o.config.Secrets.Allowlist, o.config.Secrets.EntropyThreshold)
+}
+
+// テキスト中の秘密情報を伏せる（検出が無効ならそのまま）
+func (o *Orchestrator) redactSecrets(text string) string {
+	scanner, err := o.SecretScanner()
+	if err != nil || scanner == nil {
+		return text
+	}
+	return scanner.Redact(text)
+}
+
+// 検出しないファイルか（secrets.allow_paths）
+func (o *Orchestrator) secretPathAllowed(file string) bool {
+	for _, pattern := range o.config.Secrets.AllowPaths {
+		if coversPath(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// ブランチのコミットで追加された行の秘密情報
+// 後のコミットで削除しても履歴には残るため、最終的な差分ではなくコミットごとの差分を検査する
+func (o *Orchestrator) branchSecrets(branch string) ([]analysis.SecretFinding, error) {
+	scanner, err := o.SecretScanner()
+	if err != nil || scanner == nil {
+		return nil, err
+	}
+
+	diffs, err := o.worktrees.CommitDiffs(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []analysis.SecretFinding
+	seen := make(map[string]bool)
+	for _, f := range scanner.ScanDiff(diffs) {
+		if o.secretPathAllowed(f.File) || seen[f.String()] {
+			continue
+		}
+		seen[f.String()] = true
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// タスクのブランチのコミットで追加された秘密情報
+func (o *Orchestrator) ScanSecrets(taskID string) ([]analysis.SecretFinding, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !o.worktrees.IsRepository() {
+		return nil, fmt.Errorf("project root is not a git repository: %s", o.projectRoot)
+	}
+
+	branch := task.Branch
+	if branch == "" {
+		branch = parallel.TaskBranch(task.TaskID)
+	}
+	if !o.worktrees.BranchExists(branch) {
+		return nil, fmt.Errorf("branch not found: %s", branch)
+	}
+	return o.branchSecrets(branch)
+}
+
+// 検出結果の一覧（"<規則> <ファイル>:<行> (<伏せた値>)" を ", " でつなぐ）
+func formatSecretFindings(findings []analysis.SecretFinding) string {
+	lines := make([]string, 0, len(findings))
+	for _, f := range findings {
+		lines = append(lines, f.String())
+	}
+	return strings.Join(lines, ", ")
+}
+
+// 秘密情報を含むブランチはマージしない
+// 検出結果が前回と変わったときだけタスクの履歴に記録して Marshall に通知する
+func (o *Orchestrator) gateSecrets(task communication.Task, branch string) ([]analysis.SecretFinding, error) {
+	findings, err := o.branchSecrets(branch)
+	if err != nil || len(findings) == 0 {
+		return nil, err
+	}
+
+	detail := formatSecretFindings(findings)
+	for i := len(task.History) - 1; i >= 0; i-- {
+		if task.History[i].Event == communication.TaskEventSecretsDetected {
+			if task.History[i].Detail == detail {
+				return findings, nil
+			}
+			break
+		}
+	}
+
+	if err := o.recordTaskEvent(task.TaskID, time.Now(), communication.TaskEventSecretsDetected, detail, nil); err != nil {
+		log.Printf("warning: failed to record secrets of %s: %v", task.TaskID, err)
+	}
+	log.Printf("[secrets] %s のブランチ %s に秘密情報を検出したためマージしません: %s", task.TaskID, branch, detail)
+
+	message := fmt.Sprintf("%s のブランチ %s に秘密情報が含まれているためマージを止めています: %s。担当にブランチから取り除かせ、漏れた値は無効化してください",
+		task.TaskID, branch, detail)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeSecretsDetected, "bastion"); err != nil {
+		log.Printf("[secrets] marshall への通知に失敗: %v", err)
+	}
+	return findings, nil
+}
+
+// レポートの本文の秘密情報を伏せて書き直し、Marshall に通知
+// 返り値: 書き直したか
+func (o *Orchestrator) redactReport(report *communication.Report) (bool, error) {
+	scanner, err := o.SecretScanner()
+	if err != nil || scanner == nil {
+		return false, err
+	}
+
+	var findings []analysis.SecretFinding
+	findings = append(findings, scanner.ScanText("summary", report.Summary)...)
+	report.Summary = scanner.Redact(report.Summary)
+	for i := range report.Issues {
+		findings = append(findings, scanner.ScanText(fmt.Sprintf("issues[%d]", i), report.Issues[i])...)
+		report.Issues[i] = scanner.Redact(report.Issues[i])
+	}
+	for i := range report.Deliverables {
+		findings = append(findings, scanner.ScanText(fmt.Sprintf("deliverables[%d]", i), report.Deliverables[i])...)
+		report.Deliverables[i] = scanner.Redact(report.Deliverables[i])
+	}
+	if len(findings) == 0 {
+		return false, nil
+	}
+
+	if err := o.writeReport(report); err != nil {
+		return false, err
+	}
+
+	detail := formatSecretFindings(findings)
+	log.Printf("[secrets] %s のレポートの秘密情報を伏せました: %s", report.TaskID, detail)
+	message := fmt.Sprintf("%s のレポート（%s）に秘密情報が含まれていたため伏せました: %s。漏れた値は無効化してください",
+		report.TaskID, report.SpecialistID, detail)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeSecretsDetected, "bastion"); err != nil {
+		log.Printf("[secrets] marshall への通知に失敗: %v", err)
+	}
+	return true, nil
+}
+
+// inbox のメッセージの秘密情報を伏せる
+func (o *Orchestrator) redactInbox(agent string) error {
+	scanner, err := o.SecretScanner()
+	if err != nil || scanner == nil {
+		return err
+	}
+
+	changed, err := o.inbox.RewriteMessages(agent, scanner.Redact)
+	if err != nil || changed == 0 {
+		return err
+	}
+
+	log.Printf("[secrets] %s の inbox の %d 件のメッセージの秘密情報を伏せました", agent, changed)
+	message := fmt.Sprintf("%s の inbox のメッセージ %d 件に秘密情報が含まれていたため伏せました。漏れた値は無効化してください", agent, changed)
+	return o.inbox.Write(AgentMarshall, message, communication.MessageTypeSecretsDetected, "bastion")
+}
@@ -0,0 +1,223 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// 登録済みの Specialist 一覧を取得
+func (o *Orchestrator) Specialists() ([]AgentInfo, error) {
+	agents, err := o.registry.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var specialists []AgentInfo
+	for _, info := range agents {
+		if info.Type == AgentSpecialist {
+			specialists = append(specialists, info)
+		}
+	}
+	return specialists, nil
+}
+
+// 実行中のセッションに Specialist を追加
+// spec を指定すると外部 Specialist（ペルソナ付き）として起動する
+func (o *Orchestrator) AddSpecialist(spec *SpecialistConfig) (*AgentInfo, error) {
+	specialists, err := o.Specialists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list specialists: %w", err)
+	}
+
+	if spec != nil {
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		for _, info := range specialists {
+			if info.Name == spec.Name {
+				return nil, fmt.Errorf("specialist already exists: %s", spec.Name)
+			}
+		}
+	}
+
+	// 欠番は再利用せず、最大の番号の次を使う（inbox やタスクの取り違えを防ぐ）
+	index := 1
+	for _, info := range specialists {
+		if info.Index >= index {
+			index = info.Index + 1
+		}
+	}
+
+	// specialists ウィンドウを分割、空きがなければ新しいウィンドウを作成
+	paneID, err := o.sm.SplitPaneWithID(parallel.WindowSpecialists)
+	if err != nil {
+		log.Printf("warning: failed to split specialists window, creating a new window: %v", err)
+		paneID, err = o.sm.CreateWindowWithID(fmt.Sprintf("%s_%d", parallel.WindowSpecialists, index))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pane: %w", err)
+		}
+	} else if err := o.sm.SetTiledLayout(parallel.WindowSpecialists); err != nil {
+		log.Printf("warning: failed to set tiled layout: %v", err)
+	}
+
+	name := agentName(AgentSpecialist, index)
+	if spec != nil {
+		name = spec.Name
+	}
+	// 登録できなかったペインは残さない（再実行時に同じ番号のペインが増え続けるため）
+	abandon := func(err error) (*AgentInfo, error) {
+		if killErr := o.sm.KillPane(paneID); killErr != nil {
+			log.Printf("warning: failed to close pane %s: %v", paneID, killErr)
+		}
+		return nil, err
+	}
+
+	if err := o.inbox.Create(name); err != nil {
+		return abandon(err)
+	}
+
+	if err := o.startAgent(AgentSpecialist, paneID, index, spec); err != nil {
+		return abandon(err)
+	}
+
+	info, ok, err := o.registry.Get(name)
+	if err != nil {
+		return abandon(err)
+	}
+	if !ok {
+		return abandon(fmt.Errorf("specialist was not registered: %s", name))
+	}
+	return info, nil
+}
+
+// .claude/agents の外部 Specialist をすべて起動
+func (o *Orchestrator) startExternalSpecialists() error {
+	specs, err := LoadSpecialists(filepath.Join(o.projectRoot, SpecialistDefinitionsDir))
+	if err != nil {
+		return fmt.Errorf("failed to load specialist definitions: %w", err)
+	}
+
+	for i := range specs {
+		info, err := o.AddSpecialist(&specs[i])
+		if err != nil {
+			return fmt.Errorf("failed to start specialist %s: %w", specs[i].Name, err)
+		}
+		log.Printf("外部 Specialist %s を起動しました (%s)", info.Name, specs[i].Persona)
+	}
+	return nil
+}
+
+// Specialist を削除
+// 担当中のタスクは未割当に戻して Marshall に通知してからペインを閉じる
+// 返り値: Marshall に戻したタスク ID
+func (o *Orchestrator) RemoveSpecialist(name string) ([]string, error) {
+	info, ok, err := o.registry.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || info.Type != AgentSpecialist {
+		return nil, fmt.Errorf("specialist not found: %s", name)
+	}
+
+	drained, err := o.drainTasks(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to drain tasks: %w", err)
+	}
+
+	if len(drained) > 0 {
+		message := fmt.Sprintf("%s を削除したため、担当中のタスクを未割当に戻しました: %s。再割当してください",
+			name, strings.Join(drained, ", "))
+		if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeTaskAssigned, "bastion"); err != nil {
+			return drained, fmt.Errorf("failed to notify marshall: %w", err)
+		}
+	}
+
+	if err := o.sm.KillPane(info.Target); err != nil {
+		return drained, err
+	}
+	if err := o.registry.Unregister(name); err != nil {
+		return drained, err
+	}
+
+	// worktree は未コミットの変更がなければ削除（ブランチは残す）
+	if info.Worktree != "" {
+		if err := o.worktrees.Remove(parallel.WorktreeName(info.Index)); err != nil {
+			log.Printf("warning: worktree of %s was kept: %v", name, err)
+		}
+	}
+
+	// ポート範囲は登録解除で空き枠になる。作業用ディレクトリは削除する
+	if err := o.releaseResources(info.Resources); err != nil {
+		log.Printf("warning: failed to release resources of %s: %v", name, err)
+	}
+
+	// 残りのペインを並べ直す（ウィンドウごと消えた場合は失敗してよい）
+	_ = o.sm.SetTiledLayout(parallel.WindowSpecialists)
+
+	return drained, nil
+}
+
+// Specialist が担当中のタスクを未割当に戻す
+// 戻したタスクのブランチを別の Specialist の worktree で checkout できるよう、先に担当の worktree から外す
+// 外せなければ（未コミットの変更があるなど）タスクを戻さずにエラーを返す
+func (o *Orchestrator) drainTasks(specialist string) ([]string, error) {
+	tasks, err := o.tasks.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var assigned []communication.Task
+	for _, task := range tasks {
+		if task.SpecialistID == specialist && !task.IsFinished() {
+			assigned = append(assigned, task)
+		}
+	}
+	for _, task := range assigned {
+		if err := o.releaseBranch(specialist, taskBranchName(task)); err != nil {
+			return nil, fmt.Errorf("failed to release %s from the worktree of %s: %w", task.TaskID, specialist, err)
+		}
+	}
+
+	var drained []string
+	for _, task := range assigned {
+		err := o.tasks.Update(task.TaskID, func(t *communication.Task) error {
+			t.SpecialistID = ""
+			t.Status = communication.TaskStatusPending
+			t.AssignedAt = time.Time{}
+			t.StartedAt = time.Time{}
+			// 次の担当の worktree で改めて準備する（コンフリクト解消タスクは元のタスクのブランチで作業するため残す）
+			t.Worktree = ""
+			if t.ResolvesConflictOf == "" {
+				t.Branch = ""
+			}
+			return nil
+		})
+		if err != nil {
+			return drained, err
+		}
+		drained = append(drained, task.TaskID)
+	}
+	return drained, nil
+}
+
+// Specialist が担当中のタスク ID を取得
+func (o *Orchestrator) AssignedTasks(specialist string) ([]string, error) {
+	tasks, err := o.tasks.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, task := range tasks {
+		if task.SpecialistID == specialist && !task.IsFinished() {
+			ids = append(ids, task.TaskID)
+		}
+	}
+	return ids, nil
+}
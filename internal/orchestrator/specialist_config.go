@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 外部 Specialist 定義の配置ディレクトリ（プロジェクトルートからの相対パス）
+const SpecialistDefinitionsDir = ".claude/agents"
+
+// 外部 Specialist の定義
+// 例: .claude/agents/specialist-security.yaml
+type SpecialistConfig struct {
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description"`
+	Persona      string   `yaml:"persona"`
+	Capabilities []string `yaml:"capabilities"`
+	Triggers     []string `yaml:"trigger_patterns"`
+}
+
+// Specialist 名の形式（kebab-case。specialist_N と衝突しない）
+var specialistNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// 外部 Specialist に使用できない名前
+var reservedAgentNames = map[string]bool{
+	AgentEnvoy:      true,
+	AgentMarshall:   true,
+	AgentSpecialist: true,
+	"watcher":       true,
+	"bastion":       true,
+}
+
+// 定義を検証
+func (c *SpecialistConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !specialistNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("invalid name %q: must be kebab-case (e.g. security-auditor)", c.Name)
+	}
+	if reservedAgentNames[c.Name] {
+		return fmt.Errorf("name %q is reserved", c.Name)
+	}
+	if strings.TrimSpace(c.Persona) == "" {
+		return fmt.Errorf("persona is required: %s", c.Name)
+	}
+	return nil
+}
+
+// claude の --append-system-prompt に渡すプロンプト
+func (c *SpecialistConfig) SystemPrompt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "あなたは Bastion の Specialist「%s」です。%s として振る舞ってください。", c.Name, c.Persona)
+	if c.Description != "" {
+		fmt.Fprintf(&b, "役割: %s。", c.Description)
+	}
+	if len(c.Capabilities) > 0 {
+		fmt.Fprintf(&b, "専門領域: %s。", strings.Join(c.Capabilities, "、"))
+	}
+	fmt.Fprintf(&b, "あなた宛てのメッセージは agents/queue/inbox/%s.yaml に届きます。", c.Name)
+	return b.String()
+}
+
+// 外部 Specialist 定義を 1 ファイル読み込む
+func LoadSpecialistFile(path string) (*SpecialistConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read specialist definition: %w", err)
+	}
+
+	var cfg SpecialistConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal specialist definition %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid specialist definition %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// ディレクトリ内の外部 Specialist 定義（*.yaml, *.yml）をすべて読み込む
+// ディレクトリが存在しない場合は空を返す
+func LoadSpecialists(dir string) ([]SpecialistConfig, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read specialist definitions: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext == ".yaml" || ext == ".yml" {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	var configs []SpecialistConfig
+	seen := make(map[string]string)
+	for _, path := range paths {
+		cfg, err := LoadSpecialistFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[cfg.Name]; ok {
+			return nil, fmt.Errorf("duplicate specialist name %q in %s and %s", cfg.Name, other, path)
+		}
+		seen[cfg.Name] = path
+		configs = append(configs, *cfg)
+	}
+	return configs, nil
+}
+
+// シェルのシングルクォートで囲む
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,167 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSpecialistFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write specialist definition: %v", err)
+	}
+	return path
+}
+
+func TestLoadSpecialists(t *testing.T) {
+	dir := t.TempDir()
+
+	writeSpecialistFile(t, dir, "specialist-security.yaml", `name: security-auditor
+description: "セキュリティ監査専門"
+persona: "Senior Security Engineer"
+capabilities:
+  - "OWASP Top 10 チェック"
+trigger_patterns:
+  - "セキュリティ"
+  - "脆弱性"
+`)
+	writeSpecialistFile(t, dir, "docs.yml", "name: doc-writer\npersona: Technical Writer\n")
+	// YAML 以外は無視
+	writeSpecialistFile(t, dir, "README.md", "# agents\n")
+
+	specs, err := LoadSpecialists(dir)
+	if err != nil {
+		t.Fatalf("LoadSpecialists failed: %v", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 specialists, got %d", len(specs))
+	}
+
+	// ファイル名順
+	if specs[0].Name != "doc-writer" || specs[1].Name != "security-auditor" {
+		t.Errorf("unexpected order: %s, %s", specs[0].Name, specs[1].Name)
+	}
+	if len(specs[1].Triggers) != 2 || specs[1].Triggers[1] != "脆弱性" {
+		t.Errorf("trigger_patterns not loaded: %v", specs[1].Triggers)
+	}
+}
+
+func TestLoadSpecialists_MissingDir(t *testing.T) {
+	specs, err := LoadSpecialists(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("missing directory should not be an error: %v", err)
+	}
+	if len(specs) != 0 {
+		t.Errorf("expected no specialists, got %d", len(specs))
+	}
+}
+
+func TestLoadSpecialists_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "name がない",
+			files:   map[string]string{"a.yaml": "persona: Engineer\n"},
+			wantErr: "name is required",
+		},
+		{
+			name:    "kebab-case でない",
+			files:   map[string]string{"a.yaml": "name: Security_Auditor\npersona: Engineer\n"},
+			wantErr: "kebab-case",
+		},
+		{
+			name:    "予約名",
+			files:   map[string]string{"a.yaml": "name: marshall\npersona: Engineer\n"},
+			wantErr: "reserved",
+		},
+		{
+			name:    "persona がない",
+			files:   map[string]string{"a.yaml": "name: auditor\n"},
+			wantErr: "persona is required",
+		},
+		{
+			name: "名前の重複",
+			files: map[string]string{
+				"a.yaml": "name: auditor\npersona: Engineer\n",
+				"b.yaml": "name: auditor\npersona: Engineer\n",
+			},
+			wantErr: "duplicate",
+		},
+		{
+			name:    "不正な YAML",
+			files:   map[string]string{"a.yaml": "name: [\n"},
+			wantErr: "unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeSpecialistFile(t, dir, name, content)
+			}
+
+			_, err := LoadSpecialists(dir)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSpecialistConfig_SystemPrompt(t *testing.T) {
+	spec := SpecialistConfig{
+		Name:         "security-auditor",
+		Description:  "セキュリティ監査専門",
+		Persona:      "Senior Security Engineer",
+		Capabilities: []string{"OWASP Top 10 チェック", "認証・認可レビュー"},
+	}
+
+	prompt := spec.SystemPrompt()
+	for _, want := range []string{"security-auditor", "Senior Security Engineer", "OWASP Top 10 チェック", "inbox/security-auditor.yaml"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt should contain %q: %s", want, prompt)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	input := "It's a \"persona\" with $HOME and `backticks`"
+
+	output, err := exec.Command("sh", "-c", "printf %s "+shellQuote(input)).Output()
+	if err != nil {
+		t.Fatalf("failed to run sh: %v", err)
+	}
+	if string(output) != input {
+		t.Errorf("expected %q, got %q", input, string(output))
+	}
+}
+
+func TestAddSpecialist_Duplicate(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	spec := &SpecialistConfig{Name: "security-auditor", Persona: "Senior Security Engineer"}
+	if err := o.registry.Register(AgentInfo{Name: spec.Name, Type: AgentSpecialist, Index: 1, Spec: spec}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if _, err := o.AddSpecialist(spec); err == nil {
+		t.Error("expected error for duplicate specialist")
+	}
+
+	// 登録内容からペルソナを参照できる
+	agents := o.agents()
+	if len(agents) != 1 || agents[0].Persona != "Senior Security Engineer" {
+		t.Errorf("expected persona to be loaded from registry, got %+v", agents)
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+func TestDrainTasks(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	for _, task := range []*communication.Task{
+		{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusInProgress,
+			Branch: "bastion/task/task_001", Worktree: "/tmp/sp1"},
+		{TaskID: "task_002", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted},
+		{TaskID: "task_003", SpecialistID: "specialist_2", Status: communication.TaskStatusPending},
+		{TaskID: "task_004", SpecialistID: "specialist_1", Status: communication.TaskStatusPending},
+	} {
+		if err := o.tasks.Write(task); err != nil {
+			t.Fatalf("failed to write task: %v", err)
+		}
+	}
+
+	drained, err := o.drainTasks("specialist_1")
+	if err != nil {
+		t.Fatalf("drainTasks failed: %v", err)
+	}
+	if strings.Join(drained, ",") != "task_001,task_004" {
+		t.Errorf("expected task_001 and task_004 to be drained, got %v", drained)
+	}
+
+	task, err := o.tasks.ReadByID("task_001")
+	if err != nil {
+		t.Fatalf("failed to read task: %v", err)
+	}
+	if task.SpecialistID != "" || task.Status != communication.TaskStatusPending || task.Branch != "" || task.Worktree != "" {
+		t.Errorf("drained task should be unassigned and pending, got %+v", task)
+	}
+
+	// 完了済みタスクは担当者を残す
+	done, err := o.tasks.ReadByID("task_002")
+	if err != nil {
+		t.Fatalf("failed to read task: %v", err)
+	}
+	if done.SpecialistID != "specialist_1" {
+		t.Errorf("completed task should keep its specialist, got %q", done.SpecialistID)
+	}
+
+	assigned, err := o.AssignedTasks("specialist_1")
+	if err != nil {
+		t.Fatalf("AssignedTasks failed: %v", err)
+	}
+	if len(assigned) != 0 {
+		t.Errorf("expected no assigned tasks after drain, got %v", assigned)
+	}
+}
+
+func TestDrainTasks_ReleasesBranch(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+	sp2 := registerWorktreeSpecialist(t, o, 2)
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusAssigned}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.CheckoutTask(sp1, "task_001"); err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+
+	// 削除と同じ手順で戻したタスクは別の Specialist の worktree で checkout できる
+	if _, err := o.drainTasks(sp1); err != nil {
+		t.Fatalf("drainTasks failed: %v", err)
+	}
+	task, _ := o.tasks.ReadByID("task_001")
+	if _, _, err := o.prepareTaskWorktree(sp2, task); err != nil {
+		t.Errorf("task branch should be free for another specialist: %v", err)
+	}
+}
+
+func TestDrainTasks_DirtyWorktree(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	sp1 := registerWorktreeSpecialist(t, o, 1)
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: sp1, Status: communication.TaskStatusInProgress}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if _, err := o.CheckoutTask(sp1, "task_001"); err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+	info, _, _ := o.registry.Get(sp1)
+	if err := os.WriteFile(filepath.Join(info.Worktree, "wip.go"), []byte("package app\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// 未コミットの変更が残る worktree からはブランチを外せないため、タスクを戻さない
+	if _, err := o.drainTasks(sp1); !errors.Is(err, parallel.ErrWorktreeDirty) {
+		t.Fatalf("drainTasks should fail for a dirty worktree, got %v", err)
+	}
+	if task, _ := o.tasks.ReadByID("task_001"); task.SpecialistID != sp1 || task.Status != communication.TaskStatusInProgress {
+		t.Errorf("task should stay with %s: %+v", sp1, task)
+	}
+}
+
+func TestRemoveSpecialist_NotFound(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.registry.Register(AgentInfo{Name: AgentMarshall, Type: AgentMarshall}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if _, err := o.RemoveSpecialist("specialist_9"); err == nil {
+		t.Error("expected error for unknown specialist")
+	}
+	// Specialist 以外は削除できない
+	if _, err := o.RemoveSpecialist(AgentMarshall); err == nil {
+		t.Error("expected error when removing marshall")
+	}
+}
+
+func TestSpecialists(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	for _, info := range []AgentInfo{
+		{Name: AgentEnvoy, Type: AgentEnvoy},
+		{Name: "specialist_2", Type: AgentSpecialist, Index: 2},
+		{Name: "specialist_1", Type: AgentSpecialist, Index: 1},
+	} {
+		if err := o.registry.Register(info); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	specialists, err := o.Specialists()
+	if err != nil {
+		t.Fatalf("Specialists failed: %v", err)
+	}
+	if len(specialists) != 2 || specialists[0].Name != "specialist_1" {
+		t.Errorf("unexpected specialists: %+v", specialists)
+	}
+}
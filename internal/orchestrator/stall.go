@@ -0,0 +1,231 @@
+package orchestrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// タスクごとの停滞検知状態
+type stallState struct {
+	// 前回確認時のペイン出力と worktree の状態
+	fingerprint string
+	// 最後に変化を検知した時刻
+	lastActivity time.Time
+	// 停滞中に実施済みの対応
+	nudged    bool
+	escalated bool
+}
+
+// 停滞・タイムアウトへの対応
+type stallAction int
+
+const (
+	stallActionNone stallAction = iota
+	// 担当 Specialist を nudge
+	stallActionNudge
+	// Marshall にエスカレーション
+	stallActionEscalate
+	// failed（failure: timeout）にする
+	stallActionTimeout
+)
+
+// タスクの制限時間（タスクの timeout > 優先度ごとの設定 > default）
+func taskTimeout(task communication.Task, priority string, cfg config.TimeoutsConfig) time.Duration {
+	if task.Timeout > 0 {
+		return task.Timeout
+	}
+	if timeout, ok := cfg.Priorities[priority]; ok {
+		return timeout
+	}
+	return cfg.Default
+}
+
+// 次に行う対応を決める（制限時間 > エスカレーション > nudge の順に判定）
+func nextStallAction(task communication.Task, st *stallState, now time.Time, timeout time.Duration, cfg config.TimeoutsConfig) stallAction {
+	if !task.StartedAt.IsZero() && now.Sub(task.StartedAt) >= timeout {
+		return stallActionTimeout
+	}
+
+	idle := now.Sub(st.lastActivity)
+	switch {
+	case idle >= cfg.EscalateAfter && !st.escalated:
+		return stallActionEscalate
+	case idle >= cfg.StallAfter && !st.nudged && !st.escalated:
+		return stallActionNudge
+	}
+	return stallActionNone
+}
+
+// タイムアウト・停滞検知ループを実行
+func (o *Orchestrator) runStallMonitor() {
+	ticker := time.NewTicker(o.config.Timeouts.CheckInterval)
+	defer ticker.Stop()
+
+	log.Printf("[stall] タスクの停滞検知を開始しました（間隔: %s）", o.config.Timeouts.CheckInterval)
+	for {
+		select {
+		case <-o.done:
+			return
+		case now := <-ticker.C:
+			o.checkStalls(now)
+		}
+	}
+}
+
+// in_progress タスクの制限時間と停滞を確認し、必要なら対応する
+func (o *Orchestrator) checkStalls(now time.Time) {
+	tasks, err := o.tasks.Read()
+	if err != nil {
+		log.Printf("[stall] タスクの読み込みに失敗: %v", err)
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	active := make(map[string]bool)
+	for _, task := range tasks {
+		if task.Status != communication.TaskStatusInProgress || task.SpecialistID == "" {
+			continue
+		}
+		active[task.TaskID] = true
+
+		// 作業開始を記録（制限時間の起点）
+		if task.StartedAt.IsZero() {
+			task.StartedAt = now
+			if err := o.recordTaskEvent(task.TaskID, now, communication.TaskEventStarted, "", func(t *communication.Task) {
+				t.StartedAt = now
+			}); err != nil {
+				log.Printf("[stall] %s の開始時刻の記録に失敗: %v", task.TaskID, err)
+			}
+		}
+
+		// ペイン出力か worktree が変化していれば作業中とみなす
+		fingerprint := o.activityFingerprint(task)
+		st := o.stalls[task.TaskID]
+		if st == nil || st.fingerprint != fingerprint {
+			o.stalls[task.TaskID] = &stallState{fingerprint: fingerprint, lastActivity: now}
+			st = o.stalls[task.TaskID]
+		}
+
+		timeout := taskTimeout(task, o.taskPriority(task), o.config.Timeouts)
+		switch nextStallAction(task, st, now, timeout, o.config.Timeouts) {
+		case stallActionTimeout:
+			o.timeoutTask(task, timeout, now)
+			delete(o.stalls, task.TaskID)
+		case stallActionEscalate:
+			o.escalateStall(task, now.Sub(st.lastActivity), now)
+			st.escalated = true
+		case stallActionNudge:
+			o.nudgeStall(task, now.Sub(st.lastActivity), now)
+			st.nudged = true
+		}
+	}
+
+	// 終了したタスクの状態を破棄
+	for id := range o.stalls {
+		if !active[id] {
+			delete(o.stalls, id)
+		}
+	}
+}
+
+// タスクの優先度（省略時は指令の優先度）
+func (o *Orchestrator) taskPriority(task communication.Task) string {
+	if task.Priority != "" || task.CommandID == "" {
+		return task.Priority
+	}
+	if cmd, err := o.commands.ReadByID(task.CommandID); err == nil {
+		return cmd.Priority
+	}
+	return ""
+}
+
+// 担当のペイン出力と worktree の状態から活動の指紋を作る
+func (o *Orchestrator) activityFingerprint(task communication.Task) string {
+	h := sha256.New()
+
+	if target, ok := o.agentTarget(task.SpecialistID); ok {
+		if output, err := o.sm.CapturePane(target, o.config.Activity.CaptureLines); err == nil {
+			h.Write([]byte(output))
+		}
+	}
+
+	// worktree があればファイルの変更とコミットも活動として扱う
+	if info, ok, err := o.registry.Get(task.SpecialistID); err == nil && ok && info.Worktree != "" {
+		h.Write([]byte(o.worktrees.Fingerprint(parallel.WorktreeName(info.Index))))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 停滞しているタスクの担当を nudge（inbox に書き込み、watcher が wakeup する）
+func (o *Orchestrator) nudgeStall(task communication.Task, idle time.Duration, now time.Time) {
+	detail := fmt.Sprintf("%s 動きがありません", idle.Truncate(time.Minute))
+	log.Printf("[stall] %s (%s): %s → nudge", task.TaskID, task.SpecialistID, detail)
+
+	message := fmt.Sprintf("タスク %s に %s。作業を続けるか、進められない場合は問題をレポートしてください", task.TaskID, detail)
+	if err := o.inbox.Write(task.SpecialistID, message, communication.MessageTypeWakeUp, "bastion"); err != nil {
+		log.Printf("[stall] %s への nudge に失敗: %v", task.SpecialistID, err)
+	}
+	if err := o.recordTaskEvent(task.TaskID, now, communication.TaskEventNudged, detail, nil); err != nil {
+		log.Printf("[stall] %s の履歴の記録に失敗: %v", task.TaskID, err)
+	}
+}
+
+// 停滞が続くタスクを Marshall にエスカレーション
+func (o *Orchestrator) escalateStall(task communication.Task, idle time.Duration, now time.Time) {
+	detail := fmt.Sprintf("%s 動きがありません", idle.Truncate(time.Minute))
+	log.Printf("[stall] %s (%s): %s → marshall にエスカレーション", task.TaskID, task.SpecialistID, detail)
+
+	message := fmt.Sprintf("%s の担当 %s に %s（nudge 済み）。状況を確認し、必要なら再割当してください", task.TaskID, task.SpecialistID, detail)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeWakeUp, "bastion"); err != nil {
+		log.Printf("[stall] marshall への通知に失敗: %v", err)
+	}
+	if err := o.recordTaskEvent(task.TaskID, now, communication.TaskEventEscalated, detail, nil); err != nil {
+		log.Printf("[stall] %s の履歴の記録に失敗: %v", task.TaskID, err)
+	}
+}
+
+// 制限時間を超えたタスクを failed（failure: timeout）にする
+func (o *Orchestrator) timeoutTask(task communication.Task, timeout time.Duration, now time.Time) {
+	detail := fmt.Sprintf("制限時間 %s を超えました", timeout)
+	log.Printf("[stall] %s (%s): %s → failed", task.TaskID, task.SpecialistID, detail)
+
+	err := o.recordTaskEvent(task.TaskID, now, communication.TaskEventTimedOut, detail, func(t *communication.Task) {
+		t.Status = communication.TaskStatusFailed
+		t.Failure = "timeout"
+	})
+	if err != nil {
+		log.Printf("[stall] %s を失敗にできません: %v", task.TaskID, err)
+		return
+	}
+
+	message := fmt.Sprintf("タスク %s は%sため失敗にしました。作業を中断し、inbox を確認してください", task.TaskID, detail)
+	if err := o.inbox.Write(task.SpecialistID, message, communication.MessageTypeWakeUp, "bastion"); err != nil {
+		log.Printf("[stall] %s への通知に失敗: %v", task.SpecialistID, err)
+	}
+	message = fmt.Sprintf("%s（担当: %s）は%sため failed（failure: timeout）にしました。再割当するか指令を見直してください",
+		task.TaskID, task.SpecialistID, detail)
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeWakeUp, "bastion"); err != nil {
+		log.Printf("[stall] marshall への通知に失敗: %v", err)
+	}
+}
+
+// タスクに履歴を追加して保存（fn でほかの項目も更新できる）
+func (o *Orchestrator) recordTaskEvent(taskID string, at time.Time, event, detail string, fn func(*communication.Task)) error {
+	return o.tasks.Update(taskID, func(t *communication.Task) error {
+		if fn != nil {
+			fn(t)
+		}
+		t.AddEvent(at, event, o.redactSecrets(detail))
+		return nil
+	})
+}
@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/config"
+)
+
+func TestTaskTimeout(t *testing.T) {
+	cfg := config.Default().Timeouts
+
+	if got := taskTimeout(communication.Task{Timeout: 30 * time.Minute}, "high", cfg); got != 30*time.Minute {
+		t.Errorf("task timeout should take precedence, got %s", got)
+	}
+	if got := taskTimeout(communication.Task{}, "high", cfg); got != cfg.Priorities["high"] {
+		t.Errorf("expected priority timeout, got %s", got)
+	}
+	if got := taskTimeout(communication.Task{}, "medium", cfg); got != cfg.Default {
+		t.Errorf("expected default timeout, got %s", got)
+	}
+}
+
+func TestNextStallAction(t *testing.T) {
+	cfg := config.Default().Timeouts
+	start := time.Date(2026, 2, 8, 10, 0, 0, 0, time.UTC)
+	task := communication.Task{StartedAt: start}
+
+	tests := []struct {
+		name string
+		st   stallState
+		now  time.Time
+		want stallAction
+	}{
+		{"active", stallState{lastActivity: start}, start.Add(10 * time.Minute), stallActionNone},
+		{"stalled", stallState{lastActivity: start}, start.Add(15 * time.Minute), stallActionNudge},
+		{"already nudged", stallState{lastActivity: start, nudged: true}, start.Add(20 * time.Minute), stallActionNone},
+		{"still stalled", stallState{lastActivity: start, nudged: true}, start.Add(30 * time.Minute), stallActionEscalate},
+		{"escalated", stallState{lastActivity: start, nudged: true, escalated: true}, start.Add(time.Hour), stallActionNone},
+		{"timed out", stallState{lastActivity: start.Add(4 * time.Hour)}, start.Add(4 * time.Hour), stallActionTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := tt.st
+			if got := nextStallAction(task, &st, tt.now, cfg.Default, cfg); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckStalls(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	start := time.Date(2026, 2, 8, 10, 0, 0, 0, time.UTC)
+
+	task := &communication.Task{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusInProgress, Priority: "high"}
+	if err := o.tasks.Write(task); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	// 作業開始を記録
+	o.checkStalls(start)
+	got, _ := o.tasks.ReadByID("task_001")
+	if !got.StartedAt.Equal(start) || len(got.History) != 1 || got.History[0].Event != communication.TaskEventStarted {
+		t.Fatalf("start should be recorded: %+v", got)
+	}
+
+	// 動きがなければ nudge → エスカレーション
+	o.checkStalls(start.Add(15 * time.Minute))
+	o.checkStalls(start.Add(20 * time.Minute))
+	o.checkStalls(start.Add(30 * time.Minute))
+
+	messages, _ := o.inbox.Read("specialist_1")
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "task_001") {
+		t.Errorf("specialist should be nudged once, got %+v", messages)
+	}
+	messages, _ = o.inbox.Read(AgentMarshall)
+	if len(messages) != 1 || !strings.Contains(messages[0].Message, "specialist_1") {
+		t.Errorf("marshall should be notified once, got %+v", messages)
+	}
+
+	// 優先度 high の制限時間を超えたら失敗にする
+	o.checkStalls(start.Add(2 * time.Hour))
+	got, _ = o.tasks.ReadByID("task_001")
+	if got.Status != communication.TaskStatusFailed || got.Failure != "timeout" {
+		t.Errorf("task should fail with timeout: %+v", got)
+	}
+
+	var events []string
+	for _, e := range got.History {
+		events = append(events, e.Event)
+	}
+	if strings.Join(events, ",") != "started,nudged,escalated,timed_out" {
+		t.Errorf("unexpected history: %v", events)
+	}
+	if len(o.stalls) != 0 {
+		t.Errorf("stall state should be cleared: %v", o.stalls)
+	}
+}
+
+func TestTaskPriority_FromCommand(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+
+	if err := o.commands.Write(communication.Command{ID: "cmd_001", Priority: "low", Status: communication.CommandStatusPending}); err != nil {
+		t.Fatalf("failed to write command: %v", err)
+	}
+
+	if got := o.taskPriority(communication.Task{CommandID: "cmd_001"}); got != "low" {
+		t.Errorf("expected command priority, got %q", got)
+	}
+	if got := o.taskPriority(communication.Task{CommandID: "cmd_001", Priority: "high"}); got != "high" {
+		t.Errorf("task priority should take precedence, got %q", got)
+	}
+}
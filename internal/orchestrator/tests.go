@@ -0,0 +1,165 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/t-ishitsuka/bastion-core/internal/analysis"
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+)
+
+// 通知に含める失敗したテストの数
+const maxNotifiedFailures = 10
+
+// テスト結果を探さないディレクトリ
+var skippedTestDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// テスト結果を取り込むか
+func (o *Orchestrator) useTests() bool {
+	return o.config.Tests.Enabled && len(o.config.Tests.Results) > 0
+}
+
+// タスクの worktree のテスト結果のファイル（worktree からの相対パス）
+// タスクの開始前に書かれたファイルは前回の結果として読まない
+func (o *Orchestrator) testResultFiles(task communication.Task, dir string) ([]string, error) {
+	since := task.StartedAt
+	if since.IsZero() {
+		since = task.AssignedAt
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skippedTestDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		matched := false
+		for _, pattern := range o.config.Tests.Results {
+			if coversPath(pattern, rel) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil
+		}
+
+		if !since.IsZero() {
+			info, err := d.Info()
+			if err != nil || info.ModTime().Before(since) {
+				return nil
+			}
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// タスクの worktree のテスト結果（go test -json / JUnit XML / TAP）を読み込んで集計
+// テスト結果のファイルがなければ nil を返す
+func (o *Orchestrator) CollectTestResults(taskID string) (*analysis.TestResults, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := o.taskWorkdir(*task)
+	if err != nil {
+		return nil, err
+	}
+	files, err := o.testResultFiles(*task, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results *analysis.TestResults
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := analysis.ParseTestResults(file, content)
+		if err != nil {
+			// 形式の分からないファイルは読み飛ばす
+			log.Printf("[tests] %s: %v", taskID, err)
+			continue
+		}
+		if results == nil {
+			results = &analysis.TestResults{}
+		}
+		results.Merge(*parsed)
+	}
+	return results, nil
+}
+
+// レポートにテスト結果を付ける（テスト結果のファイルがなければ何もしない）
+func (o *Orchestrator) attachTestResults(report *communication.Report) bool {
+	if !o.useTests() {
+		return false
+	}
+	results, err := o.CollectTestResults(report.TaskID)
+	if err != nil {
+		log.Printf("[tests] %s のテスト結果の読み込みに失敗: %v", report.TaskID, err)
+		return false
+	}
+	if results == nil {
+		return false
+	}
+	report.Tests = results
+	return true
+}
+
+// 完了報告にテスト結果を記録する（品質ゲートを使わない場合）
+func (o *Orchestrator) recordTestResults(report *communication.Report) error {
+	if report.Status != communication.TaskStatusCompleted || report.Tests != nil || !o.attachTestResults(report) {
+		return nil
+	}
+	if err := o.writeReport(report); err != nil {
+		return err
+	}
+	o.applyTestResults(*report)
+	return nil
+}
+
+// 記録したテスト結果を評価に反映し、失敗したテストがあれば Marshall に通知
+func (o *Orchestrator) applyTestResults(report communication.Report) {
+	tests := report.Tests
+	log.Printf("[tests] %s のテスト結果: %s", report.TaskID, tests)
+
+	if err := o.applyReportToEvaluation(report); err != nil {
+		log.Printf("[tests] %s の評価への反映に失敗: %v", report.TaskID, err)
+	}
+
+	if tests.Failed == 0 {
+		return
+	}
+	failures := tests.Failures
+	if len(failures) > maxNotifiedFailures {
+		failures = append(failures[:maxNotifiedFailures:maxNotifiedFailures], fmt.Sprintf("ほか %d 件", len(tests.Failures)-maxNotifiedFailures))
+	}
+	message := fmt.Sprintf("%s のテストが %d 件失敗しています（%s）: %s", report.TaskID, tests.Failed, tests, strings.Join(failures, ", "))
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeReportReceived, "bastion"); err != nil {
+		log.Printf("[tests] marshall への通知に失敗: %v", err)
+	}
+}
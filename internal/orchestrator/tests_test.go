@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/evaluation"
+)
+
+const testGoJSON = `{"Action":"pass","Package":"example.com/app/auth","Test":"TestLogin","Elapsed":0.5}
+{"Action":"fail","Package":"example.com/app/auth","Test":"TestRefresh","Elapsed":0.5}
+{"Action":"fail","Package":"example.com/app/auth","Elapsed":1}
+`
+
+// worktree にファイルを書き込む
+func writeWorktreeFile(t *testing.T, dir, file, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+	return path
+}
+
+func TestCollectTestResults(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	worktree := t.TempDir()
+	started := time.Now().Add(-time.Minute)
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", Status: communication.TaskStatusCompleted, Worktree: worktree, StartedAt: started}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	writeWorktreeFile(t, worktree, "test-results/go.json", testGoJSON)
+	writeWorktreeFile(t, worktree, "web/junit.xml", `<testsuite><testcase name="renders"/><testcase name="submits"><skipped/></testcase></testsuite>`)
+	writeWorktreeFile(t, worktree, "test-results/notes.txt", "all green")
+	writeWorktreeFile(t, worktree, "node_modules/pkg/junit.xml", `<testsuite><testcase name="vendored"/></testsuite>`)
+	// 前回の実行で残ったファイルは読まない
+	stale := writeWorktreeFile(t, worktree, "e2e.tap", "1..1\nnot ok 1 - old failure\n")
+	if err := os.Chtimes(stale, started.Add(-time.Hour), started.Add(-time.Hour)); err != nil {
+		t.Fatalf("failed to change mtime: %v", err)
+	}
+
+	results, err := o.CollectTestResults("task_001")
+	if err != nil {
+		t.Fatalf("CollectTestResults failed: %v", err)
+	}
+	if results.Passed != 2 || results.Failed != 1 || results.Skipped != 1 || results.Duration != time.Second || results.Format != "go-json,junit" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if !reflect.DeepEqual(results.Sources, []string{"test-results/go.json", "web/junit.xml"}) {
+		t.Errorf("unexpected sources: %v", results.Sources)
+	}
+
+	o.config.Tests.Results = []string{"reports/*.xml"}
+	if results, err := o.CollectTestResults("task_001"); err != nil || results != nil {
+		t.Errorf("expected no results without matching files: %+v (%v)", results, err)
+	}
+}
+
+func TestHandleReportChange_RecordsTestResults(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	worktree := t.TempDir()
+	writeWorktreeFile(t, worktree, "test-results/go.json", testGoJSON)
+
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted, Worktree: worktree}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	if err := o.evaluations.Write(&evaluation.Evaluation{TaskID: "task_001", Evaluator: "marshall", Scores: evaluation.Scores{Correctness: 5, CodeQuality: 4}}); err != nil {
+		t.Fatalf("failed to write evaluation: %v", err)
+	}
+	report := &communication.Report{TaskID: "task_001", SpecialistID: "specialist_1", Status: communication.TaskStatusCompleted, Summary: "テストはすべてパスしました", Timestamp: time.Now()}
+	if err := o.reports.Write(report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	path := filepath.Join(o.reports.Dir(), "specialist_1_report.yaml")
+
+	// 書き込みイベントが複数届いても記録と通知は 1 回
+	for i := 0; i < 2; i++ {
+		if err := o.handleReportChange(path); err != nil {
+			t.Fatalf("handleReportChange failed: %v", err)
+		}
+	}
+
+	got, _ := o.reports.ReadFile(path)
+	if got.Tests == nil || got.Tests.Passed != 1 || got.Tests.Failed != 1 || got.Tests.Failures[0] != "example.com/app/auth.TestRefresh" {
+		t.Fatalf("test results should be recorded: %+v", got.Tests)
+	}
+	if n := countMarshallMessages(t, o, "example.com/app/auth.TestRefresh"); n != 1 {
+		t.Errorf("marshall should be notified once, got %d", n)
+	}
+	eval, _ := o.evaluations.Read("task_001")
+	if eval.Scores.Correctness != 3 || eval.Scores.CodeQuality != 4 || eval.Tests == nil {
+		t.Errorf("evaluation should be capped by failing tests: %+v", eval)
+	}
+
+	// Marshall が評価を書き直しても上限を反映する
+	eval.Scores.Correctness = 5
+	if err := o.evaluations.Write(eval); err != nil {
+		t.Fatalf("failed to write evaluation: %v", err)
+	}
+	if err := o.handleEvaluationChange(o.evaluations.Path("task_001")); err != nil {
+		t.Fatalf("handleEvaluationChange failed: %v", err)
+	}
+	if eval, _ := o.evaluations.Read("task_001"); eval.Scores.Correctness != 3 || len(eval.Adjustments) != 2 {
+		t.Errorf("rewritten evaluation should be capped again: %+v", eval)
+	}
+}
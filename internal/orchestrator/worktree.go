@@ -0,0 +1,367 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// worktree の状態
+type WorktreeStatus struct {
+	parallel.Worktree
+	// 使用中の Specialist（未使用なら空）
+	Specialist string
+	// 未コミットの変更がある
+	Dirty bool
+}
+
+// Specialist を worktree で起動するか
+func (o *Orchestrator) useWorktrees() bool {
+	return o.config.Worktree.Enabled && o.worktrees.IsRepository()
+}
+
+// worktree 起動時に追加するシステムプロンプト
+// worktree には agents/queue がないため、キューの場所を明示する
+func worktreePrompt(worktree, queueDir string) string {
+	return fmt.Sprintf("作業ディレクトリは git worktree %s です。コードの変更はこの worktree で行い、タスクごとのブランチにコミットしてください。"+
+		"inbox・タスク・レポートは %s を参照してください。", worktree, queueDir)
+}
+
+// Specialist の worktree を用意（.worktrees/sp<N>、ブランチ bastion/sp<N>）
+// 新しく作成した場合は初期化し、失敗したら Marshall に報告する（Specialist はそのまま起動する）
+func (o *Orchestrator) prepareSpecialistWorktree(index int, spec *SpecialistConfig) (string, error) {
+	base, err := o.worktrees.BaseCommit()
+	if err != nil {
+		return "", err
+	}
+
+	name := parallel.WorktreeName(index)
+	created := !o.worktrees.Exists(name)
+
+	path, err := o.worktrees.EnsureSparse(name, parallel.BranchPrefix+name, base, o.sparsePaths(spec))
+	if err != nil {
+		return "", err
+	}
+
+	if created {
+		specialist := agentName(AgentSpecialist, index)
+		if spec != nil {
+			specialist = spec.Name
+		}
+		o.bootstrapWorktree(specialist, name)
+	}
+	return path, nil
+}
+
+// Specialist の種類に応じた sparse-checkout 対象（指定がなければ nil）
+func (o *Orchestrator) sparsePaths(spec *SpecialistConfig) []string {
+	key := AgentSpecialist
+	if spec != nil {
+		key = spec.Name
+	}
+	return o.config.Worktree.SparseCheckout[key]
+}
+
+// 新しい worktree を初期化し、失敗したら Marshall の inbox に報告
+func (o *Orchestrator) bootstrapWorktree(specialist, name string) {
+	w := o.config.Worktree
+	if len(w.CopyFiles) == 0 && len(w.Bootstrap) == 0 {
+		return
+	}
+
+	log.Printf("%s の worktree %s を初期化しています...", specialist, name)
+	err := o.worktrees.Bootstrap(name, parallel.BootstrapOptions{
+		CopyFiles: w.CopyFiles,
+		Commands:  w.Bootstrap,
+		CacheDir:  w.CacheDir,
+		Env:       w.Env,
+		Timeout:   w.BootstrapTimeout,
+	})
+	if err == nil {
+		return
+	}
+
+	log.Printf("warning: %s の worktree %s の初期化に失敗しました: %v", specialist, name, err)
+	message := fmt.Sprintf("%s の worktree %s の初期化に失敗しました: %v", specialist, o.worktrees.Path(name), err)
+	var bootstrapErr *parallel.BootstrapError
+	if errors.As(err, &bootstrapErr) && bootstrapErr.Output != "" {
+		message += "\n" + bootstrapErr.Output
+	}
+	if err := o.inbox.Write(AgentMarshall, message, communication.MessageTypeBootstrapFailed, "bastion"); err != nil {
+		log.Printf("warning: failed to notify marshall: %v", err)
+	}
+}
+
+// タスクを割り当てた Specialist の worktree をタスク用ブランチに切り替える
+// タスクにブランチが記録されていればそれを使う（コンフリクト解消タスクなど）
+// 返り値: ブランチ名と worktree のパス（worktree を使用しない Specialist は空）
+func (o *Orchestrator) prepareTaskWorktree(specialist string, task *communication.Task) (string, string, error) {
+	branch := task.Branch
+	if branch == "" {
+		branch = parallel.TaskBranch(task.TaskID)
+	}
+	worktree, err := o.switchSpecialistBranch(specialist, branch)
+	if err != nil || worktree == "" {
+		return "", "", err
+	}
+	return branch, worktree, nil
+}
+
+// Specialist の worktree を指定したブランチに切り替える（ブランチがなければ作成）
+// 返り値: worktree のパス（worktree を使用しない Specialist は空）
+func (o *Orchestrator) switchSpecialistBranch(specialist, branch string) (string, error) {
+	info, ok, err := o.registry.Get(specialist)
+	if err != nil {
+		return "", err
+	}
+	if !ok || info.Worktree == "" {
+		return "", nil
+	}
+
+	base, err := o.worktrees.BaseCommit()
+	if err != nil {
+		return "", err
+	}
+
+	if err := o.worktrees.Switch(parallel.WorktreeName(info.Index), branch, base); err != nil {
+		return "", err
+	}
+	return info.Worktree, nil
+}
+
+// Specialist の worktree を待機用ブランチ（bastion/sp<N>）に戻す
+// 付け替えたタスクのブランチを別の worktree で checkout できるようにする
+func (o *Orchestrator) releaseTaskWorktree(specialist string) error {
+	info, ok, err := o.registry.Get(specialist)
+	if err != nil {
+		return err
+	}
+	if !ok || info.Worktree == "" {
+		return nil
+	}
+
+	_, err = o.switchSpecialistBranch(specialist, parallel.BranchPrefix+parallel.WorktreeName(info.Index))
+	return err
+}
+
+// Specialist の worktree がブランチを使用中なら待機用ブランチに戻す（別のブランチで作業中なら何もしない）
+func (o *Orchestrator) releaseBranch(specialist, branch string) error {
+	info, ok, err := o.registry.Get(specialist)
+	if err != nil || !ok || info.Worktree == "" {
+		return err
+	}
+	// worktree が消えていればブランチは使用されていない
+	current, err := o.worktrees.CurrentBranch(parallel.WorktreeName(info.Index))
+	if err != nil || current != branch {
+		return nil
+	}
+	return o.releaseTaskWorktree(specialist)
+}
+
+// .worktrees 配下の worktree と使用中の Specialist を取得
+func (o *Orchestrator) Worktrees() ([]WorktreeStatus, error) {
+	worktrees, err := o.worktrees.List()
+	if err != nil {
+		return nil, err
+	}
+
+	owners, err := o.worktreeOwners()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []WorktreeStatus
+	for _, wt := range worktrees {
+		status := WorktreeStatus{Worktree: wt, Specialist: owners[wt.Name]}
+		if !wt.Prunable {
+			dirty, err := o.worktrees.IsDirty(wt.Name)
+			if err != nil {
+				log.Printf("warning: failed to check worktree %s: %v", wt.Name, err)
+			}
+			status.Dirty = dirty
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// 使用されていない worktree を削除
+// ディレクトリが消えた worktree の管理情報を削除し、登録済みの Specialist が使っていない
+// worktree を削除する。未コミットの変更がある worktree と、処理中の指令の統合・ロールバック用
+// worktree、カバレッジ計測用の worktree は残す
+// 返り値: 削除した worktree と、変更があるため残した worktree
+func (o *Orchestrator) PruneWorktrees() ([]string, []string, error) {
+	if err := o.worktrees.Prune(); err != nil {
+		return nil, nil, err
+	}
+
+	statuses, err := o.Worktrees()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var removed, kept []string
+	for _, wt := range statuses {
+		if wt.Specialist != "" || o.worktreeInUse(wt.Name) {
+			continue
+		}
+
+		err := o.worktrees.Remove(wt.Name)
+		if errors.Is(err, parallel.ErrWorktreeDirty) {
+			kept = append(kept, wt.Name)
+			continue
+		}
+		if err != nil {
+			return removed, kept, err
+		}
+		removed = append(removed, wt.Name)
+	}
+	return removed, kept, nil
+}
+
+// Specialist 以外が使用中の worktree か
+// 統合用は指令が完了・失敗するまで、ロールバック用は revert のコンフリクトが解消されるまで使用中とする。
+// カバレッジ計測用は計測した側が破棄するため、別プロセスで計測中の可能性があり常に使用中とする
+func (o *Orchestrator) worktreeInUse(name string) bool {
+	if strings.HasPrefix(name, coverageWorktreePrefix) {
+		return true
+	}
+	if commandID, ok := strings.CutPrefix(name, parallel.IntegrationWorktreeName("")); ok {
+		// 指令が読めない場合は残す
+		cmd, err := o.commands.ReadByID(commandID)
+		if err != nil {
+			return true
+		}
+		return cmd.Status != communication.CommandStatusCompleted && cmd.Status != communication.CommandStatusFailed
+	}
+	if commandID, ok := strings.CutPrefix(name, parallel.RollbackWorktreeName("")); ok {
+		if o.worktrees.RevertInProgress(name) {
+			return true
+		}
+		cmd, err := o.commands.ReadByID(commandID)
+		if err != nil {
+			return true
+		}
+		return cmd.Rollback != nil && cmd.Rollback.Conflict != ""
+	}
+	return false
+}
+
+// worktree を削除（未コミットの変更がある場合や使用中の場合は削除しない）
+func (o *Orchestrator) RemoveWorktree(name string) error {
+	owners, err := o.worktreeOwners()
+	if err != nil {
+		return err
+	}
+	if specialist := owners[name]; specialist != "" {
+		return fmt.Errorf("worktree %s is used by %s", name, specialist)
+	}
+	return o.worktrees.Remove(name)
+}
+
+// Specialist の worktree をタスク用ブランチに切り替える（Marshall が手動で割り当てた場合に使用）
+func (o *Orchestrator) CheckoutTask(specialist, taskID string) (string, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return "", err
+	}
+
+	branch, worktree, err := o.prepareTaskWorktree(specialist, task)
+	if err != nil {
+		return "", err
+	}
+	if branch == "" {
+		return "", fmt.Errorf("%s does not use a worktree", specialist)
+	}
+
+	err = o.tasks.Update(taskID, func(t *communication.Task) error {
+		t.Branch = branch
+		t.Worktree = worktree
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return branch, nil
+}
+
+// 差し戻したタスクを担当に戻し、担当の worktree をタスクのブランチに切り替える
+// 担当がほかのタスクを抱えているか worktree を切り替えられなければ、note をコンテキストに追記して
+// pending に戻し、スケジューラの割り当てを待つ（ブランチは引き継ぐ）
+// 返り値: 担当に戻したか
+func (o *Orchestrator) sendBackTask(taskID, specialist string, at time.Time, event, detail, note string, fn func(*communication.Task)) (bool, error) {
+	task, err := o.tasks.ReadByID(taskID)
+	if err != nil {
+		return false, err
+	}
+
+	assigned, err := o.AssignedTasks(specialist)
+	if err != nil {
+		return false, err
+	}
+	requeue := ""
+	for _, id := range assigned {
+		if id != taskID {
+			requeue = specialist + " is working on " + id
+			break
+		}
+	}
+
+	var branch, worktree string
+	if requeue == "" {
+		branch, worktree, err = o.prepareTaskWorktree(specialist, task)
+		if err != nil {
+			requeue = "worktree: " + err.Error()
+		}
+	}
+
+	if requeue != "" {
+		log.Printf("[scheduler] %s を %s に戻せないため pending に戻します: %s", taskID, specialist, requeue)
+		return false, o.recordTaskEvent(taskID, at, event, detail, func(t *communication.Task) {
+			if fn != nil {
+				fn(t)
+			}
+			if note != "" {
+				t.Context = strings.TrimSpace(t.Context + "\n\n" + note)
+			}
+			t.Status = communication.TaskStatusPending
+			t.SpecialistID = ""
+			t.AssignedAt = time.Time{}
+			t.StartedAt = time.Time{}
+			t.Worktree = ""
+		})
+	}
+
+	return true, o.recordTaskEvent(taskID, at, event, detail, func(t *communication.Task) {
+		if fn != nil {
+			fn(t)
+		}
+		t.Status = communication.TaskStatusInProgress
+		t.SpecialistID = specialist
+		if branch != "" {
+			t.Branch = branch
+			t.Worktree = worktree
+		}
+	})
+}
+
+// worktree 名と使用中の Specialist の対応
+func (o *Orchestrator) worktreeOwners() (map[string]string, error) {
+	specialists, err := o.Specialists()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]string)
+	for _, info := range specialists {
+		if info.Worktree != "" {
+			owners[parallel.WorktreeName(info.Index)] = info.Name
+		}
+	}
+	return owners, nil
+}
@@ -0,0 +1,225 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/t-ishitsuka/bastion-core/internal/communication"
+	"github.com/t-ishitsuka/bastion-core/internal/parallel"
+)
+
+// worktree を有効にした Orchestrator を git リポジトリ上に作成
+// 設定ファイルを読み込んで Orchestrator を作成
+func newTestOrchestrator(t *testing.T, projectRoot string) *Orchestrator {
+	t.Helper()
+
+	o, err := NewOrchestrator(projectRoot, 0)
+	if err != nil {
+		t.Fatalf("NewOrchestrator failed: %v", err)
+	}
+	return o
+}
+
+func newWorktreeOrchestrator(t *testing.T) *Orchestrator {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	o := newTestOrchestrator(t, dir)
+	o.config.Worktree.Enabled = true
+	return o
+}
+
+func TestBuildAgentCommand(t *testing.T) {
+	o := newTestOrchestrator(t, "/project")
+
+	cmd := o.buildAgentCommand("/project/agents/specialist", "", nil, nil)
+	if cmd != "cd /project/agents/specialist && claude --add-dir /project" {
+		t.Errorf("unexpected command: %s", cmd)
+	}
+
+	cmd = o.buildAgentCommand("/project/agents/specialist", "/project/.worktrees/sp1", securitySpec, nil)
+	for _, want := range []string{
+		"cd /project/.worktrees/sp1 && ",
+		"--add-dir /project/agents/specialist --add-dir /project",
+		"--append-system-prompt",
+		"Senior Security Engineer",
+		"/project/agents/queue",
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command should contain %q: %s", want, cmd)
+		}
+	}
+}
+
+func TestPrepareTaskWorktree(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+
+	path, err := o.prepareSpecialistWorktree(1, nil)
+	if err != nil {
+		t.Fatalf("prepareSpecialistWorktree failed: %v", err)
+	}
+	if err := o.registry.Register(AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1, Worktree: path}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := o.tasks.Write(&communication.Task{TaskID: "task_001", Status: communication.TaskStatusPending}); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+
+	branch, err := o.CheckoutTask("specialist_1", "task_001")
+	if err != nil {
+		t.Fatalf("CheckoutTask failed: %v", err)
+	}
+	if branch != "bastion/task/task_001" {
+		t.Errorf("unexpected branch: %s", branch)
+	}
+
+	task, _ := o.tasks.ReadByID("task_001")
+	if task.Branch != branch || task.Worktree != path {
+		t.Errorf("branch and worktree should be recorded on the task: %+v", task)
+	}
+
+	// 待機用ブランチに戻すとタスクのブランチを別の worktree で使える
+	if err := o.releaseTaskWorktree("specialist_1"); err != nil {
+		t.Fatalf("releaseTaskWorktree failed: %v", err)
+	}
+	current, _ := o.worktrees.CurrentBranch("sp1")
+	if current != "bastion/sp1" {
+		t.Errorf("expected bastion/sp1 after release, got %s", current)
+	}
+
+	// worktree を使わない Specialist は何もしない
+	if err := o.registry.Register(AgentInfo{Name: "specialist_2", Type: AgentSpecialist, Index: 2}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := o.CheckoutTask("specialist_2", "task_001"); err == nil {
+		t.Error("expected error for specialist without worktree")
+	}
+}
+
+func TestPruneWorktrees(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+
+	var paths []string
+	for i := 1; i <= 3; i++ {
+		path, err := o.prepareSpecialistWorktree(i, nil)
+		if err != nil {
+			t.Fatalf("prepareSpecialistWorktree failed: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	// sp1 は使用中、sp2 は未使用で変更あり、sp3 は未使用
+	if err := o.registry.Register(AgentInfo{Name: "specialist_1", Type: AgentSpecialist, Index: 1, Worktree: paths[0]}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(paths[1], "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	removed, kept, err := o.PruneWorktrees()
+	if err != nil {
+		t.Fatalf("PruneWorktrees failed: %v", err)
+	}
+	if strings.Join(removed, ",") != "sp3" || strings.Join(kept, ",") != "sp2" {
+		t.Errorf("expected sp3 removed and sp2 kept, got removed=%v kept=%v", removed, kept)
+	}
+
+	// 使用中の worktree は削除できない
+	if err := o.RemoveWorktree(parallel.WorktreeName(1)); err == nil {
+		t.Error("expected error when removing worktree in use")
+	}
+}
+
+func TestPruneWorktrees_KeepsInUse(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+
+	// cmd_001 は実行中、cmd_002 は完了済み
+	for _, cmd := range []communication.Command{
+		{ID: "cmd_001", Status: communication.CommandStatusInProgress},
+		{ID: "cmd_002", Status: communication.CommandStatusCompleted},
+	} {
+		if err := o.commands.Write(cmd); err != nil {
+			t.Fatalf("failed to write command: %v", err)
+		}
+		if _, err := o.worktrees.Ensure(parallel.IntegrationWorktreeName(cmd.ID), parallel.IntegrationBranch(cmd.ID), "HEAD"); err != nil {
+			t.Fatalf("Ensure failed: %v", err)
+		}
+	}
+	if _, err := o.worktrees.Ensure(coverageWorktreeName("task_001", "head"), "bastion/coverage/task_001", "HEAD"); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	removed, kept, err := o.PruneWorktrees()
+	if err != nil {
+		t.Fatalf("PruneWorktrees failed: %v", err)
+	}
+	if strings.Join(removed, ",") != parallel.IntegrationWorktreeName("cmd_002") || len(kept) != 0 {
+		t.Errorf("expected only integration-cmd_002 removed, got removed=%v kept=%v", removed, kept)
+	}
+}
+
+func TestPrepareSpecialistWorktree_Bootstrap(t *testing.T) {
+	o := newWorktreeOrchestrator(t)
+	o.config.Worktree.Bootstrap = []string{"echo missing lockfile >&2; exit 1"}
+
+	path, err := o.prepareSpecialistWorktree(1, securitySpec)
+	if err != nil {
+		t.Fatalf("prepareSpecialistWorktree should not fail on bootstrap error: %v", err)
+	}
+	if path == "" {
+		t.Fatal("worktree should be created")
+	}
+
+	// 初期化の失敗は Marshall に報告される
+	messages, err := o.inbox.Read(AgentMarshall)
+	if err != nil {
+		t.Fatalf("failed to read inbox: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Type != communication.MessageTypeBootstrapFailed ||
+		!strings.Contains(messages[0].Message, securitySpec.Name) || !strings.Contains(messages[0].Message, "missing lockfile") {
+		t.Errorf("expected bootstrap failure report, got %+v", messages)
+	}
+
+	// 既存の worktree は再初期化しない
+	if _, err := o.prepareSpecialistWorktree(1, securitySpec); err != nil {
+		t.Fatalf("prepareSpecialistWorktree failed: %v", err)
+	}
+	messages, _ = o.inbox.Read(AgentMarshall)
+	if len(messages) != 1 {
+		t.Errorf("existing worktree should not be bootstrapped again, got %d messages", len(messages))
+	}
+}
+
+func TestSparsePaths(t *testing.T) {
+	o := newTestOrchestrator(t, t.TempDir())
+	o.config.Worktree.SparseCheckout = map[string][]string{
+		AgentSpecialist:   {"packages/core"},
+		securitySpec.Name: {"services/auth"},
+	}
+
+	if got := o.sparsePaths(nil); strings.Join(got, ",") != "packages/core" {
+		t.Errorf("unexpected sparse paths for generic specialist: %v", got)
+	}
+	if got := o.sparsePaths(securitySpec); strings.Join(got, ",") != "services/auth" {
+		t.Errorf("unexpected sparse paths for %s: %v", securitySpec.Name, got)
+	}
+}
@@ -0,0 +1,191 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 失敗時に報告する出力の末尾行数
+const bootstrapOutputLines = 20
+
+// 新しい worktree の初期化設定
+type BootstrapOptions struct {
+	// プロジェクトルートから worktree にコピーするファイル（.env など git 管理外のファイル）
+	CopyFiles []string
+	// worktree で順に実行するシェルコマンド（sh -c）
+	Commands []string
+	// worktree 間で共有するキャッシュディレクトリ（BASTION_CACHE_DIR として渡す）
+	CacheDir string
+	// コマンドに渡す環境変数（値の $BASTION_CACHE_DIR などは展開する）
+	Env map[string]string
+	// コマンドごとのタイムアウト
+	Timeout time.Duration
+}
+
+// worktree の初期化の失敗
+type BootstrapError struct {
+	// 失敗したコマンド（コマンド以外の失敗は空）
+	Command string
+	// 出力の末尾
+	Output string
+	Err    error
+}
+
+func (e *BootstrapError) Error() string {
+	if e.Command == "" {
+		return fmt.Sprintf("bootstrap failed: %v", e.Err)
+	}
+	return fmt.Sprintf("bootstrap command %q failed: %v", e.Command, e.Err)
+}
+
+func (e *BootstrapError) Unwrap() error {
+	return e.Err
+}
+
+// worktree を初期化する（ファイルのコピー → コマンドの実行）
+// 初期化後に未コミットの変更が残る場合は、ブランチを切り替えられなくなるため失敗として扱う
+func (m *WorktreeManager) Bootstrap(name string, opts BootstrapOptions) error {
+	path := m.Path(name)
+
+	for _, file := range opts.CopyFiles {
+		if err := copyFile(filepath.Join(m.repoRoot, file), filepath.Join(path, file)); err != nil {
+			return &BootstrapError{Err: fmt.Errorf("failed to copy %s: %w", file, err)}
+		}
+	}
+
+	if len(opts.Commands) > 0 {
+		env, err := m.bootstrapEnv(path, opts)
+		if err != nil {
+			return &BootstrapError{Err: err}
+		}
+
+		for _, command := range opts.Commands {
+			if err := runBootstrapCommand(path, command, env, opts.Timeout); err != nil {
+				return err
+			}
+		}
+	}
+
+	output, err := m.git(path, "status", "--porcelain")
+	if err != nil {
+		return &BootstrapError{Err: err}
+	}
+	if status := strings.TrimSpace(output); status != "" {
+		return &BootstrapError{Output: status, Err: fmt.Errorf("%w (add generated files to .gitignore)", ErrWorktreeDirty)}
+	}
+	return nil
+}
+
+// 初期化コマンドの環境変数
+func (m *WorktreeManager) bootstrapEnv(path string, opts BootstrapOptions) ([]string, error) {
+	vars := map[string]string{
+		"BASTION_PROJECT_ROOT": m.repoRoot,
+		"BASTION_WORKTREE":     path,
+	}
+
+	if opts.CacheDir != "" {
+		cacheDir := opts.CacheDir
+		if !filepath.IsAbs(cacheDir) {
+			cacheDir = filepath.Join(m.repoRoot, cacheDir)
+		}
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		vars["BASTION_CACHE_DIR"] = cacheDir
+	}
+
+	lookup := func(key string) string {
+		if value, ok := vars[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	}
+	for key, value := range opts.Env {
+		vars[key] = os.Expand(value, lookup)
+	}
+
+	env := os.Environ()
+	for key, value := range vars {
+		env = append(env, key+"="+value)
+	}
+	return env, nil
+}
+
+// 初期化コマンドを 1 つ実行
+func runBootstrapCommand(dir, command string, env []string, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = env
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// タイムアウト後に子プロセスが出力を握ったままでも待ち続けない
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		return &BootstrapError{Command: command, Output: tailLines(output.String(), bootstrapOutputLines), Err: err}
+	}
+	return nil
+}
+
+// ファイルをコピー（コピー元がなければ何もしない、コピー先が既にあれば上書きしない）
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", src)
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// 末尾 n 行を取得
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,121 @@
+package parallel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorktreeManager_Bootstrap(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+
+	// 生成物は .gitignore で除外する
+	commitFile(t, repo, ".gitignore", ".env\ndeps/\n", "ignore")
+	base, _ := m.BaseCommit()
+	if err := os.WriteFile(filepath.Join(repo, ".env"), []byte("TOKEN=x\n"), 0600); err != nil {
+		t.Fatalf("failed to write .env: %v", err)
+	}
+
+	if _, err := m.Ensure("sp1", "bastion/sp1", base); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	err := m.Bootstrap("sp1", BootstrapOptions{
+		CopyFiles: []string{".env", ".env.local"},
+		Commands:  []string{`mkdir -p deps && echo "$DEPS_CACHE" > deps/cache`},
+		CacheDir:  ".worktrees/.cache",
+		Env:       map[string]string{"DEPS_CACHE": "$BASTION_CACHE_DIR/deps"},
+		Timeout:   time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+
+	// git 管理外のファイルがコピーされる（存在しないファイルは無視）
+	data, err := os.ReadFile(filepath.Join(m.Path("sp1"), ".env"))
+	if err != nil || string(data) != "TOKEN=x\n" {
+		t.Errorf(".env should be copied: %q, %v", data, err)
+	}
+
+	// 共有キャッシュのパスが環境変数で渡される
+	data, _ = os.ReadFile(filepath.Join(m.Path("sp1"), "deps", "cache"))
+	if want := filepath.Join(repo, ".worktrees", ".cache", "deps"); strings.TrimSpace(string(data)) != want {
+		t.Errorf("expected cache path %s, got %q", want, data)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".worktrees", ".cache")); err != nil {
+		t.Errorf("cache directory should be created: %v", err)
+	}
+}
+
+func TestWorktreeManager_BootstrapFailure(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+	if _, err := m.Ensure("sp1", "bastion/sp1", base); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	err := m.Bootstrap("sp1", BootstrapOptions{
+		Commands: []string{"echo installing", "echo broken >&2; exit 3", "touch never"},
+		Timeout:  time.Minute,
+	})
+	var bootstrapErr *BootstrapError
+	if !errors.As(err, &bootstrapErr) {
+		t.Fatalf("expected BootstrapError, got %v", err)
+	}
+	if !strings.Contains(bootstrapErr.Command, "exit 3") || !strings.Contains(bootstrapErr.Output, "broken") {
+		t.Errorf("failed command and output should be reported: %+v", bootstrapErr)
+	}
+	if _, err := os.Stat(filepath.Join(m.Path("sp1"), "never")); err == nil {
+		t.Error("commands after the failure should not run")
+	}
+
+	// タイムアウト
+	err = m.Bootstrap("sp1", BootstrapOptions{Commands: []string{"sleep 5"}, Timeout: 100 * time.Millisecond})
+	if !errors.As(err, &bootstrapErr) || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+
+	// 生成物が未コミットの変更として残るとブランチを切り替えられない
+	err = m.Bootstrap("sp1", BootstrapOptions{Commands: []string{"touch generated.txt"}, Timeout: time.Minute})
+	if !errors.Is(err, ErrWorktreeDirty) {
+		t.Errorf("expected ErrWorktreeDirty, got %v", err)
+	}
+}
+
+func TestWorktreeManager_EnsureSparse(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+
+	for _, dir := range []string{"apps/web", "apps/api"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+	}
+	commitFile(t, repo, "apps/web/index.js", "web\n", "web")
+	commitFile(t, repo, "apps/api/main.go", "api\n", "api")
+	base, _ := m.BaseCommit()
+
+	path, err := m.EnsureSparse("sp1", "bastion/sp1", base, []string{"apps/web"})
+	if err != nil {
+		t.Fatalf("EnsureSparse failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "apps", "web", "index.js")); err != nil {
+		t.Errorf("sparse path should be checked out: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "apps", "api")); !os.IsNotExist(err) {
+		t.Errorf("paths outside sparse-checkout should not be checked out: %v", err)
+	}
+	if dirty, _ := m.IsDirty("sp1"); dirty {
+		t.Error("sparse worktree should be clean")
+	}
+
+	// リポジトリルートは全体を checkout したまま
+	if _, err := os.Stat(filepath.Join(repo, "apps", "api", "main.go")); err != nil {
+		t.Errorf("repository root should not be affected: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// マージでコンフリクトが発生した
+var ErrMergeConflict = errors.New("merge conflict")
+
+// マージの結果
+type MergeResult struct {
+	// 作成したマージコミット（既にマージ済みの場合は空）
+	Commit string
+	// 既にマージ済みだった
+	AlreadyMerged bool
+	// コンフリクトしたファイル
+	Conflicts []string
+}
+
+// 指令ごとの統合ブランチ名（例: bastion/integration/cmd_001）
+func IntegrationBranch(commandID string) string {
+	return BranchPrefix + "integration/" + commandID
+}
+
+// 統合ブランチ用の worktree 名（例: integration-cmd_001）
+func IntegrationWorktreeName(commandID string) string {
+	return "integration-" + commandID
+}
+
+// 退避したブランチの名前（例: bastion/task/task_001 -> bastion/archive/task/task_001）
+func ArchivedBranch(branch string) string {
+	return BranchPrefix + "archive/" + strings.TrimPrefix(branch, BranchPrefix)
+}
+
+// ブランチを bastion/archive/ 以下に退避し、元のブランチを削除する（コミットは退避先に残る）
+// worktree で使用中のブランチは削除できないため、先に worktree を別のブランチに切り替えておく
+func (m *WorktreeManager) ArchiveBranch(branch string) (string, error) {
+	return m.ArchiveBranchAs(branch, ArchivedBranch(branch))
+}
+
+// ブランチを指定した名前で退避し、元のブランチを削除する
+func (m *WorktreeManager) ArchiveBranchAs(branch, archived string) (string, error) {
+	if !m.branchExists(branch) {
+		return "", fmt.Errorf("branch not found: %s", branch)
+	}
+	if m.branchExists(archived) {
+		return "", fmt.Errorf("archived branch already exists: %s", archived)
+	}
+
+	if _, err := m.git(m.repoRoot, "branch", archived, branch); err != nil {
+		return "", fmt.Errorf("failed to archive %s: %w", branch, err)
+	}
+	if _, err := m.git(m.repoRoot, "branch", "-D", branch); err != nil {
+		return archived, fmt.Errorf("archived %s as %s but failed to delete it: %w", branch, archived, err)
+	}
+	return archived, nil
+}
+
+// ブランチが存在するか
+func (m *WorktreeManager) BranchExists(branch string) bool {
+	return m.branchExists(branch)
+}
+
+// ブランチで変更されたファイル（リポジトリルートの HEAD との分岐点からの差分）
+func (m *WorktreeManager) ChangedFiles(branch string) ([]string, error) {
+	if !m.branchExists(branch) {
+		return nil, fmt.Errorf("branch not found: %s", branch)
+	}
+
+	output, err := m.git(m.repoRoot, "diff", "--name-only", "HEAD..."+branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s: %w", branch, err)
+	}
+	return splitLines(output), nil
+}
+
+// ブランチの変更内容（リポジトリルートの HEAD との分岐点からの unified diff）
+func (m *WorktreeManager) Diff(branch string) (string, error) {
+	if !m.branchExists(branch) {
+		return "", fmt.Errorf("branch not found: %s", branch)
+	}
+
+	output, err := m.git(m.repoRoot, "diff", "--no-color", "--no-ext-diff", "HEAD..."+branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s: %w", branch, err)
+	}
+	return output, nil
+}
+
+// ブランチのコミットごとの変更内容（リポジトリルートの HEAD にないコミットの unified diff をつなげたもの）
+// 後のコミットで取り消した変更も含む
+func (m *WorktreeManager) CommitDiffs(branch string) (string, error) {
+	if !m.branchExists(branch) {
+		return "", fmt.Errorf("branch not found: %s", branch)
+	}
+
+	output, err := m.git(m.repoRoot, "log", "-p", "--no-color", "--no-ext-diff", "--format=", "HEAD.."+branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commits of %s: %w", branch, err)
+	}
+	return output, nil
+}
+
+// リポジトリルートの HEAD とブランチの分岐点のコミット
+func (m *WorktreeManager) MergeBase(branch string) (string, error) {
+	output, err := m.git(m.repoRoot, "merge-base", "HEAD", branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %s: %w", branch, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// リビジョン（ブランチ名など）のコミット
+func (m *WorktreeManager) Commit(rev string) (string, error) {
+	output, err := m.git(m.repoRoot, "rev-parse", "--verify", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// コミット時点のファイルの内容（ファイルが存在しなければ nil, false）
+func (m *WorktreeManager) FileAt(rev, file string) ([]byte, bool, error) {
+	object := rev + ":" + file
+	if _, err := m.git(m.repoRoot, "cat-file", "-e", object); err != nil {
+		return nil, false, nil
+	}
+	output, err := m.git(m.repoRoot, "show", object)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", object, err)
+	}
+	return []byte(output), true, nil
+}
+
+// worktree の現在のブランチに branch をマージする（--no-ff）
+// コンフリクトした場合は git merge --abort でマージ前の状態に戻し、ErrMergeConflict を返す
+func (m *WorktreeManager) Merge(name, branch, message string) (*MergeResult, error) {
+	path := m.Path(name)
+
+	if !m.branchExists(branch) {
+		return nil, fmt.Errorf("branch not found: %s", branch)
+	}
+
+	dirty, err := m.IsDirty(name)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("cannot merge into %s: %w", name, ErrWorktreeDirty)
+	}
+
+	// 既に取り込み済みなら何もしない
+	if _, err := m.git(path, "merge-base", "--is-ancestor", branch, "HEAD"); err == nil {
+		return &MergeResult{AlreadyMerged: true}, nil
+	}
+
+	if _, mergeErr := m.git(path, "merge", "--no-ff", "--no-edit", "-m", message, branch); mergeErr != nil {
+		output, err := m.git(path, "diff", "--name-only", "--diff-filter=U")
+		if err != nil {
+			return nil, fmt.Errorf("merge failed: %w", mergeErr)
+		}
+		conflicts := splitLines(output)
+
+		// 中途半端なマージを残さない（reset --hard は使わない）
+		if _, err := m.git(path, "merge", "--abort"); err != nil {
+			return nil, fmt.Errorf("failed to abort merge: %w", err)
+		}
+
+		if len(conflicts) == 0 {
+			return nil, fmt.Errorf("merge failed: %w", mergeErr)
+		}
+		return &MergeResult{Conflicts: conflicts}, fmt.Errorf("%s: %w", branch, ErrMergeConflict)
+	}
+
+	commit, err := m.git(path, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve merge commit: %w", err)
+	}
+	return &MergeResult{Commit: strings.TrimSpace(commit)}, nil
+}
+
+// 空行を除いて行ごとに分割（空白を含むファイル名に対応）
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,217 @@
+package parallel
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// worktree でファイルを書き込んでコミット
+func commitFile(t *testing.T, dir, name, content, message string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"add", name},
+		{"commit", "-q", "-m", message},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+}
+
+func TestWorktreeManager_Merge(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	// sp1 で task_001 のブランチに変更をコミット
+	sp1, err := m.Ensure("sp1", TaskBranch("task_001"), base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	commitFile(t, sp1, "a.txt", "a\n", "add a")
+
+	if _, err := m.Ensure("integration-cmd_001", IntegrationBranch("cmd_001"), base); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	result, err := m.Merge("integration-cmd_001", TaskBranch("task_001"), "merge task_001")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if result.Commit == "" || result.AlreadyMerged {
+		t.Errorf("expected merge commit, got %+v", result)
+	}
+
+	// 2 回目はマージ済み
+	result, err = m.Merge("integration-cmd_001", TaskBranch("task_001"), "merge task_001")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !result.AlreadyMerged {
+		t.Errorf("expected already merged, got %+v", result)
+	}
+}
+
+func TestWorktreeManager_MergeConflictAborts(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	sp1, _ := m.Ensure("sp1", TaskBranch("task_001"), base)
+	commitFile(t, sp1, "shared.txt", "from task_001\n", "task_001")
+	sp2, _ := m.Ensure("sp2", TaskBranch("task_002"), base)
+	commitFile(t, sp2, "shared.txt", "from task_002\n", "task_002")
+
+	if _, err := m.Ensure("integration-cmd_001", IntegrationBranch("cmd_001"), base); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	first, err := m.Merge("integration-cmd_001", TaskBranch("task_001"), "merge task_001")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	result, err := m.Merge("integration-cmd_001", TaskBranch("task_002"), "merge task_002")
+	if !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("expected ErrMergeConflict, got %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0] != "shared.txt" {
+		t.Errorf("unexpected conflicts: %v", result.Conflicts)
+	}
+
+	// マージ前の状態に戻っている
+	dirty, err := m.IsDirty("integration-cmd_001")
+	if err != nil {
+		t.Fatalf("IsDirty failed: %v", err)
+	}
+	if dirty {
+		t.Error("integration worktree should be clean after aborted merge")
+	}
+	head, _ := m.git(m.Path("integration-cmd_001"), "rev-parse", "HEAD")
+	if head[:len(first.Commit)] != first.Commit {
+		t.Errorf("HEAD should stay at %s, got %s", first.Commit, head)
+	}
+}
+
+func TestWorktreeManager_FileAt(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	sp1, err := m.Ensure("sp1", TaskBranch("task_001"), base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	commitFile(t, sp1, "go.mod", "module example.com/app\n", "add go.mod")
+
+	mergeBase, err := m.MergeBase(TaskBranch("task_001"))
+	if err != nil || mergeBase != base {
+		t.Fatalf("expected merge base %s, got %s (%v)", base, mergeBase, err)
+	}
+
+	// 分岐点には存在しない
+	if _, ok, err := m.FileAt(mergeBase, "go.mod"); err != nil || ok {
+		t.Errorf("go.mod should not exist at merge base: ok=%v err=%v", ok, err)
+	}
+	content, ok, err := m.FileAt(TaskBranch("task_001"), "go.mod")
+	if err != nil || !ok || string(content) != "module example.com/app\n" {
+		t.Errorf("unexpected content: %q ok=%v err=%v", content, ok, err)
+	}
+}
+
+func TestWorktreeManager_Diff(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	sp1, err := m.Ensure("sp1", TaskBranch("task_001"), base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	commitFile(t, sp1, "app.go", "package app\n", "add app.go")
+
+	diff, err := m.Diff(TaskBranch("task_001"))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(diff, "+++ b/app.go") || !strings.Contains(diff, "+package app") {
+		t.Errorf("unexpected diff: %s", diff)
+	}
+
+	if _, err := m.Diff(TaskBranch("task_missing")); err == nil {
+		t.Error("expected error for missing branch")
+	}
+}
+
+func TestWorktreeManager_CommitDiffs(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	sp1, err := m.Ensure("sp1", TaskBranch("task_001"), base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	commitFile(t, sp1, "app.go", "package app\n// token\n", "add app.go")
+	commitFile(t, sp1, "app.go", "package app\n", "remove token")
+
+	// 後のコミットで消した行も含む
+	diffs, err := m.CommitDiffs(TaskBranch("task_001"))
+	if err != nil {
+		t.Fatalf("CommitDiffs failed: %v", err)
+	}
+	if !strings.Contains(diffs, "+// token") || !strings.Contains(diffs, "-// token") {
+		t.Errorf("unexpected diffs: %s", diffs)
+	}
+
+	if _, err := m.CommitDiffs(TaskBranch("task_missing")); err == nil {
+		t.Error("expected error for missing branch")
+	}
+}
+
+func TestWorktreeManager_ArchiveBranch(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	branch := TaskBranch("task_001_attempt_2")
+	sp1, err := m.Ensure("sp1", branch, base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	commitFile(t, sp1, "app.go", "package app\n", "attempt 2")
+	head, _ := m.Commit(branch)
+
+	// worktree で使用中のブランチは退避先を作っても削除できない
+	if _, err := m.ArchiveBranch(branch); err == nil || !m.BranchExists(branch) {
+		t.Fatalf("branch in use should not be deleted: %v", err)
+	}
+	if _, err := m.git(repo, "branch", "-D", ArchivedBranch(branch)); err != nil {
+		t.Fatalf("failed to clean up: %v", err)
+	}
+
+	if err := m.Switch("sp1", BranchPrefix+"sp1", base); err != nil {
+		t.Fatalf("Switch failed: %v", err)
+	}
+	archived, err := m.ArchiveBranch(branch)
+	if err != nil {
+		t.Fatalf("ArchiveBranch failed: %v", err)
+	}
+	if archived != "bastion/archive/task/task_001_attempt_2" || m.BranchExists(branch) {
+		t.Errorf("branch should be moved to %s", archived)
+	}
+	if commit, err := m.Commit(archived); err != nil || commit != head {
+		t.Errorf("archived branch should keep the commits: %s (%v)", commit, err)
+	}
+	if _, err := m.ArchiveBranch(branch); err == nil {
+		t.Error("expected error for missing branch")
+	}
+}
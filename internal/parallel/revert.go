@@ -0,0 +1,175 @@
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// revert でコンフリクトが発生した
+var ErrRevertConflict = errors.New("revert conflict")
+
+// コンフリクトした revert が解消されていない
+var ErrRevertInProgress = errors.New("revert in progress")
+
+// bastion の revert コミットに付けるトレーラー（打ち消したコミットを記録し、再実行時に打ち消し済みと判定する）
+const RevertTrailer = "Bastion-Reverts"
+
+// revert の結果
+type RevertResult struct {
+	// 作成した revert コミット（既に revert 済みの場合は空）
+	Commit string
+	// 既に revert 済みだった
+	AlreadyReverted bool
+	// コンフリクトしたファイル
+	Conflicts []string
+}
+
+// 指令のロールバック用ブランチ名（例: bastion/rollback/cmd_001）
+func RollbackBranch(commandID string) string {
+	return BranchPrefix + "rollback/" + commandID
+}
+
+// ロールバック用の worktree 名（例: rollback-cmd_001）
+func RollbackWorktreeName(commandID string) string {
+	return "rollback-" + commandID
+}
+
+// commit が rev の祖先（rev に取り込まれている）か
+func (m *WorktreeManager) IsAncestor(commit, rev string) bool {
+	_, err := m.git(m.repoRoot, "merge-base", "--is-ancestor", commit, rev)
+	return err == nil
+}
+
+// マージコミットで取り込んだコミット（古い順）
+func (m *WorktreeManager) MergedCommits(merge string) ([]string, error) {
+	output, err := m.git(m.repoRoot, "rev-list", "--reverse", merge+"^1.."+merge+"^2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits merged by %s: %w", merge, err)
+	}
+	return splitLines(output), nil
+}
+
+// worktree でコンフリクトした revert が進行中か
+func (m *WorktreeManager) RevertInProgress(name string) bool {
+	_, err := m.git(m.Path(name), "rev-parse", "-q", "--verify", "REVERT_HEAD")
+	return err == nil
+}
+
+// worktree のコンフリクトしているファイル
+func (m *WorktreeManager) UnmergedFiles(name string) ([]string, error) {
+	output, err := m.git(m.Path(name), "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// worktree の現在のブランチで commit を revert する（マージコミットは 1 番目の親に戻す）
+// 履歴は書き換えず、打ち消すコミットを追加する。コミットメッセージには RevertTrailer を付ける
+// コンフリクトした場合は revert を進行中のまま残し、ErrRevertConflict を返す
+// （worktree で解消して git add し、git revert --continue でコミットする）
+// 解消前に再度呼ぶと ErrRevertInProgress を返す
+func (m *WorktreeManager) Revert(name, commit string) (*RevertResult, error) {
+	path := m.Path(name)
+
+	if m.RevertInProgress(name) {
+		conflicts, err := m.UnmergedFiles(name)
+		if err != nil {
+			return nil, err
+		}
+		return &RevertResult{Conflicts: conflicts}, fmt.Errorf("%s: %w", name, ErrRevertInProgress)
+	}
+
+	dirty, err := m.IsDirty(name)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("cannot revert in %s: %w", name, ErrWorktreeDirty)
+	}
+
+	// 取り込まれていないコミットは revert できない
+	if _, err := m.git(path, "merge-base", "--is-ancestor", commit, "HEAD"); err != nil {
+		return nil, fmt.Errorf("commit is not in %s: %s", name, commit)
+	}
+
+	// 既に revert 済みなら何もしない（再実行時）
+	// git revert のメッセージか bastion のトレーラーがあれば revert 済みとみなす
+	full, err := m.git(path, "rev-parse", "--verify", commit+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", commit, err)
+	}
+	full = strings.TrimSpace(full)
+	reverted, err := m.git(path, "log", "--format=%H", "-F",
+		"--grep", "This reverts commit "+full, "--grep", RevertTrailer+": "+full, full+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search reverts of %s: %w", commit, err)
+	}
+	if strings.TrimSpace(reverted) != "" {
+		return &RevertResult{AlreadyReverted: true}, nil
+	}
+
+	parents, err := m.git(path, "rev-list", "--parents", "-n", "1", full)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parents of %s: %w", commit, err)
+	}
+	args := []string{"revert", "--no-commit"}
+	if len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+
+	if _, revertErr := m.git(path, append(args, full)...); revertErr != nil {
+		conflicts, err := m.UnmergedFiles(name)
+		if err != nil || len(conflicts) == 0 {
+			// コンフリクト以外の失敗は revert 前の状態に戻す（reset --hard は使わない）
+			if _, err := m.git(path, "revert", "--abort"); err != nil {
+				return nil, fmt.Errorf("failed to abort revert: %w", err)
+			}
+			return nil, fmt.Errorf("revert failed: %w", revertErr)
+		}
+
+		// 解消後の git revert --continue でもトレーラーが付くようにする
+		if err := m.appendRevertTrailer(path, full); err != nil {
+			return nil, err
+		}
+		return &RevertResult{Conflicts: conflicts}, fmt.Errorf("%s: %w", commit, ErrRevertConflict)
+	}
+
+	if err := m.appendRevertTrailer(path, full); err != nil {
+		return nil, err
+	}
+	if _, err := m.git(path, "commit", "--no-edit"); err != nil {
+		return nil, fmt.Errorf("failed to commit revert of %s: %w", commit, err)
+	}
+
+	head, err := m.git(path, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revert commit: %w", err)
+	}
+	return &RevertResult{Commit: strings.TrimSpace(head)}, nil
+}
+
+// 進行中の revert のコミットメッセージ（MERGE_MSG）にトレーラーを追加する
+func (m *WorktreeManager) appendRevertTrailer(path, commit string) error {
+	output, err := m.git(path, "rev-parse", "--git-path", "MERGE_MSG")
+	if err != nil {
+		return fmt.Errorf("failed to locate revert message: %w", err)
+	}
+	msgPath := strings.TrimSpace(output)
+	if !filepath.IsAbs(msgPath) {
+		msgPath = filepath.Join(path, msgPath)
+	}
+
+	data, err := os.ReadFile(msgPath)
+	if err != nil {
+		return fmt.Errorf("failed to read revert message: %w", err)
+	}
+	message := strings.TrimRight(string(data), "\n") + "\n\n" + RevertTrailer + ": " + commit + "\n"
+	if err := os.WriteFile(msgPath, []byte(message), 0644); err != nil {
+		return fmt.Errorf("failed to write revert message: %w", err)
+	}
+	return nil
+}
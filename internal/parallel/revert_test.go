@@ -0,0 +1,156 @@
+package parallel
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorktreeManager_Revert(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	sp1, _ := m.Ensure("sp1", TaskBranch("task_001"), base)
+	commitFile(t, sp1, "a.txt", "a\n", "add a")
+	commitFile(t, sp1, "b.txt", "b\n", "add b")
+	if _, err := m.Ensure("integration-cmd_001", IntegrationBranch("cmd_001"), base); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	merged, err := m.Merge("integration-cmd_001", TaskBranch("task_001"), "merge task_001")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	commits, err := m.MergedCommits(merged.Commit)
+	if err != nil || len(commits) != 2 {
+		t.Fatalf("merge should bring in 2 commits: %v (%v)", commits, err)
+	}
+	if !m.IsAncestor(merged.Commit, IntegrationBranch("cmd_001")) || m.IsAncestor(merged.Commit, "main") {
+		t.Error("merge commit should only be in the integration branch")
+	}
+
+	// 統合ブランチから分岐したロールバック用ブランチで打ち消す
+	path, err := m.Ensure(RollbackWorktreeName("cmd_001"), RollbackBranch("cmd_001"), IntegrationBranch("cmd_001"))
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	result, err := m.Revert(RollbackWorktreeName("cmd_001"), merged.Commit)
+	if err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+	if result.Commit == "" || result.AlreadyReverted {
+		t.Errorf("expected revert commit, got %+v", result)
+	}
+	if message, _ := m.git(path, "log", "-1", "--format=%B"); !strings.Contains(message, RevertTrailer+": "+merged.Commit) {
+		t.Errorf("revert commit should have the trailer: %s", message)
+	}
+	if _, err := os.Stat(filepath.Join(path, "a.txt")); !os.IsNotExist(err) {
+		t.Errorf("merged file should be removed: %v", err)
+	}
+	// 元のマージコミットは履歴に残る
+	if !m.IsAncestor(merged.Commit, RollbackBranch("cmd_001")) {
+		t.Error("history should not be rewritten")
+	}
+
+	// 2 回目は revert 済み
+	result, err = m.Revert(RollbackWorktreeName("cmd_001"), merged.Commit)
+	if err != nil || !result.AlreadyReverted {
+		t.Errorf("expected already reverted, got %+v (%v)", result, err)
+	}
+
+	// 取り込まれていないコミットは revert できない
+	if _, err := m.Revert("sp1", merged.Commit); err == nil {
+		t.Error("revert of a commit outside the branch should fail")
+	}
+}
+
+func TestWorktreeManager_RevertConflictInProgress(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	sp1, _ := m.Ensure("sp1", TaskBranch("task_001"), base)
+	commitFile(t, sp1, "shared.txt", "from task_001\n", "task_001")
+	if _, err := m.Ensure("integration-cmd_001", IntegrationBranch("cmd_001"), base); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	merged, err := m.Merge("integration-cmd_001", TaskBranch("task_001"), "merge task_001")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	// マージ後に同じファイルを変更すると revert がコンフリクトする
+	name := RollbackWorktreeName("cmd_001")
+	path, _ := m.Ensure(name, RollbackBranch("cmd_001"), IntegrationBranch("cmd_001"))
+	commitFile(t, path, "shared.txt", "edited later\n", "edit shared")
+	head, _ := m.Commit(RollbackBranch("cmd_001"))
+
+	result, err := m.Revert(name, merged.Commit)
+	if !errors.Is(err, ErrRevertConflict) {
+		t.Fatalf("expected ErrRevertConflict, got %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0] != "shared.txt" {
+		t.Errorf("unexpected conflicts: %v", result.Conflicts)
+	}
+	// revert は解消を待つため中止しない
+	if !m.RevertInProgress(name) {
+		t.Fatal("revert should stay in progress")
+	}
+	if after, _ := m.Commit(RollbackBranch("cmd_001")); after != head {
+		t.Errorf("HEAD should stay at %s until resolved, got %s", head, after)
+	}
+
+	// 解消前に再度呼んでも revert し直さない
+	result, err = m.Revert(name, merged.Commit)
+	if !errors.Is(err, ErrRevertInProgress) || len(result.Conflicts) != 1 {
+		t.Fatalf("expected ErrRevertInProgress, got %+v (%v)", result, err)
+	}
+
+	// 解消して git revert --continue でコミットすると打ち消し済みになる
+	if err := os.WriteFile(filepath.Join(path, "shared.txt"), []byte("resolved\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, args := range [][]string{{"add", "shared.txt"}, {"revert", "--continue"}} {
+		cmd := exec.Command("git", append([]string{"-C", path}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	if message, _ := m.git(path, "log", "-1", "--format=%B"); !strings.Contains(message, RevertTrailer+": "+merged.Commit) {
+		t.Errorf("resolved revert should keep the trailer: %s", message)
+	}
+	result, err = m.Revert(name, merged.Commit)
+	if err != nil || !result.AlreadyReverted {
+		t.Errorf("expected already reverted, got %+v (%v)", result, err)
+	}
+}
+
+func TestWorktreeManager_RevertDetectsTrailer(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	path, _ := m.Ensure("sp1", TaskBranch("task_001"), base)
+	commitFile(t, path, "a.txt", "a\n", "add a")
+	target, _ := m.Commit(TaskBranch("task_001"))
+
+	// トレーラーだけを付けて手で打ち消したコミット
+	if err := os.Remove(filepath.Join(path, "a.txt")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "remove a\n\n" + RevertTrailer + ": " + target}} {
+		if _, err := m.git(path, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	result, err := m.Revert("sp1", target)
+	if err != nil || !result.AlreadyReverted {
+		t.Errorf("trailer should mark the commit as reverted: %+v (%v)", result, err)
+	}
+}
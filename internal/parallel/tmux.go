@@ -29,6 +29,15 @@ func NewSessionManager() *SessionManager {
 	}
 }
 
+// tmux ターゲットをセッション名付きに変換
+// ペイン ID（例: %12）はセッションを跨いで一意なためそのまま使用する
+func (sm *SessionManager) fullTarget(target string) string {
+	if strings.HasPrefix(target, "%") {
+		return target
+	}
+	return fmt.Sprintf("%s:%s", sm.sessionName, target)
+}
+
 // セッションが存在するか確認
 func (sm *SessionManager) SessionExists() (bool, error) {
 	cmd := exec.Command("tmux", "has-session", "-t", sm.sessionName)
@@ -92,9 +101,50 @@ func (sm *SessionManager) SplitPaneVertical(window string) error {
 	return nil
 }
 
+// ペインを分割（水平）し、新しいペインの ID を返す
+func (sm *SessionManager) SplitPaneWithID(window string) (string, error) {
+	target := fmt.Sprintf("%s:%s", sm.sessionName, window)
+	cmd := exec.Command("tmux", "split-window", "-h", "-t", target, "-P", "-F", "#{pane_id}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to split pane: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ウィンドウを作成し、最初のペインの ID を返す
+func (sm *SessionManager) CreateWindowWithID(name string) (string, error) {
+	target := fmt.Sprintf("%s:", sm.sessionName)
+	cmd := exec.Command("tmux", "new-window", "-d", "-t", target, "-n", name, "-P", "-F", "#{pane_id}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create window: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ペイン ID を取得（例: specialists.0 -> %12）
+func (sm *SessionManager) PaneID(target string) (string, error) {
+	cmd := exec.Command("tmux", "display-message", "-p", "-t", sm.fullTarget(target), "#{pane_id}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get pane id: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ペインを閉じる
+func (sm *SessionManager) KillPane(target string) error {
+	cmd := exec.Command("tmux", "kill-pane", "-t", sm.fullTarget(target))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to kill pane: %w", err)
+	}
+	return nil
+}
+
 // コマンドを送信
 func (sm *SessionManager) SendKeys(target, keys string, enter bool) error {
-	fullTarget := fmt.Sprintf("%s:%s", sm.sessionName, target)
+	fullTarget := sm.fullTarget(target)
 
 	// exec.Command は引数を適切にエスケープするため、-l フラグは不要
 	// すべてのテキスト（シェルコマンドと単純なテキストの両方）を同じ方法で送信
@@ -142,9 +192,55 @@ func (sm *SessionManager) ListPanes(window string) ([]string, error) {
 	return lines, nil
 }
 
+// ペインの状態
+type PaneInfo struct {
+	// ペインで実行中のコマンド（例: claude, node, bash）
+	CurrentCommand string
+	// ペインのプロセスが終了しているか（remain-on-exit 時）
+	Dead bool
+}
+
+// ペインの表示内容を取得（末尾 lines 行）
+func (sm *SessionManager) CapturePane(target string, lines int) (string, error) {
+	fullTarget := sm.fullTarget(target)
+	cmd := exec.Command("tmux", "capture-pane", "-p", "-J", "-t", fullTarget, "-S", fmt.Sprintf("-%d", lines))
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture pane: %w", err)
+	}
+	return string(output), nil
+}
+
+// ペインの状態を取得
+func (sm *SessionManager) GetPaneInfo(target string) (*PaneInfo, error) {
+	fullTarget := sm.fullTarget(target)
+	cmd := exec.Command("tmux", "display-message", "-p", "-t", fullTarget, "#{pane_current_command}\t#{pane_dead}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pane info: %w", err)
+	}
+
+	fields := strings.Split(strings.TrimRight(string(output), "\n"), "\t")
+	info := &PaneInfo{CurrentCommand: fields[0]}
+	if len(fields) > 1 {
+		info.Dead = fields[1] == "1"
+	}
+	return info, nil
+}
+
+// 終了したペインをシェルで再生成
+func (sm *SessionManager) RespawnPane(target string) error {
+	fullTarget := sm.fullTarget(target)
+	cmd := exec.Command("tmux", "respawn-pane", "-k", "-t", fullTarget)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to respawn pane: %w", err)
+	}
+	return nil
+}
+
 // ペインタイトルを設定（カスタム属性を使用して上書き防止）
 func (sm *SessionManager) SetPaneTitle(target, title string) error {
-	fullTarget := fmt.Sprintf("%s:%s", sm.sessionName, target)
+	fullTarget := sm.fullTarget(target)
 
 	// カスタムペイン属性を設定（アプリケーションに上書きされない）
 	cmd := exec.Command("tmux", "set-option", "-p", "-t", fullTarget, "@pane_label", title)
@@ -173,6 +269,32 @@ func (sm *SessionManager) EnablePaneBorders() error {
 	return nil
 }
 
+// ステータスラインの右側にメッセージを表示
+func (sm *SessionManager) SetStatusMessage(message string) error {
+	// "#" は tmux のフォーマット指定子として解釈されるためエスケープする
+	escaped := strings.ReplaceAll(message, "#", "##")
+	cmd := exec.Command("tmux", "set-option", "-t", sm.sessionName, "status-right", escaped)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to set status message: %w", err)
+	}
+
+	// 既定の長さ（40 文字）では切り詰められるため広げる
+	lengthCmd := exec.Command("tmux", "set-option", "-t", sm.sessionName, "status-right-length", "120")
+	if err := lengthCmd.Run(); err != nil {
+		return fmt.Errorf("failed to set status length: %w", err)
+	}
+	return nil
+}
+
+// ステータスラインの右側を既定の表示に戻す
+func (sm *SessionManager) ClearStatusMessage() error {
+	cmd := exec.Command("tmux", "set-option", "-u", "-t", sm.sessionName, "status-right")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to clear status message: %w", err)
+	}
+	return nil
+}
+
 // カスタムキーバインドを設定
 func (sm *SessionManager) SetupKeyBindings(bastionCmd string) error {
 	// Ctrl+b q で確認付き停止
@@ -206,7 +328,7 @@ func (sm *SessionManager) KillSession() error {
 
 // ペインのサイズを変更
 func (sm *SessionManager) ResizePane(target string, size int) error {
-	fullTarget := fmt.Sprintf("%s:%s", sm.sessionName, target)
+	fullTarget := sm.fullTarget(target)
 	cmd := exec.Command("tmux", "resize-pane", "-t", fullTarget, "-x", fmt.Sprintf("%d%%", size))
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to resize pane: %w", err)
@@ -216,7 +338,7 @@ func (sm *SessionManager) ResizePane(target string, size int) error {
 
 // ペインを選択
 func (sm *SessionManager) SelectPane(target string) error {
-	fullTarget := fmt.Sprintf("%s:%s", sm.sessionName, target)
+	fullTarget := sm.fullTarget(target)
 	cmd := exec.Command("tmux", "select-pane", "-t", fullTarget)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to select pane: %w", err)
@@ -3,6 +3,7 @@ package parallel
 import (
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 )
@@ -200,6 +201,126 @@ func TestSessionManager_SendKeys(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 }
 
+func TestSessionManager_CapturePane(t *testing.T) {
+	if !isTmuxAvailable() {
+		t.Skip("tmux is not available")
+	}
+
+	sm := NewSessionManager()
+	defer cleanupSession(t, sm)
+
+	// セッション作成
+	if err := sm.CreateSession(); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	if err := sm.SendKeys(WindowEnvoy, "echo capture-marker", true); err != nil {
+		t.Fatalf("failed to send keys: %v", err)
+	}
+
+	// 入力がペインに反映されるまで待機
+	var output string
+	for i := 0; i < 50; i++ {
+		time.Sleep(100 * time.Millisecond)
+		out, err := sm.CapturePane(WindowEnvoy, 20)
+		if err != nil {
+			t.Fatalf("failed to capture pane: %v", err)
+		}
+		output = out
+		if strings.Contains(output, "capture-marker") {
+			break
+		}
+	}
+
+	if !strings.Contains(output, "capture-marker") {
+		t.Errorf("expected captured output to contain sent keys, got:\n%s", output)
+	}
+}
+
+func TestSessionManager_GetPaneInfo(t *testing.T) {
+	if !isTmuxAvailable() {
+		t.Skip("tmux is not available")
+	}
+
+	sm := NewSessionManager()
+	defer cleanupSession(t, sm)
+
+	// セッション作成
+	if err := sm.CreateSession(); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	info, err := sm.GetPaneInfo(WindowEnvoy)
+	if err != nil {
+		t.Fatalf("failed to get pane info: %v", err)
+	}
+	if info.CurrentCommand == "" {
+		t.Error("expected current command to be set")
+	}
+	if info.Dead {
+		t.Error("pane should not be dead")
+	}
+}
+
+func TestSessionManager_SplitAndKillPaneByID(t *testing.T) {
+	if !isTmuxAvailable() {
+		t.Skip("tmux is not available")
+	}
+
+	sm := NewSessionManager()
+	defer cleanupSession(t, sm)
+
+	// セッション作成
+	if err := sm.CreateSession(); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	paneID, err := sm.SplitPaneWithID(WindowEnvoy)
+	if err != nil {
+		t.Fatalf("failed to split pane: %v", err)
+	}
+	if !strings.HasPrefix(paneID, "%") {
+		t.Errorf("expected pane id like %%N, got %q", paneID)
+	}
+
+	// インデックス指定から同じペイン ID が得られる
+	resolved, err := sm.PaneID(WindowEnvoy + ".1")
+	if err != nil {
+		t.Fatalf("failed to resolve pane id: %v", err)
+	}
+	if resolved != paneID {
+		t.Errorf("expected %s, got %s", paneID, resolved)
+	}
+
+	// ペイン ID を直接ターゲットにできる
+	if err := sm.SendKeys(paneID, "echo by-id", true); err != nil {
+		t.Errorf("failed to send keys by pane id: %v", err)
+	}
+
+	if err := sm.KillPane(paneID); err != nil {
+		t.Fatalf("failed to kill pane: %v", err)
+	}
+
+	panes, err := sm.ListPanes(WindowEnvoy)
+	if err != nil {
+		t.Fatalf("failed to list panes: %v", err)
+	}
+	if len(panes) != 1 {
+		t.Errorf("expected 1 pane after kill, got %d", len(panes))
+	}
+}
+
+func TestSessionManager_FullTarget(t *testing.T) {
+	sm := NewSessionManager()
+
+	if got := sm.fullTarget("main.0"); got != SessionName+":main.0" {
+		t.Errorf("expected session-prefixed target, got %s", got)
+	}
+	if got := sm.fullTarget("%12"); got != "%12" {
+		t.Errorf("expected pane id unchanged, got %s", got)
+	}
+}
+
 func TestSessionManager_KillSession(t *testing.T) {
 	if !isTmuxAvailable() {
 		t.Skip("tmux is not available")
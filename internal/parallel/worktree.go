@@ -0,0 +1,331 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// worktree の配置ディレクトリ（リポジトリルートからの相対パス）
+	WorktreeDir = ".worktrees"
+
+	// Bastion が作成するブランチの接頭辞
+	BranchPrefix = "bastion/"
+)
+
+// 未コミットの変更がある worktree は削除・ブランチ切り替えをしない
+var ErrWorktreeDirty = errors.New("worktree has uncommitted changes")
+
+// git worktree の情報
+type Worktree struct {
+	// worktree 名（.worktrees/<name>）
+	Name   string
+	Path   string
+	Branch string
+	Head   string
+	// ディレクトリが削除されており prune 対象
+	Prunable bool
+}
+
+// Specialist ごとの git worktree を管理
+// 各 Specialist は .worktrees/sp<N> で作業し、ファイル競合を物理的に回避する
+type WorktreeManager struct {
+	repoRoot string
+}
+
+// 新しい WorktreeManager を作成
+func NewWorktreeManager(repoRoot string) *WorktreeManager {
+	return &WorktreeManager{
+		repoRoot: repoRoot,
+	}
+}
+
+// Specialist 番号から worktree 名を決定（例: 1 -> sp1）
+func WorktreeName(index int) string {
+	return fmt.Sprintf("sp%d", index)
+}
+
+// タスク用のブランチ名（例: bastion/task/task_001）
+func TaskBranch(taskID string) string {
+	return BranchPrefix + "task/" + taskID
+}
+
+// リポジトリルートが git リポジトリか
+func (m *WorktreeManager) IsRepository() bool {
+	_, err := m.git(m.repoRoot, "rev-parse", "--git-dir")
+	return err == nil
+}
+
+// worktree のパス
+func (m *WorktreeManager) Path(name string) string {
+	return filepath.Join(m.repoRoot, WorktreeDir, name)
+}
+
+// worktree が存在するか
+func (m *WorktreeManager) Exists(name string) bool {
+	return m.isWorktree(m.Path(name))
+}
+
+// worktree を作成（既に存在する場合はそのまま使う）
+// ブランチが存在しなければ base から作成する
+func (m *WorktreeManager) Ensure(name, branch, base string) (string, error) {
+	return m.EnsureSparse(name, branch, base, nil)
+}
+
+// sparse-checkout を設定して worktree を作成（既に存在する場合はそのまま使う）
+// sparse が空なら全体を checkout する。指定したディレクトリとルート直下のファイルのみ展開する（cone モード）
+func (m *WorktreeManager) EnsureSparse(name, branch, base string, sparse []string) (string, error) {
+	path := m.Path(name)
+
+	if m.isWorktree(path) {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	args := []string{"worktree", "add"}
+	if len(sparse) > 0 {
+		// 全体を展開しないよう、sparse-checkout を設定してから checkout する
+		args = append(args, "--no-checkout")
+	}
+	if m.branchExists(branch) {
+		args = append(args, path, branch)
+	} else {
+		args = append(args, "-b", branch, path, base)
+	}
+	if _, err := m.git(m.repoRoot, args...); err != nil {
+		return "", fmt.Errorf("failed to add worktree %s: %w", name, err)
+	}
+
+	if len(sparse) > 0 {
+		if _, err := m.git(path, append([]string{"sparse-checkout", "set", "--cone"}, sparse...)...); err != nil {
+			return "", fmt.Errorf("failed to set sparse-checkout for %s: %w", name, err)
+		}
+		if _, err := m.git(path, "checkout", branch); err != nil {
+			return "", fmt.Errorf("failed to checkout %s in %s: %w", branch, name, err)
+		}
+	}
+	return path, nil
+}
+
+// worktree を別のブランチに切り替える（ブランチが存在しなければ base から作成）
+// 未コミットの変更がある場合は切り替えない
+func (m *WorktreeManager) Switch(name, branch, base string) error {
+	path := m.Path(name)
+
+	dirty, err := m.IsDirty(name)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("cannot switch %s to %s: %w", name, branch, ErrWorktreeDirty)
+	}
+
+	args := []string{"switch", branch}
+	if !m.branchExists(branch) {
+		args = []string{"switch", "-c", branch, base}
+	}
+	if _, err := m.git(path, args...); err != nil {
+		return fmt.Errorf("failed to switch %s to %s: %w", name, branch, err)
+	}
+	return nil
+}
+
+// リポジトリルートで checkout 中のコミットを取得（新しいブランチの起点）
+func (m *WorktreeManager) BaseCommit() (string, error) {
+	output, err := m.git(m.repoRoot, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// 未コミットの変更（未追跡ファイルを含む）があるか
+func (m *WorktreeManager) IsDirty(name string) (bool, error) {
+	output, err := m.git(m.Path(name), "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("failed to check worktree status: %w", err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+// .worktrees 配下の worktree 一覧を取得
+func (m *WorktreeManager) List() ([]Worktree, error) {
+	output, err := m.git(m.repoRoot, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	dir := m.worktreeRoot()
+	var worktrees []Worktree
+	for _, wt := range parseWorktreeList(output) {
+		if filepath.Dir(wt.Path) != dir {
+			continue
+		}
+		wt.Name = filepath.Base(wt.Path)
+		worktrees = append(worktrees, wt)
+	}
+	return worktrees, nil
+}
+
+// ディレクトリが削除された worktree の管理情報を削除
+func (m *WorktreeManager) Prune() error {
+	if _, err := m.git(m.repoRoot, "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w", err)
+	}
+	return nil
+}
+
+// worktree を削除（ブランチは残す）
+// 未コミットの変更がある場合は削除しない
+func (m *WorktreeManager) Remove(name string) error {
+	path := m.Path(name)
+	if !m.isWorktree(path) {
+		return fmt.Errorf("worktree not found: %s", name)
+	}
+
+	dirty, err := m.IsDirty(name)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("cannot remove %s: %w", name, ErrWorktreeDirty)
+	}
+
+	if _, err := m.git(m.repoRoot, "worktree", "remove", path); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", name, err)
+	}
+	return nil
+}
+
+// コミットを detached HEAD で checkout した一時的な worktree を作成（既に存在する場合は作り直す）
+func (m *WorktreeManager) Checkout(name, rev string) (string, error) {
+	path := m.Path(name)
+	if m.isWorktree(path) {
+		if err := m.Discard(name); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if _, err := m.git(m.repoRoot, "worktree", "add", "--detach", path, rev); err != nil {
+		return "", fmt.Errorf("failed to checkout %s: %w", rev, err)
+	}
+	return path, nil
+}
+
+// 一時的な worktree を未コミットの変更ごと削除
+func (m *WorktreeManager) Discard(name string) error {
+	path := m.Path(name)
+	if !m.isWorktree(path) {
+		return nil
+	}
+	if _, err := m.git(m.repoRoot, "worktree", "remove", "--force", path); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", name, err)
+	}
+	return nil
+}
+
+// worktree の状態の指紋（HEAD・未追跡ファイル・未コミットの差分。変化の検知に使う）
+// 取得できない場合は空文字列
+func (m *WorktreeManager) Fingerprint(name string) string {
+	path := m.Path(name)
+
+	var b strings.Builder
+	for _, args := range [][]string{
+		{"rev-parse", "HEAD"},
+		{"status", "--porcelain"},
+		{"diff", "HEAD"},
+	} {
+		output, err := m.git(path, args...)
+		if err != nil {
+			return ""
+		}
+		b.WriteString(output)
+	}
+	return b.String()
+}
+
+// 現在のブランチ名を取得
+func (m *WorktreeManager) CurrentBranch(name string) (string, error) {
+	output, err := m.git(m.Path(name), "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// .worktrees の絶対パス（シンボリックリンクを解決して git の出力と比較する）
+func (m *WorktreeManager) worktreeRoot() string {
+	dir := filepath.Join(m.repoRoot, WorktreeDir)
+	if resolved, err := filepath.EvalSymlinks(m.repoRoot); err == nil {
+		dir = filepath.Join(resolved, WorktreeDir)
+	}
+	return dir
+}
+
+// 登録済みの worktree か
+func (m *WorktreeManager) isWorktree(path string) bool {
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return false
+	}
+	return true
+}
+
+// ブランチが存在するか
+func (m *WorktreeManager) branchExists(branch string) bool {
+	_, err := m.git(m.repoRoot, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
+// git コマンドを実行し、標準出力を返す
+func (m *WorktreeManager) git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
+
+// git worktree list --porcelain の出力を解析
+func parseWorktreeList(output string) []Worktree {
+	var worktrees []Worktree
+	var current *Worktree
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			if current != nil {
+				worktrees = append(worktrees, *current)
+			}
+			current = &Worktree{Path: strings.TrimPrefix(line, "worktree ")}
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "HEAD "):
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+		case strings.HasPrefix(line, "branch "):
+			current.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		case strings.HasPrefix(line, "prunable"):
+			current.Prunable = true
+		}
+	}
+	if current != nil {
+		worktrees = append(worktrees, *current)
+	}
+	return worktrees
+}
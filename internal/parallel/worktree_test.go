@@ -0,0 +1,267 @@
+package parallel
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// テスト用の git リポジトリを作成
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	return dir
+}
+
+func TestWorktreeManager_EnsureAndList(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+
+	if !m.IsRepository() {
+		t.Fatal("expected repository")
+	}
+
+	base, err := m.BaseCommit()
+	if err != nil {
+		t.Fatalf("BaseCommit failed: %v", err)
+	}
+
+	path, err := m.Ensure(WorktreeName(1), BranchPrefix+"sp1", base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	if path != filepath.Join(repo, WorktreeDir, "sp1") {
+		t.Errorf("unexpected worktree path: %s", path)
+	}
+
+	// 2 回目は既存の worktree を使う
+	if _, err := m.Ensure(WorktreeName(1), BranchPrefix+"sp1", base); err != nil {
+		t.Fatalf("Ensure should reuse existing worktree: %v", err)
+	}
+
+	worktrees, err := m.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	// メインの checkout は含まない
+	if len(worktrees) != 1 || worktrees[0].Name != "sp1" || worktrees[0].Branch != "bastion/sp1" {
+		t.Errorf("unexpected worktrees: %+v", worktrees)
+	}
+}
+
+func TestWorktreeManager_SwitchRefusesDirty(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+
+	base, _ := m.BaseCommit()
+	path, err := m.Ensure("sp1", BranchPrefix+"sp1", base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	if err := m.Switch("sp1", TaskBranch("task_001"), base); err != nil {
+		t.Fatalf("Switch failed: %v", err)
+	}
+	branch, err := m.CurrentBranch("sp1")
+	if err != nil {
+		t.Fatalf("CurrentBranch failed: %v", err)
+	}
+	if branch != "bastion/task/task_001" {
+		t.Errorf("expected bastion/task/task_001, got %s", branch)
+	}
+
+	// 未コミットの変更があれば切り替えない
+	if err := os.WriteFile(filepath.Join(path, "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	err = m.Switch("sp1", TaskBranch("task_002"), base)
+	if !errors.Is(err, ErrWorktreeDirty) {
+		t.Errorf("expected ErrWorktreeDirty, got %v", err)
+	}
+}
+
+func TestWorktreeManager_RemoveRefusesDirty(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+
+	base, _ := m.BaseCommit()
+	path, err := m.Ensure("sp1", BranchPrefix+"sp1", base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := m.Remove("sp1"); !errors.Is(err, ErrWorktreeDirty) {
+		t.Fatalf("expected ErrWorktreeDirty, got %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("dirty worktree should not be removed")
+	}
+
+	// 変更を片付ければ削除できる
+	if err := os.Remove(filepath.Join(path, "wip.txt")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if err := m.Remove("sp1"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("worktree directory should be removed")
+	}
+}
+
+func TestWorktreeManager_Prune(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+
+	base, _ := m.BaseCommit()
+	path, err := m.Ensure("sp1", BranchPrefix+"sp1", base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	// ディレクトリだけ削除された worktree
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatalf("failed to remove directory: %v", err)
+	}
+	worktrees, _ := m.List()
+	if len(worktrees) != 1 || !worktrees[0].Prunable {
+		t.Fatalf("expected prunable worktree, got %+v", worktrees)
+	}
+
+	if err := m.Prune(); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	worktrees, _ = m.List()
+	if len(worktrees) != 0 {
+		t.Errorf("expected no worktrees after prune, got %+v", worktrees)
+	}
+}
+
+func TestParseWorktreeList(t *testing.T) {
+	output := `worktree /repo
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /repo/.worktrees/sp1
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/bastion/task/task_001
+
+worktree /repo/.worktrees/sp2
+HEAD 3333333333333333333333333333333333333333
+detached
+prunable gitdir file points to non-existent location
+`
+
+	worktrees := parseWorktreeList(output)
+	if len(worktrees) != 3 {
+		t.Fatalf("expected 3 worktrees, got %d", len(worktrees))
+	}
+	if worktrees[1].Branch != "bastion/task/task_001" {
+		t.Errorf("unexpected branch: %s", worktrees[1].Branch)
+	}
+	if !worktrees[2].Prunable || worktrees[2].Branch != "" {
+		t.Errorf("unexpected detached worktree: %+v", worktrees[2])
+	}
+}
+
+func TestWorktreeManager_Fingerprint(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	path, err := m.Ensure("sp1", "bastion/sp1", base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+
+	before := m.Fingerprint("sp1")
+	if before == "" {
+		t.Fatal("expected fingerprint")
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "work.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	untracked := m.Fingerprint("sp1")
+	if untracked == before {
+		t.Error("fingerprint should change when a file is added")
+	}
+
+	commitFile(t, path, "work.txt", "v2", "work")
+	if m.Fingerprint("sp1") == untracked {
+		t.Error("fingerprint should change after commit")
+	}
+
+	if m.Fingerprint("missing") != "" {
+		t.Error("fingerprint of missing worktree should be empty")
+	}
+}
+
+func TestWorktreeManager_CheckoutAndDiscard(t *testing.T) {
+	repo := initTestRepo(t)
+	m := NewWorktreeManager(repo)
+	base, _ := m.BaseCommit()
+
+	sp1, err := m.Ensure("sp1", TaskBranch("task_001"), base)
+	if err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	commitFile(t, sp1, "app.go", "package app\n", "add app.go")
+
+	head, err := m.Commit(TaskBranch("task_001"))
+	if err != nil || head == base {
+		t.Fatalf("Commit should resolve the branch tip: %s (%v)", head, err)
+	}
+
+	// 分岐点を checkout した worktree には app.go がない
+	path, err := m.Checkout("snapshot", base)
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "app.go")); !os.IsNotExist(err) {
+		t.Errorf("app.go should not exist at base: %v", err)
+	}
+
+	// 作り直すとブランチの先頭になる
+	if path, err = m.Checkout("snapshot", TaskBranch("task_001")); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "coverage.out"), []byte("mode: set\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "app.go")); err != nil {
+		t.Errorf("app.go should exist at head: %v", err)
+	}
+
+	// 未追跡のファイルがあっても削除する
+	if err := m.Discard("snapshot"); err != nil {
+		t.Fatalf("Discard failed: %v", err)
+	}
+	if m.Exists("snapshot") {
+		t.Error("snapshot should be removed")
+	}
+	if _, err := m.Commit("task/missing"); err == nil {
+		t.Error("expected error for missing revision")
+	}
+}